@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"sync"
+
 	"github.com/bradenaw/juniper/xsort"
 )
 
@@ -18,11 +20,15 @@ import (
 //	gte_10240_lt_102400  which counts Observe()s since the last Emit() with 10240 <= v < 102400
 //	gte_102400           which counts Observe()s since the last Emit() with 102400 <= v
 //
-// BucketedGaugeGroups are usually emitted to using Metrics.EveryFlush.
+// BucketedGaugeGroups are usually emitted to using Metrics.EveryFlush or EmitEveryFlush. They're
+// safe to use concurrently, so Observe can be called from anywhere while they're being emitted.
 type BucketedGaugeGroup struct {
+	m          *Metrics
 	boundaries []float64
 	gauges     []*Gauge
-	pending    []float64
+
+	mu      sync.Mutex
+	pending []float64
 }
 
 // NewBucketedGaugeGroup returns a group of gauges that will emit the number of observations in each
@@ -45,6 +51,7 @@ func NewBucketedGaugeGroup(
 		gauges[i] = m.Gauge(d.Values(name))
 	}
 	return &BucketedGaugeGroup{
+		m:          m,
 		boundaries: boundaries,
 		gauges:     gauges,
 		pending:    make([]float64, len(gauges)),
@@ -57,13 +64,24 @@ func (gg *BucketedGaugeGroup) Observe(v float64) {
 	if idx < len(gg.boundaries) && v == gg.boundaries[idx] {
 		idx++
 	}
+	gg.mu.Lock()
+	defer gg.mu.Unlock()
 	gg.pending[idx]++
 }
 
 // Emit emits the observations passed to Observe() since the last Emit() as gauges.
 func (gg *BucketedGaugeGroup) Emit() {
+	gg.mu.Lock()
+	defer gg.mu.Unlock()
 	for i := range gg.pending {
 		gg.gauges[i].Set(gg.pending[i])
 		gg.pending[i] = 0
 	}
 }
+
+// EmitEveryFlush calls Emit before every flush using Metrics.EveryFlush, returning a function that
+// stops it. This is useful when Observe is called as things happen rather than all at once just
+// before emitting.
+func (gg *BucketedGaugeGroup) EmitEveryFlush() func() {
+	return gg.m.EveryFlush(gg.Emit)
+}
@@ -67,5 +67,5 @@ func (s *Server) Close() {
 }
 
 // --- main.go -------------------------------------------------------------------------------------
-func Example_BucketedGaugeGroup() {
+func ExampleBucketedGaugeGroup() {
 }
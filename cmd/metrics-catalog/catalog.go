@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/bradenaw/metrics"
+)
+
+// catalogPackage is the set of metrics defined in one package.
+type catalogPackage struct {
+	Path    string
+	Metrics []catalogMetric
+}
+
+type catalogMetric struct {
+	metrics.Metadata
+	// File, relative to the repository root if known.
+	RelFile string
+	// Empty if no source URL template was given.
+	SourceURL string
+}
+
+type catalogOptions struct {
+	// Stripped from the beginning of each file path to get the path relative to the repository.
+	trimPrefix string
+	// Joined with the directory of each file to make the package path, for example a module path.
+	packagePrefix string
+	// If non-empty, used to make a link to the definition of each metric. {file} is replaced with
+	// the file path after trimPrefix is removed and {line} is replaced with the line number.
+	sourceURLTemplate string
+}
+
+// makeCatalog groups defs by the package that defines them, sorted by package path and then by
+// name.
+func makeCatalog(defs map[string]metrics.Metadata, opts catalogOptions) []catalogPackage {
+	byPath := make(map[string][]catalogMetric)
+	for _, md := range defs {
+		relFile := strings.TrimPrefix(strings.TrimPrefix(md.File, opts.trimPrefix), "/")
+		m := catalogMetric{
+			Metadata: md,
+			RelFile:  relFile,
+		}
+		if opts.sourceURLTemplate != "" {
+			m.SourceURL = strings.NewReplacer(
+				"{file}", relFile,
+				"{line}", strconv.Itoa(md.Line),
+			).Replace(opts.sourceURLTemplate)
+		}
+		pkgPath := path.Join(opts.packagePrefix, path.Dir(relFile))
+		if pkgPath == "." {
+			pkgPath = "(root)"
+		}
+		byPath[pkgPath] = append(byPath[pkgPath], m)
+	}
+
+	catalog := make([]catalogPackage, 0, len(byPath))
+	for dir, ms := range byPath {
+		sort.Slice(ms, func(i, j int) bool { return ms[i].Name < ms[j].Name })
+		catalog = append(catalog, catalogPackage{Path: dir, Metrics: ms})
+	}
+	sort.Slice(catalog, func(i, j int) bool { return catalog[i].Path < catalog[j].Path })
+	return catalog
+}
+
+func writeMarkdown(w io.Writer, catalog []catalogPackage) error {
+	return markdownTmpl.Execute(w, catalog)
+}
+
+func writeHTML(w io.Writer, catalog []catalogPackage) error {
+	return htmlTmpl.Execute(w, catalog)
+}
+
+func tagList(md metrics.Metadata) []string {
+	tags := make([]string, len(md.Keys))
+	for i, key := range md.Keys {
+		if key == "" {
+			key = "(value only)"
+		}
+		if i < len(md.ValueTypeNames) {
+			tags[i] = fmt.Sprintf("%s (%s)", key, md.ValueTypeNames[i])
+		} else {
+			tags[i] = key
+		}
+	}
+	return tags
+}
+
+// Makes s safe to put inside of a markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\n", " ")
+	return s
+}
+
+// Makes a heading anchor the same way GitHub does for simple paths.
+func markdownAnchor(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			sb.WriteRune(r)
+		case r == ' ':
+			sb.WriteRune('-')
+		}
+	}
+	return sb.String()
+}
+
+var markdownTmpl = texttemplate.Must(texttemplate.New("markdown").Funcs(texttemplate.FuncMap{
+	"cell":    markdownCell,
+	"anchor":  markdownAnchor,
+	"tagList": tagList,
+	"join":    strings.Join,
+}).Parse(`# Metrics
+{{range .}}
+- [{{.Path}}](#{{anchor .Path}}) ({{len .Metrics}})
+{{- end}}
+{{range .}}
+## {{.Path}}
+
+| Name | Type | Unit | Tags | Description |
+| --- | --- | --- | --- | --- |
+{{- range .Metrics}}
+| {{if .SourceURL}}[` + "`{{.Name}}`" + `]({{.SourceURL}}){{else}}` + "`{{.Name}}`" + `{{end}} | {{.MetricType}} | {{cell (print .Unit)}} | {{cell (join (tagList .Metadata) ", ")}} | {{cell .Description}} |
+{{- end}}
+{{end -}}
+`))
+
+var htmlTmpl = template.Must(template.New("html").Funcs(template.FuncMap{
+	"tagList": tagList,
+	"join":    strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Metrics</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+code { font-size: 1.1em; }
+#search { font-size: 1.2em; width: 40em; max-width: 100%; margin-bottom: 1em; }
+</style>
+</head>
+<body>
+<h1>Metrics</h1>
+<input id="search" type="search" placeholder="Filter by name, tag, unit, or description" autofocus>
+{{range .}}
+<section class="package">
+<h2>{{.Path}}</h2>
+<table>
+<thead><tr><th>Name</th><th>Type</th><th>Unit</th><th>Tags</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .Metrics}}
+<tr class="metric">
+<td><code>{{if .SourceURL}}<a href="{{.SourceURL}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</code></td>
+<td>{{.MetricType}}</td>
+<td>{{.Unit}}</td>
+<td>{{join (tagList .Metadata) ", "}}</td>
+<td>{{.Description}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+</section>
+{{end}}
+<script>
+document.getElementById("search").addEventListener("input", function(e) {
+	const q = e.target.value.toLowerCase();
+	for (const section of document.querySelectorAll("section.package")) {
+		let any = false;
+		for (const row of section.querySelectorAll("tr.metric")) {
+			const match = row.textContent.toLowerCase().includes(q);
+			row.style.display = match ? "" : "none";
+			any = any || match;
+		}
+		section.style.display = any ? "" : "none";
+	}
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bradenaw/metrics"
+)
+
+func TestCatalogMarkdown(t *testing.T) {
+	defs := map[string]metrics.Metadata{
+		"rpc_responses": {
+			MetricType:     metrics.CounterType,
+			Name:           "rpc_responses",
+			Description:    "Counts responses | by method and status.",
+			Unit:           metrics.UnitResponse,
+			Keys:           []string{"method", "status"},
+			ValueTypeNames: []string{"string", "string"},
+			File:           "/src/repo/server/metrics.go",
+			Line:           12,
+		},
+		"queue_depth": {
+			MetricType:  metrics.GaugeType,
+			Name:        "queue_depth",
+			Description: "Items waiting.",
+			Unit:        metrics.UnitItem,
+			Keys:        []string{},
+			File:        "/src/repo/server/queue/metrics.go",
+			Line:        5,
+		},
+		"runs": {
+			MetricType: metrics.CounterType,
+			Name:       "runs",
+			Keys:       []string{},
+			File:       "/src/repo/server/metrics.go",
+			Line:       8,
+		},
+	}
+
+	catalog := makeCatalog(defs, catalogOptions{
+		trimPrefix:        "/src/repo",
+		packagePrefix:     "example.com/repo",
+		sourceURLTemplate: "https://example.com/repo/blob/main/{file}#L{line}",
+	})
+	var buf bytes.Buffer
+	err := writeMarkdown(&buf, catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.TrimLeft(`
+# Metrics
+
+- [example.com/repo/server](#examplecomreposerver) (2)
+- [example.com/repo/server/queue](#examplecomreposerverqueue) (1)
+
+## example.com/repo/server
+
+| Name | Type | Unit | Tags | Description |
+| --- | --- | --- | --- | --- |
+| [`+"`rpc_responses`"+`](https://example.com/repo/blob/main/server/metrics.go#L12) | counter | response | method (string), status (string) | Counts responses \| by method and status. |
+| [`+"`runs`"+`](https://example.com/repo/blob/main/server/metrics.go#L8) | counter |  |  |  |
+
+## example.com/repo/server/queue
+
+| Name | Type | Unit | Tags | Description |
+| --- | --- | --- | --- | --- |
+| [`+"`queue_depth`"+`](https://example.com/repo/blob/main/server/queue/metrics.go#L5) | gauge | item |  | Items waiting. |
+`, "\n")
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\n\nactual:\n%s", expected, buf.String())
+	}
+}
+
+func TestCatalogHTML(t *testing.T) {
+	defs := map[string]metrics.Metadata{
+		"runs": {
+			MetricType:  metrics.CounterType,
+			Name:        "runs",
+			Description: "<script>alert(1)</script>",
+			Keys:        []string{},
+			File:        "/src/repo/metrics.go",
+			Line:        8,
+		},
+	}
+	var buf bytes.Buffer
+	err := writeHTML(&buf, makeCatalog(defs, catalogOptions{trimPrefix: "/src/repo"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<h2>(root)</h2>") {
+		t.Fatalf("expected a (root) section:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "<script>alert(1)</script>") {
+		t.Fatalf("description was not escaped:\n%s", buf.String())
+	}
+}
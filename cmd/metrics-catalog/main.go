@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bradenaw/metrics"
+)
+
+func main() {
+	err := main2()
+	if err != nil {
+		panic(err)
+	}
+}
+
+var usage = `
+metrics-catalog produces a catalog of metric definitions, so that it's easy to find out which metrics
+a service emits and what they mean. Metrics are grouped by the package that defines them.
+
+This program parses metric metadata from stdin in the same format that it's emitted from
+metrics.DumpDefs() or metrics-extract-defs. For example:
+
+	metrics-extract-defs ./... | metrics-catalog \
+		--format html \
+		--trim-prefix "$(pwd)" \
+		--package-prefix github.com/my-org/my-repo \
+		--source-url 'https://github.com/my-org/my-repo/blob/main/{file}#L{line}' \
+		> metrics.html
+`
+
+func main2() error {
+	format := flag.String(
+		"format",
+		"markdown", // default
+		"The output format, either markdown or html.",
+	)
+	trimPrefix := flag.String(
+		"trim-prefix",
+		"", // default
+		"If supplied, removed from the beginning of each definition's file path, for example the "+
+			"path of the repository root on the machine that produced the definitions.",
+	)
+	packagePrefix := flag.String(
+		"package-prefix",
+		"", // default
+		"If supplied, placed before the directory of each definition's file (after --trim-prefix) "+
+			"to make its package path, for example the module path of the repository.",
+	)
+	sourceURL := flag.String(
+		"source-url",
+		"", // default
+		"If supplied, a template for links to each metric's definition. {file} is replaced with the "+
+			"file path after --trim-prefix and {line} is replaced with the line number.",
+	)
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+		fmt.Fprint(out, usage)
+		fmt.Fprint(out, "\n\n")
+		fmt.Fprint(out, "Flags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	b, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	var defs map[string]metrics.Metadata
+	err = json.Unmarshal(b, &defs)
+	if err != nil {
+		return err
+	}
+
+	catalog := makeCatalog(defs, catalogOptions{
+		trimPrefix:        *trimPrefix,
+		packagePrefix:     *packagePrefix,
+		sourceURLTemplate: *sourceURL,
+	})
+
+	switch *format {
+	case "markdown":
+		return writeMarkdown(os.Stdout, catalog)
+	case "html":
+		return writeHTML(os.Stdout, catalog)
+	default:
+		return fmt.Errorf("unknown --format %q, expected markdown or html", *format)
+	}
+}
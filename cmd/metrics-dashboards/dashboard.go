@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bradenaw/metrics"
+)
+
+// The below mirror the parts of the DataDog dashboard and monitor APIs that are generated, and are
+// checked against the schemas in schema/.
+
+type dashboard struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	LayoutType  string        `json:"layout_type"`
+	Widgets     []groupWidget `json:"widgets"`
+}
+
+type groupWidget struct {
+	Definition groupDefinition `json:"definition"`
+}
+
+type groupDefinition struct {
+	Type       string             `json:"type"`
+	Title      string             `json:"title"`
+	LayoutType string             `json:"layout_type"`
+	Widgets    []timeseriesWidget `json:"widgets"`
+}
+
+type timeseriesWidget struct {
+	Definition timeseriesDefinition `json:"definition"`
+}
+
+type timeseriesDefinition struct {
+	Type       string              `json:"type"`
+	Title      string              `json:"title"`
+	ShowLegend bool                `json:"show_legend"`
+	Requests   []timeseriesRequest `json:"requests"`
+}
+
+type timeseriesRequest struct {
+	ResponseFormat string         `json:"response_format"`
+	DisplayType    string         `json:"display_type"`
+	Queries        []metricsQuery `json:"queries"`
+	Formulas       []formula      `json:"formulas"`
+}
+
+type metricsQuery struct {
+	DataSource string `json:"data_source"`
+	Name       string `json:"name"`
+	Query      string `json:"query"`
+}
+
+type formula struct {
+	Formula string `json:"formula"`
+	Alias   string `json:"alias,omitempty"`
+}
+
+type monitor struct {
+	Name     string         `json:"name"`
+	Type     string         `json:"type"`
+	Query    string         `json:"query"`
+	Message  string         `json:"message"`
+	Tags     []string       `json:"tags,omitempty"`
+	Priority int            `json:"priority,omitempty"`
+	Options  monitorOptions `json:"options"`
+}
+
+type monitorOptions struct {
+	Thresholds        monitorThresholds `json:"thresholds"`
+	NotifyNoData      bool              `json:"notify_no_data"`
+	RequireFullWindow bool              `json:"require_full_window"`
+}
+
+type monitorThresholds struct {
+	Critical float64 `json:"critical"`
+}
+
+// annotation adjusts how a single metric appears on the dashboard. Annotations are read from a JSON
+// object keyed by metric name, without --metric-prefix.
+type annotation struct {
+	// Replaces the widget title, which is otherwise the metric's short name or name.
+	Title string `json:"title"`
+	// If set, the metric doesn't get a widget.
+	Hidden bool `json:"hidden"`
+	// Replaces the tag keys that the metric is grouped by, which is otherwise all of them. An empty
+	// list shows the metric as a single series.
+	GroupBy *[]string `json:"groupBy"`
+	// Replaces the percentiles shown for distributions, for example ["p50", "p99.9"].
+	Percentiles []string `json:"percentiles"`
+}
+
+var defaultPercentiles = []string{"p50", "p90", "p99"}
+
+type dashboardOptions struct {
+	title string
+	// If non-empty, placed plus a dot before each metric name.
+	metricPrefix string
+	// Stripped from the beginning of each file path to get the path relative to the repository.
+	trimPrefix string
+	// Joined with the directory of each file to make the package path, for example a module path.
+	packagePrefix string
+	annotations   map[string]annotation
+}
+
+// makeDashboard returns a dashboard with a group of widgets for each package that defines metrics,
+// sorted by package path and then by metric name.
+func makeDashboard(defs map[string]metrics.Metadata, opts dashboardOptions) dashboard {
+	byPath := make(map[string][]metrics.Metadata)
+	for _, md := range defs {
+		if opts.annotations[md.Name].Hidden {
+			continue
+		}
+		relFile := strings.TrimPrefix(strings.TrimPrefix(md.File, opts.trimPrefix), "/")
+		pkgPath := path.Join(opts.packagePrefix, path.Dir(relFile))
+		if pkgPath == "." {
+			pkgPath = "(root)"
+		}
+		byPath[pkgPath] = append(byPath[pkgPath], md)
+	}
+	pkgPaths := make([]string, 0, len(byPath))
+	for pkgPath := range byPath {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	sort.Strings(pkgPaths)
+
+	d := dashboard{
+		Title:       opts.title,
+		Description: "Generated by metrics-dashboards from metric definitions.",
+		LayoutType:  "ordered",
+		Widgets:     make([]groupWidget, 0, len(pkgPaths)),
+	}
+	for _, pkgPath := range pkgPaths {
+		mds := byPath[pkgPath]
+		sort.Slice(mds, func(i, j int) bool { return mds[i].Name < mds[j].Name })
+		group := groupDefinition{
+			Type:       "group",
+			Title:      pkgPath,
+			LayoutType: "ordered",
+			Widgets:    make([]timeseriesWidget, 0, len(mds)),
+		}
+		for _, md := range mds {
+			group.Widgets = append(
+				group.Widgets,
+				metricWidget(md, opts.metricPrefix, opts.annotations[md.Name]),
+			)
+		}
+		d.Widgets = append(d.Widgets, groupWidget{Definition: group})
+	}
+	return d
+}
+
+// metricWidget returns a timeseries widget for md: percentiles for distributions, the per-second
+// rate for counters, and the value for gauges and sets, grouped by each of the tag keys.
+func metricWidget(md metrics.Metadata, metricPrefix string, a annotation) timeseriesWidget {
+	name := prefixed(metricPrefix, md.Name)
+	by := groupBy(md, a)
+
+	title := md.Name
+	if a.Title != "" {
+		title = a.Title
+	} else if md.ShortName != "" {
+		title = md.ShortName
+	}
+
+	request := timeseriesRequest{
+		ResponseFormat: "timeseries",
+		DisplayType:    "line",
+	}
+	switch md.MetricType {
+	case metrics.DistributionType:
+		percentiles := a.Percentiles
+		if len(percentiles) == 0 {
+			percentiles = defaultPercentiles
+		}
+		for i, p := range percentiles {
+			queryName := fmt.Sprintf("query%d", i+1)
+			request.Queries = append(request.Queries, metricsQuery{
+				DataSource: "metrics",
+				Name:       queryName,
+				Query:      fmt.Sprintf("%s:%s{*}%s", p, name, by),
+			})
+			request.Formulas = append(request.Formulas, formula{Formula: queryName, Alias: p})
+		}
+	case metrics.CounterType:
+		request.DisplayType = "bars"
+		request.Queries = []metricsQuery{{
+			DataSource: "metrics",
+			Name:       "query1",
+			Query:      fmt.Sprintf("sum:%s{*}%s.as_rate()", name, by),
+		}}
+		request.Formulas = []formula{{Formula: "query1", Alias: "per second"}}
+	case metrics.GaugeType, metrics.SetType:
+		request.Queries = []metricsQuery{{
+			DataSource: "metrics",
+			Name:       "query1",
+			Query:      fmt.Sprintf("%s:%s{*}%s", gaugeSpaceAggregation(md), name, by),
+		}}
+		request.Formulas = []formula{{Formula: "query1"}}
+	}
+
+	return timeseriesWidget{Definition: timeseriesDefinition{
+		Type:       "timeseries",
+		Title:      title,
+		ShowLegend: by != "",
+		Requests:   []timeseriesRequest{request},
+	}}
+}
+
+// groupBy returns the " by {...}" clause for md's widget, or the empty string if it isn't grouped.
+func groupBy(md metrics.Metadata, a annotation) string {
+	var keys []string
+	if a.GroupBy != nil {
+		keys = *a.GroupBy
+	} else {
+		for _, key := range md.Keys {
+			// Tags with empty keys are only a value, and so can't be grouped by.
+			if key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	return " by {" + strings.Join(keys, ",") + "}"
+}
+
+// gaugeSpaceAggregation returns how to combine the values of a gauge across hosts, matching how the
+// gauge is aggregated over time where that makes sense.
+func gaugeSpaceAggregation(md metrics.Metadata) string {
+	if md.MetricType == metrics.SetType {
+		return "sum"
+	}
+	switch md.GaugeAggregation {
+	case metrics.GaugeAggregationMax:
+		return "max"
+	case metrics.GaugeAggregationMin:
+		return "min"
+	case metrics.GaugeAggregationSum:
+		return "sum"
+	default:
+		return "avg"
+	}
+}
+
+// makeMonitors returns monitor templates for the metrics that the metrics package itself emits.
+func makeMonitors(metricPrefix string, tags []string) []monitor {
+	name := prefixed(metricPrefix, "metrics.bad_metric_definitions")
+	return []monitor{{
+		Name:  "Bad metric definitions ({{reason.name}}) on {{host.name}}",
+		Type:  "query alert",
+		Query: fmt.Sprintf("max(last_5m):max:%s{*} by {host,reason} > 0", name),
+		Message: "{{host.name}} has metric definitions that are invalid for reason " +
+			"{{reason.name}}, and so they don't produce any data. See the package documentation of " +
+			"github.com/bradenaw/metrics for what each reason means, and run metrics-vet to catch " +
+			"these before deploying.",
+		Tags:     tags,
+		Priority: 4,
+		Options: monitorOptions{
+			Thresholds: monitorThresholds{Critical: 0},
+			// The gauge is only emitted by programs that use the package.
+			NotifyNoData: false,
+		},
+	}}
+}
+
+func prefixed(metricPrefix string, name string) string {
+	if metricPrefix == "" {
+		return name
+	}
+	return metricPrefix + "." + name
+}
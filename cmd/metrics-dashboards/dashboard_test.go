@@ -0,0 +1,144 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bradenaw/metrics"
+)
+
+var testDefs = map[string]metrics.Metadata{
+	"rpc_latency": {
+		MetricType: metrics.DistributionType,
+		Name:       "rpc_latency",
+		Unit:       metrics.UnitSecond,
+		Keys:       []string{"method"},
+		File:       "/src/repo/rpc/metrics.go",
+	},
+	"rpc_responses": {
+		MetricType: metrics.CounterType,
+		Name:       "rpc_responses",
+		Unit:       metrics.UnitResponse,
+		Keys:       []string{"method", "code"},
+		File:       "/src/repo/rpc/metrics.go",
+	},
+	"queue_depth": {
+		MetricType:       metrics.GaugeType,
+		Name:             "queue_depth",
+		Unit:             metrics.UnitItem,
+		Keys:             []string{},
+		File:             "/src/repo/queue/metrics.go",
+		GaugeAggregation: metrics.GaugeAggregationMax,
+		ShortName:        "Queue depth",
+	},
+}
+
+func queries(w timeseriesWidget) []string {
+	var qs []string
+	for _, r := range w.Definition.Requests {
+		for _, q := range r.Queries {
+			qs = append(qs, q.Query)
+		}
+	}
+	return qs
+}
+
+func TestMakeDashboard(t *testing.T) {
+	d := makeDashboard(testDefs, dashboardOptions{
+		title:         "Test",
+		metricPrefix:  "svc",
+		trimPrefix:    "/src/repo",
+		packagePrefix: "example.com/repo",
+	})
+	err := validate(dashboardSchema, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(d.Widgets) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(d.Widgets))
+	}
+	queue := d.Widgets[0].Definition
+	rpc := d.Widgets[1].Definition
+	if queue.Title != "example.com/repo/queue" || rpc.Title != "example.com/repo/rpc" {
+		t.Fatalf("unexpected group titles %q, %q", queue.Title, rpc.Title)
+	}
+
+	if queue.Widgets[0].Definition.Title != "Queue depth" {
+		t.Fatalf("expected short name as title, got %q", queue.Widgets[0].Definition.Title)
+	}
+	expectQueries := func(w timeseriesWidget, expected []string) {
+		t.Helper()
+		actual := queries(w)
+		if !slices.Equal(actual, expected) {
+			t.Fatalf("expected queries %q, got %q", expected, actual)
+		}
+	}
+	expectQueries(queue.Widgets[0], []string{"max:svc.queue_depth{*}"})
+	expectQueries(rpc.Widgets[0], []string{
+		"p50:svc.rpc_latency{*} by {method}",
+		"p90:svc.rpc_latency{*} by {method}",
+		"p99:svc.rpc_latency{*} by {method}",
+	})
+	expectQueries(rpc.Widgets[1], []string{"sum:svc.rpc_responses{*} by {method,code}.as_rate()"})
+}
+
+func TestMakeDashboardAnnotations(t *testing.T) {
+	noGroups := []string{}
+	d := makeDashboard(testDefs, dashboardOptions{
+		title: "Test",
+		annotations: map[string]annotation{
+			"queue_depth":   {Hidden: true},
+			"rpc_latency":   {Percentiles: []string{"p99.9"}, GroupBy: &noGroups},
+			"rpc_responses": {Title: "Responses"},
+		},
+	})
+	err := validate(dashboardSchema, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(d.Widgets) != 1 {
+		t.Fatalf("expected only the rpc group, got %d groups", len(d.Widgets))
+	}
+	widgets := d.Widgets[0].Definition.Widgets
+	if actual := queries(widgets[0]); !slices.Equal(actual, []string{"p99.9:rpc_latency{*}"}) {
+		t.Fatalf("unexpected queries %q", actual)
+	}
+	if widgets[1].Definition.Title != "Responses" {
+		t.Fatalf("expected annotated title, got %q", widgets[1].Definition.Title)
+	}
+}
+
+func TestValidateRejectsInvalid(t *testing.T) {
+	d := makeDashboard(testDefs, dashboardOptions{title: "Test"})
+	d.Widgets[0].Definition.Widgets[0].Definition.Requests[0].DisplayType = "pie"
+	err := validate(dashboardSchema, d)
+	if err == nil {
+		t.Fatal("expected invalid display_type to fail validation")
+	}
+
+	d = makeDashboard(testDefs, dashboardOptions{title: ""})
+	err = validate(dashboardSchema, d)
+	if err == nil {
+		t.Fatal("expected empty title to fail validation")
+	}
+}
+
+func TestMakeMonitors(t *testing.T) {
+	monitors := makeMonitors("svc", []string{"team:foo"})
+	err := validate(monitorsSchema, monitors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "max(last_5m):max:svc.metrics.bad_metric_definitions{*} by {host,reason} > 0"
+	if monitors[0].Query != expected {
+		t.Fatalf("expected query %q, got %q", expected, monitors[0].Query)
+	}
+
+	monitors[0].Query = "svc.metrics.bad_metric_definitions > 0"
+	err = validate(monitorsSchema, monitors)
+	if err == nil {
+		t.Fatal("expected malformed query to fail validation")
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bradenaw/metrics"
+)
+
+func main() {
+	err := main2()
+	if err != nil {
+		panic(err)
+	}
+}
+
+var usage = `
+metrics-dashboards generates DataDog dashboard and monitor JSON from metric definitions, so that
+every metric has a graph without building each one by hand.
+
+The dashboard has a group for each package that defines metrics, and a timeseries widget for each
+metric in it: percentiles for distributions, the per-second rate for counters, and the value for
+gauges and sets, each grouped by all of the metric's tag keys. The monitors alert when
+metrics.bad_metric_definitions is above zero. Both are checked against a JSON schema before being
+written, without talking to DataDog.
+
+This program parses metric metadata from stdin in the same format that it's emitted from
+metrics.DumpDefs() or metrics-extract-defs. For example:
+
+	metrics-extract-defs ./... | metrics-dashboards \
+		--title "My Service" \
+		--trim-prefix "$(pwd)" \
+		--package-prefix github.com/my-org/my-repo \
+		> dashboard.json
+
+	metrics-dashboards --kind monitors --monitor-tags team:foo > monitors.json
+
+The output is the body for the DataDog create dashboard or create monitor APIs (one per element of
+the list for monitors).
+
+Annotations can optionally adjust individual widgets, for example:
+
+	{
+		"rpc_latency": {"percentiles": ["p50", "p99.9"], "groupBy": ["method"]},
+		"rpc_responses": {"title": "Responses"},
+		"internal_thing": {"hidden": true}
+	}
+`
+
+func main2() error {
+	kind := flag.String(
+		"kind",
+		"dashboard", // default
+		"What to generate, either dashboard or monitors.",
+	)
+	title := flag.String(
+		"title",
+		"Metrics", // default
+		"The title of the dashboard.",
+	)
+	annotationsPath := flag.String(
+		"annotations",
+		"", // default
+		"If supplied, the path to a JSON file of annotations keyed by metric name.",
+	)
+	metricPrefix := flag.String(
+		"metric-prefix",
+		"", // default
+		"If supplied, places this plus a dot before each metric name.",
+	)
+	monitorTags := flag.String(
+		"monitor-tags",
+		"", // default
+		"A comma-separated list of tags to add to each monitor, for example team:foo.",
+	)
+	trimPrefix := flag.String(
+		"trim-prefix",
+		"", // default
+		"If supplied, removed from the beginning of each definition's file path, for example the "+
+			"path of the repository root on the machine that produced the definitions.",
+	)
+	packagePrefix := flag.String(
+		"package-prefix",
+		"", // default
+		"If supplied, placed before the directory of each definition's file (after --trim-prefix) "+
+			"to make its package path, for example the module path of the repository.",
+	)
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+		fmt.Fprint(out, usage)
+		fmt.Fprint(out, "\n\n")
+		fmt.Fprint(out, "Flags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var v any
+	switch *kind {
+	case "dashboard":
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		var defs map[string]metrics.Metadata
+		err = json.Unmarshal(b, &defs)
+		if err != nil {
+			return err
+		}
+
+		var annotations map[string]annotation
+		if *annotationsPath != "" {
+			b, err := os.ReadFile(*annotationsPath)
+			if err != nil {
+				return err
+			}
+			err = json.Unmarshal(b, &annotations)
+			if err != nil {
+				return fmt.Errorf("parsing %s: %w", *annotationsPath, err)
+			}
+		}
+
+		d := makeDashboard(defs, dashboardOptions{
+			title:         *title,
+			metricPrefix:  *metricPrefix,
+			trimPrefix:    *trimPrefix,
+			packagePrefix: *packagePrefix,
+			annotations:   annotations,
+		})
+		err = validate(dashboardSchema, d)
+		if err != nil {
+			return fmt.Errorf("generated dashboard is invalid: %w", err)
+		}
+		v = d
+	case "monitors":
+		var tags []string
+		if *monitorTags != "" {
+			tags = strings.Split(*monitorTags, ",")
+		}
+		monitors := makeMonitors(*metricPrefix, tags)
+		err := validate(monitorsSchema, monitors)
+		if err != nil {
+			return fmt.Errorf("generated monitors are invalid: %w", err)
+		}
+		v = monitors
+	default:
+		return fmt.Errorf("unknown --kind %q, expected dashboard or monitors", *kind)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	// Queries contain > and <, which would otherwise be escaped.
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
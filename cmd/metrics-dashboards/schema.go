@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var (
+	//go:embed schema/dashboard.json
+	dashboardSchemaJSON string
+	//go:embed schema/monitors.json
+	monitorsSchemaJSON string
+
+	dashboardSchema = jsonschema.MustCompileString("dashboard.json", dashboardSchemaJSON)
+	monitorsSchema  = jsonschema.MustCompileString("monitors.json", monitorsSchemaJSON)
+)
+
+// validate checks that the JSON encoding of v matches schema, so that mistakes in generated output
+// are found without needing to talk to DataDog.
+func validate(schema *jsonschema.Schema, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	// jsonschema expects numbers as json.Number.
+	d.UseNumber()
+	var doc any
+	err = d.Decode(&doc)
+	if err != nil {
+		return err
+	}
+	return schema.Validate(doc)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bradenaw/metrics"
+)
+
+// change is one difference between two sets of metric definitions.
+type change struct {
+	name string
+	// Whether this change can break existing dashboards and monitors that use the metric.
+	breaking bool
+	desc     string
+}
+
+func (c change) String() string {
+	prefix := " "
+	if c.breaking {
+		prefix = "!"
+	}
+	return fmt.Sprintf("%s %s: %s", prefix, c.name, c.desc)
+}
+
+// diffDefs returns the differences from before to after, sorted by metric name.
+func diffDefs(before map[string]metrics.Metadata, after map[string]metrics.Metadata) []change {
+	// The metrics in after that are also published under a previous name.
+	renamedTo := make(map[string]string)
+	for name, a := range after {
+		if a.PreviousName != "" {
+			renamedTo[a.PreviousName] = name
+		}
+	}
+
+	var changes []change
+	for name, b := range before {
+		a, ok := after[name]
+		if !ok {
+			newName, ok := renamedTo[name]
+			if ok {
+				// Still published under this name, so nothing that uses it breaks unless the
+				// definition itself changed.
+				changes = append(changes, change{
+					name: name,
+					desc: fmt.Sprintf("renamed to %s", newName),
+				})
+				changes = append(changes, diffDef(name, b, after[newName])...)
+				continue
+			}
+			changes = append(changes, change{
+				name:     name,
+				breaking: b.Stability != metrics.StabilityExperimental,
+				desc:     fmt.Sprintf("removed %s", b.MetricType),
+			})
+			continue
+		}
+		changes = append(changes, diffDef(name, b, a)...)
+	}
+	for name, a := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, change{
+				name: name,
+				desc: fmt.Sprintf("added %s", a.MetricType),
+			})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].name < changes[j].name })
+	return changes
+}
+
+// diffDef returns the differences from b to a, which are published as name.
+func diffDef(name string, b metrics.Metadata, a metrics.Metadata) []change {
+	var changes []change
+	add := func(breaking bool, format string, args ...any) {
+		changes = append(changes, change{
+			name: name,
+			// Experimental metrics make no promises about staying the same.
+			breaking: breaking && b.Stability != metrics.StabilityExperimental,
+			desc:     fmt.Sprintf(format, args...),
+		})
+	}
+
+	if b.MetricType != a.MetricType {
+		add(true, "type changed from %s to %s", b.MetricType, a.MetricType)
+	}
+	if b.Unit != a.Unit {
+		add(true, "unit changed from %q to %q", b.Unit, a.Unit)
+	}
+
+	// Tags are unordered once published, so only keys going missing breaks anything. The empty key
+	// may appear more than once, so values with the same key are matched up in order.
+	beforeTypes := keyTypes(b)
+	afterTypes := keyTypes(a)
+	seen := make(map[string]int, len(b.Keys))
+	for _, key := range b.Keys {
+		i := seen[key]
+		seen[key]++
+		if i >= len(afterTypes[key]) {
+			add(true, "tag key %q removed", key)
+			continue
+		}
+		beforeType := beforeTypes[key][i]
+		afterType := afterTypes[key][i]
+		if beforeType != "" && afterType != "" && beforeType != afterType {
+			add(true, "tag %q value type changed from %s to %s", key, beforeType, afterType)
+		}
+	}
+	clear(seen)
+	for _, key := range a.Keys {
+		i := seen[key]
+		seen[key]++
+		if i >= len(beforeTypes[key]) {
+			add(false, "tag key %q added", key)
+		}
+	}
+
+	if b.Description != a.Description {
+		add(false, "description changed")
+	}
+	if b.Stability != a.Stability {
+		add(false, "stability changed from %q to %q", b.Stability, a.Stability)
+	}
+	if b.PreviousName != "" && b.PreviousName != a.PreviousName {
+		add(true, "no longer published as %s", b.PreviousName)
+	}
+	if b.Deprecation == nil && a.Deprecation != nil {
+		add(false, "deprecated")
+	}
+	return changes
+}
+
+// Returns the value types of the tags with each key in order, or empty string if not known.
+func keyTypes(md metrics.Metadata) map[string][]string {
+	m := make(map[string][]string, len(md.Keys))
+	for i, key := range md.Keys {
+		typ := ""
+		if i < len(md.ValueTypeNames) {
+			typ = md.ValueTypeNames[i]
+		}
+		m[key] = append(m[key], typ)
+	}
+	return m
+}
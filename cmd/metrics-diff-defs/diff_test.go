@@ -0,0 +1,144 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bradenaw/metrics"
+)
+
+func TestDiffDefs(t *testing.T) {
+	before := map[string]metrics.Metadata{
+		"unchanged": {
+			MetricType: metrics.CounterType,
+			Name:       "unchanged",
+			Unit:       metrics.UnitRequest,
+		},
+		"removed": {
+			MetricType: metrics.CounterType,
+			Name:       "removed",
+		},
+		"changed": {
+			MetricType:     metrics.CounterType,
+			Name:           "changed",
+			Description:    "before",
+			Unit:           metrics.UnitMillisecond,
+			Keys:           []string{"a", "b", "c"},
+			ValueTypeNames: []string{"string", "int", "bool"},
+		},
+		"reordered": {
+			MetricType:     metrics.GaugeType,
+			Name:           "reordered",
+			Keys:           []string{"a", "b"},
+			ValueTypeNames: []string{"string", "int"},
+		},
+		"experimental_removed": {
+			MetricType: metrics.CounterType,
+			Name:       "experimental_removed",
+			Stability:  metrics.StabilityExperimental,
+		},
+		"experimental_changed": {
+			MetricType: metrics.CounterType,
+			Name:       "experimental_changed",
+			Unit:       metrics.UnitRequest,
+			Stability:  metrics.StabilityExperimental,
+		},
+		"renamed_before": {
+			MetricType: metrics.CounterType,
+			Name:       "renamed_before",
+		},
+		"alias_dropped": {
+			MetricType:   metrics.CounterType,
+			Name:         "alias_dropped",
+			PreviousName: "alias_dropped_before",
+		},
+		"retyped_before": {
+			MetricType: metrics.CounterType,
+			Name:       "retyped_before",
+			Keys:       []string{"a", "b"},
+		},
+		"unkeyed": {
+			MetricType: metrics.GaugeType,
+			Name:       "unkeyed",
+			Keys:       []string{"a", "", ""},
+		},
+	}
+	after := map[string]metrics.Metadata{
+		"unchanged": before["unchanged"],
+		"added": {
+			MetricType: metrics.GaugeType,
+			Name:       "added",
+		},
+		"changed": {
+			MetricType:     metrics.DistributionType,
+			Name:           "changed",
+			Description:    "after",
+			Unit:           metrics.UnitSecond,
+			Keys:           []string{"a", "b", "d"},
+			ValueTypeNames: []string{"string", "string", "bool"},
+		},
+		"reordered": {
+			MetricType:     metrics.GaugeType,
+			Name:           "reordered",
+			Keys:           []string{"b", "a"},
+			ValueTypeNames: []string{"int", "string"},
+		},
+		"experimental_changed": {
+			MetricType:  metrics.CounterType,
+			Name:        "experimental_changed",
+			Unit:        metrics.UnitResponse,
+			Stability:   metrics.StabilityStable,
+			Deprecation: &metrics.Deprecation{Replacement: "unchanged"},
+		},
+		"renamed_after": {
+			MetricType:   metrics.CounterType,
+			Name:         "renamed_after",
+			PreviousName: "renamed_before",
+		},
+		"alias_dropped": {
+			MetricType: metrics.CounterType,
+			Name:       "alias_dropped",
+		},
+		"retyped_after": {
+			MetricType:   metrics.GaugeType,
+			Name:         "retyped_after",
+			Keys:         []string{"a"},
+			PreviousName: "retyped_before",
+		},
+		"unkeyed": {
+			MetricType: metrics.GaugeType,
+			Name:       "unkeyed",
+			Keys:       []string{"", "a"},
+		},
+	}
+
+	var actual []string
+	for _, c := range diffDefs(before, after) {
+		actual = append(actual, c.String())
+	}
+	expected := []string{
+		"  added: added gauge",
+		"! alias_dropped: no longer published as alias_dropped_before",
+		"! changed: type changed from counter to distribution",
+		`! changed: unit changed from "millisecond" to "second"`,
+		`! changed: tag "b" value type changed from int to string`,
+		`! changed: tag key "c" removed`,
+		`  changed: tag key "d" added`,
+		"  changed: description changed",
+		`  experimental_changed: unit changed from "request" to "response"`,
+		`  experimental_changed: stability changed from "experimental" to "stable"`,
+		"  experimental_changed: deprecated",
+		"  experimental_removed: removed counter",
+		"! removed: removed counter",
+		"  renamed_after: added counter",
+		"  renamed_before: renamed to renamed_after",
+		"  retyped_after: added gauge",
+		"  retyped_before: renamed to retyped_after",
+		"! retyped_before: type changed from counter to gauge",
+		`! retyped_before: tag key "b" removed`,
+		`! unkeyed: tag key "" removed`,
+	}
+	if !slices.Equal(expected, actual) {
+		t.Fatalf("expected:\n%q\n\nactual:\n%q", expected, actual)
+	}
+}
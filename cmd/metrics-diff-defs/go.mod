@@ -0,0 +1,12 @@
+module github.com/bradenaw/metrics/cmd/metrics-diff-defs
+
+go 1.22
+
+require github.com/bradenaw/metrics v0.0.0-20230830160715-f8023a8e07fd
+
+require (
+	github.com/bradenaw/juniper v0.13.0 // indirect
+	golang.org/x/exp v0.0.0-20220217172124-1812c5b45e43 // indirect
+)
+
+replace github.com/bradenaw/metrics => ../..
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bradenaw/metrics"
+)
+
+func main() {
+	ok, err := main2()
+	if err != nil {
+		panic(err)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+var usage = `
+metrics-diff-defs compares two sets of metric definitions in the format emitted by
+metrics.DumpDefs() (or metrics-extract-defs) and reports the differences. It exits non-zero if any of
+them can break existing dashboards and monitors: removed metrics, and changed types, units, tag keys,
+and tag value types. Changes to metrics defined with metrics.WithStability(metrics.StabilityExperimental)
+are never considered breaking, and neither is renaming a metric with metrics.WithPreviousName, since
+it's still published under the old name, though the renamed metric is compared with the old one.
+
+For example, in CI:
+
+	git checkout main && metrics-extract-defs ./... > before.json
+	git checkout my-branch && metrics-extract-defs ./... > after.json
+	metrics-diff-defs --allow metrics-allowed-changes.txt before.json after.json
+
+Breaking changes that are intentional can be allowed by listing the metric name in the file passed to
+--allow, one per line. Blank lines and anything following a # are ignored.
+`
+
+func main2() (bool, error) {
+	allowPath := flag.String(
+		"allow",
+		"", // default
+		"If supplied, a file listing metric names whose breaking changes are intentional.",
+	)
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage of %s: [flags] <before.json> <after.json>\n", os.Args[0])
+		fmt.Fprint(out, usage)
+		fmt.Fprint(out, "\n\n")
+		fmt.Fprint(out, "Flags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	before, err := readDefs(flag.Arg(0))
+	if err != nil {
+		return false, err
+	}
+	after, err := readDefs(flag.Arg(1))
+	if err != nil {
+		return false, err
+	}
+	allowed := make(map[string]bool)
+	if *allowPath != "" {
+		allowed, err = readAllowlist(*allowPath)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	ok := true
+	for _, c := range diffDefs(before, after) {
+		if c.breaking && allowed[c.name] {
+			fmt.Printf("%s (allowed)\n", c)
+			continue
+		}
+		fmt.Println(c)
+		if c.breaking {
+			ok = false
+		}
+	}
+	if !ok {
+		fmt.Fprintln(
+			os.Stderr,
+			"Found breaking changes to metric definitions, marked with !. If they're intentional, add "+
+				"the metric names to the file passed to --allow.",
+		)
+	}
+	return ok, nil
+}
+
+func readDefs(path string) (map[string]metrics.Metadata, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var defs map[string]metrics.Metadata
+	err = json.Unmarshal(b, &defs)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return defs, nil
+}
+
+func readAllowlist(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	allowed := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line != "" {
+			allowed[line] = true
+		}
+	}
+	return allowed, scanner.Err()
+}
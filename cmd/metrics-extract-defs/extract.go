@@ -0,0 +1,367 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"regexp"
+
+	"github.com/bradenaw/metrics"
+	"golang.org/x/tools/go/packages"
+)
+
+const metricsPkgPath = "github.com/bradenaw/metrics"
+
+var newDefRegexp = regexp.MustCompile(`^New(Counter|Gauge|Distribution|Set)Def([0-9]*)$`)
+
+var metricTypes = map[string]metrics.MetricType{
+	"Counter":      metrics.CounterType,
+	"Gauge":        metrics.GaugeType,
+	"Distribution": metrics.DistributionType,
+	"Set":          metrics.SetType,
+}
+
+// extractor finds metric definitions in the syntax of loaded packages.
+type extractor struct {
+	defs map[string]metrics.Metadata
+	// The positions of the definitions already extracted. With tests, the same file may be loaded
+	// as part of more than one package, e.g. both "foo" and "foo [foo.test]".
+	seen map[token.Position]bool
+	// The namespace of each package-level var initialized with metrics.NewNamespacedRegistry, for
+	// evaluating metrics.WithRegistry.
+	namespaces map[types.Object]string
+	// Problems that make the output not match what metrics.DumpDefs() would produce, for example
+	// definitions whose names aren't constant.
+	errs []error
+}
+
+func newExtractor() *extractor {
+	return &extractor{
+		defs:       make(map[string]metrics.Metadata),
+		seen:       make(map[token.Position]bool),
+		namespaces: make(map[types.Object]string),
+	}
+}
+
+func (e *extractor) errorf(fset *token.FileSet, pos token.Pos, format string, args ...any) {
+	e.errs = append(e.errs, fmt.Errorf("%s: %s", fset.Position(pos), fmt.Sprintf(format, args...)))
+}
+
+// Extracts the definitions from pkg. Only definitions made at init time are included, since any
+// others would be inert and not appear in metrics.DumpDefs() either.
+func (e *extractor) extractPackage(pkg *packages.Package) {
+	// Registries may be declared after the definitions that use them.
+	e.extractNamespaces(pkg)
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				if decl.Tok == token.VAR {
+					e.extractInitTime(pkg, decl)
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil && decl.Name.Name == "init" && decl.Body != nil {
+					e.extractInitTime(pkg, decl.Body)
+				}
+			}
+		}
+	}
+}
+
+func (e *extractor) extractNamespaces(pkg *packages.Package) {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.ValueSpec)
+				if len(spec.Names) != len(spec.Values) {
+					continue
+				}
+				for i, value := range spec.Values {
+					call, ok := ast.Unparen(value).(*ast.CallExpr)
+					if !ok {
+						continue
+					}
+					fn, _, ok := calledFunc(pkg.TypesInfo, call)
+					if !ok || fn.Name() != "NewNamespacedRegistry" {
+						continue
+					}
+					namespace, ok := e.constString(pkg, call.Args[0], "namespace")
+					if !ok {
+						continue
+					}
+					e.namespaces[pkg.TypesInfo.Defs[spec.Names[i]]] = namespace
+				}
+			}
+		}
+	}
+}
+
+func (e *extractor) extractInitTime(pkg *packages.Package, node ast.Node) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// Function literals may run at any time.
+			return false
+		case *ast.CallExpr:
+			e.extractCall(pkg, n)
+		}
+		return true
+	})
+}
+
+func (e *extractor) extractCall(pkg *packages.Package, call *ast.CallExpr) {
+	fn, inst, ok := calledFunc(pkg.TypesInfo, call)
+	if !ok {
+		return
+	}
+	match := newDefRegexp.FindStringSubmatch(fn.Name())
+	if match == nil {
+		return
+	}
+	metricType := metricTypes[match[1]]
+	tagged := match[2] != ""
+
+	// name, description, unit, [keys], [sampleRate], opts...
+	nFixed := 3
+	if tagged {
+		nFixed++
+	}
+	if metricType == metrics.DistributionType || metricType == metrics.SetType {
+		nFixed++
+	}
+	if len(call.Args) < nFixed {
+		e.errorf(pkg.Fset, call.Pos(), "%s called with too few arguments", fn.Name())
+		return
+	}
+
+	position := pkg.Fset.Position(call.Lparen)
+	if e.seen[position] {
+		return
+	}
+	e.seen[position] = true
+	md := metrics.Metadata{
+		MetricType: metricType,
+		// Untagged metrics have empty rather than nil keys in DumpDefs().
+		Keys: []string{},
+		File: position.Filename,
+		Line: position.Line,
+	}
+
+	var ok1, ok2, ok3 bool
+	md.Name, ok1 = e.constString(pkg, call.Args[0], "name")
+	md.Description, ok2 = e.constString(pkg, call.Args[1], "description")
+	var unit string
+	unit, ok3 = e.constString(pkg, call.Args[2], "unit")
+	md.Unit = metrics.Unit(unit)
+	if !ok1 || !ok2 || !ok3 {
+		return
+	}
+
+	if tagged {
+		keys, ok := e.keys(pkg, call.Args[3])
+		if !ok {
+			return
+		}
+		md.Keys = keys
+		md.ValueTypeNames = make([]string, inst.TypeArgs.Len())
+		for i := range md.ValueTypeNames {
+			md.ValueTypeNames[i] = typeName(inst.TypeArgs.At(i))
+		}
+	}
+
+	if call.Ellipsis.IsValid() {
+		e.errorf(pkg.Fset, call.Ellipsis, "can't evaluate options passed with ...")
+		return
+	}
+	for _, arg := range call.Args[nFixed:] {
+		if !e.applyOption(pkg, arg, &md) {
+			return
+		}
+	}
+	if md.Namespace != "" {
+		md.Name = md.Namespace + "." + md.Name
+	}
+
+	existing, ok := e.defs[md.Name]
+	if ok {
+		e.errs = append(e.errs, fmt.Errorf(
+			"multiple definitions for metric %s:\n\t%s:%d\n\t%s:%d",
+			md.Name,
+			existing.File, existing.Line,
+			md.File, md.Line,
+		))
+		return
+	}
+	e.defs[md.Name] = md
+}
+
+// Applies the DefOption expression arg to md, returning false if it couldn't be evaluated.
+func (e *extractor) applyOption(pkg *packages.Package, arg ast.Expr, md *metrics.Metadata) bool {
+	call, ok := ast.Unparen(arg).(*ast.CallExpr)
+	var fn *types.Func
+	if ok {
+		fn, _, ok = calledFunc(pkg.TypesInfo, call)
+	}
+	if !ok {
+		e.errorf(pkg.Fset, arg.Pos(), "can't evaluate option, options must be direct calls")
+		return false
+	}
+
+	switch fn.Name() {
+	case "WithGaugeAggregation":
+		a, ok := e.constString(pkg, call.Args[0], "gauge aggregation")
+		if !ok {
+			return false
+		}
+		md.GaugeAggregation = metrics.GaugeAggregation(a)
+	case "WithPublishOnlyOnChange":
+		md.PublishOnlyOnChange = true
+	case "WithPerUnit":
+		perUnit, ok := e.constString(pkg, call.Args[0], "per unit")
+		if !ok {
+			return false
+		}
+		md.PerUnit = metrics.Unit(perUnit)
+	case "WithShortName":
+		md.ShortName, ok = e.constString(pkg, call.Args[0], "short name")
+		if !ok {
+			return false
+		}
+	case "WithOwner":
+		md.Owner, ok = e.constString(pkg, call.Args[0], "owner")
+		if !ok {
+			return false
+		}
+	case "WithRunbook":
+		md.RunbookURL, ok = e.constString(pkg, call.Args[0], "runbook")
+		if !ok {
+			return false
+		}
+	case "WithSLO":
+		slo, ok := e.constString(pkg, call.Args[0], "SLO")
+		if !ok {
+			return false
+		}
+		md.SLOs = append(md.SLOs, slo)
+	case "WithStability":
+		stability, ok := e.constString(pkg, call.Args[0], "stability")
+		if !ok {
+			return false
+		}
+		md.Stability = metrics.Stability(stability)
+	case "WithDeprecated":
+		replacement, ok1 := e.constString(pkg, call.Args[0], "deprecation replacement")
+		removalDate, ok2 := e.constString(pkg, call.Args[1], "deprecation removal date")
+		if !ok1 || !ok2 {
+			return false
+		}
+		md.Deprecation = &metrics.Deprecation{
+			Replacement: replacement,
+			RemovalDate: removalDate,
+		}
+	case "WithPreviousName":
+		md.PreviousName, ok = e.constString(pkg, call.Args[0], "previous name")
+		if !ok {
+			return false
+		}
+	case "WithRegistry":
+		var ident *ast.Ident
+		switch r := ast.Unparen(call.Args[0]).(type) {
+		case *ast.Ident:
+			ident = r
+		case *ast.SelectorExpr:
+			ident = r.Sel
+		}
+		namespace, ok := e.namespaces[pkg.TypesInfo.Uses[ident]]
+		if ident == nil || !ok {
+			e.errorf(
+				pkg.Fset,
+				call.Args[0].Pos(),
+				"can't evaluate registry, registries must be package-level vars initialized with "+
+					"metrics.NewNamespacedRegistry",
+			)
+			return false
+		}
+		md.Namespace = namespace
+	}
+	return true
+}
+
+func (e *extractor) constString(pkg *packages.Package, expr ast.Expr, what string) (string, bool) {
+	tv, ok := pkg.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		e.errorf(pkg.Fset, expr.Pos(), "%s is not a constant string", what)
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+func (e *extractor) keys(pkg *packages.Package, expr ast.Expr) ([]string, bool) {
+	lit, ok := ast.Unparen(expr).(*ast.CompositeLit)
+	if !ok {
+		e.errorf(pkg.Fset, expr.Pos(), "tag keys are not an array literal")
+		return nil, false
+	}
+	keys := make([]string, len(lit.Elts))
+	for i, elt := range lit.Elts {
+		if _, ok := elt.(*ast.KeyValueExpr); ok {
+			e.errorf(pkg.Fset, elt.Pos(), "tag keys can't use indexed elements")
+			return nil, false
+		}
+		keys[i], ok = e.constString(pkg, elt, "tag key")
+		if !ok {
+			return nil, false
+		}
+	}
+	return keys, true
+}
+
+// Returns the package-level function in github.com/bradenaw/metrics that call calls, if it does.
+func calledFunc(info *types.Info, call *ast.CallExpr) (*types.Func, types.Instance, bool) {
+	fun := ast.Unparen(call.Fun)
+	// Generic functions may be explicitly instantiated, e.g. metrics.NewCounterDef1[string](...).
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+	var ident *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		ident = f
+	case *ast.SelectorExpr:
+		ident = f.Sel
+	default:
+		return nil, types.Instance{}, false
+	}
+	fn, ok := info.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != metricsPkgPath {
+		return nil, types.Instance{}, false
+	}
+	if fn.Type().(*types.Signature).Recv() != nil {
+		return nil, types.Instance{}, false
+	}
+	return fn, info.Instances[ident], true
+}
+
+// Returns the name of t the same way reflect.Type.String() would.
+func typeName(t types.Type) string {
+	t = types.Unalias(t)
+	if b, ok := t.(*types.Basic); ok {
+		// byte and rune are aliases, and reflect only knows them by their real names.
+		switch b.Kind() {
+		case types.Uint8:
+			return "uint8"
+		case types.Int32:
+			return "int32"
+		}
+	}
+	return types.TypeString(t, func(p *types.Package) string { return p.Name() })
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"testing"
+)
+
+// Checks that the definitions extracted from source for the example binary exactly match what it
+// dumps when it runs.
+func TestExtractMatchesDumpDefs(t *testing.T) {
+	cmd := exec.Command("go", "run", "./example", "-show-metrics")
+	cmd.Dir = "../.."
+	dumped, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("running example: %s", err)
+	}
+
+	defs, err := extract([]string{"../../example"}, true /*deps*/, false /*tests*/)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extracted, err := json.MarshalIndent(defs, "" /*prefix*/, "  " /*indent*/)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(bytes.TrimSpace(dumped), bytes.TrimSpace(extracted)) {
+		t.Fatalf("extracted:\n%s\n\ndumped:\n%s", extracted, dumped)
+	}
+}
+
+func TestExtractNamespaced(t *testing.T) {
+	defs, err := extract([]string{"./testdata/namespaced"}, false /*deps*/, false /*tests*/)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, ok := defs["rpclib.requests"]
+	if !ok {
+		t.Fatalf("expected rpclib.requests, got %v", defs)
+	}
+	if md.Name != "rpclib.requests" || md.Namespace != "rpclib" || md.PreviousName != "requests" {
+		t.Fatalf("unexpected metadata %#v", md)
+	}
+}
+
+func TestExtractTests(t *testing.T) {
+	for _, deps := range []bool{false, true} {
+		defs, err := extract([]string{"./testdata/withtests"}, deps, true /*tests*/)
+		if err != nil {
+			t.Fatalf("deps=%t: %s", deps, err)
+		}
+		for _, name := range []string{"withtests_requests", "withtests_test_requests"} {
+			if _, ok := defs[name]; !ok {
+				t.Errorf("deps=%t: expected %s, got %v", deps, name, defs)
+			}
+		}
+	}
+}
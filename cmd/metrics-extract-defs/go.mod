@@ -0,0 +1,19 @@
+module github.com/bradenaw/metrics/cmd/metrics-extract-defs
+
+go 1.25.0
+
+require (
+	github.com/bradenaw/metrics v0.0.0-20230830160715-f8023a8e07fd
+	golang.org/x/tools v0.47.0
+)
+
+require (
+	github.com/bradenaw/juniper v0.13.0 // indirect
+	golang.org/x/exp v0.0.0-20220217172124-1812c5b45e43 // indirect
+	golang.org/x/mod v0.37.0 // indirect
+	golang.org/x/sync v0.21.0 // indirect
+)
+
+// Always build against the definitions in this repository so that the output matches
+// metrics.DumpDefs() exactly.
+replace github.com/bradenaw/metrics => ../..
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bradenaw/metrics"
+	"golang.org/x/tools/go/packages"
+)
+
+func main() {
+	err := main2()
+	if err != nil {
+		panic(err)
+	}
+}
+
+var usage = `
+metrics-extract-defs finds github.com/bradenaw/metrics definitions in source and prints them as
+JSON in the same format as metrics.DumpDefs(), without needing to build or run the binary that
+contains them. Its output can be passed to metrics-sync-metadata.
+
+Arguments are package patterns as accepted by the go command. For example, to sync every metric
+defined anywhere in a repository:
+
+	metrics-extract-defs ./... | DD_API_KEY="<DD_API_KEY>" DD_APP_KEY="<DD_APP_KEY>" metrics-sync-metadata
+
+Only definitions made at init time, in a top-level var block or func init(), are included since
+others will not produce any data. All arguments to the definitions must be constants.
+`
+
+func main2() error {
+	deps := flag.Bool(
+		"deps",
+		true, // default
+		"If set, also includes definitions from the dependencies of the given packages, including "+
+			"the ones made by github.com/bradenaw/metrics itself, as metrics.DumpDefs() would.",
+	)
+	tests := flag.Bool(
+		"tests",
+		false, // default
+		"If set, also includes definitions from test files.",
+	)
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage of %s: [flags] [packages]\n", os.Args[0])
+		fmt.Fprint(out, usage)
+		fmt.Fprint(out, "\n\n")
+		fmt.Fprint(out, "Flags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	defs, err := extract(patterns, *deps, *tests)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(defs, "" /*prefix*/, "  " /*indent*/)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+func extract(patterns []string, deps bool, tests bool) (map[string]metrics.Metadata, error) {
+	mode := packages.NeedName |
+		packages.NeedFiles |
+		packages.NeedSyntax |
+		packages.NeedTypes |
+		packages.NeedTypesInfo
+	if deps {
+		mode |= packages.NeedImports | packages.NeedDeps
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: mode, Tests: tests}, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, errors.New("errors loading packages")
+	}
+
+	e := newExtractor()
+	if deps {
+		packages.Visit(pkgs, nil /*pre*/, e.extractPackage)
+	} else {
+		for _, pkg := range pkgs {
+			e.extractPackage(pkg)
+		}
+	}
+	if len(e.errs) > 0 {
+		return nil, errors.Join(e.errs...)
+	}
+	return e.defs, nil
+}
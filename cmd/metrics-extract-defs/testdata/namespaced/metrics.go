@@ -0,0 +1,15 @@
+package namespaced
+
+import "github.com/bradenaw/metrics"
+
+var (
+	requestsDef = metrics.NewCounterDef(
+		"requests",
+		"The number of requests sent.",
+		metrics.UnitRequest,
+		metrics.WithRegistry(registry),
+		metrics.WithPreviousName("requests"),
+	)
+
+	registry = metrics.NewNamespacedRegistry("rpclib")
+)
@@ -0,0 +1,9 @@
+package withtests
+
+import "github.com/bradenaw/metrics"
+
+var requestsDef = metrics.NewCounterDef(
+	"withtests_requests",
+	"The number of requests sent.",
+	metrics.UnitRequest,
+)
@@ -0,0 +1,9 @@
+package withtests
+
+import "github.com/bradenaw/metrics"
+
+var testRequestsDef = metrics.NewCounterDef(
+	"withtests_test_requests",
+	"The number of requests sent by tests.",
+	metrics.UnitRequest,
+)
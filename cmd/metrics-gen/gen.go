@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// The longest a line of a generated description is allowed to be before it's split, not including
+// indentation.
+const descriptionWidth = 88
+
+type genMetric struct {
+	Variable    string
+	Func        string
+	Name        string
+	Description []string
+	Unit        string
+	Keys        []string
+	SampleRate  string
+}
+
+// generate returns the source of a metrics.go that defines each of the metrics in s. specName is
+// the name of the spec file, for the header. s must be valid.
+func generate(s spec, units map[string]string, specName string) ([]byte, error) {
+	ms := make([]genMetric, 0, len(s.Metrics))
+	for _, m := range s.Metrics {
+		g := genMetric{
+			Variable:    m.variable(),
+			Func:        "New" + metricTypes[m.Type] + "Def",
+			Name:        strconv.Quote(m.Name),
+			Description: wrapString(m.Description, descriptionWidth),
+			Unit:        units[m.Unit],
+		}
+		if len(m.Tags) > 0 {
+			typeArgs := make([]string, len(m.Tags))
+			g.Keys = make([]string, len(m.Tags))
+			for i, tag := range m.Tags {
+				typeArgs[i] = tag.Type
+				g.Keys[i] = strconv.Quote(tag.Key)
+			}
+			g.Func += strconv.Itoa(len(m.Tags)) + "[" + strings.Join(typeArgs, ", ") + "]"
+		}
+		if m.Type == "distribution" || m.Type == "set" {
+			sampleRate := 1.0
+			if m.SampleRate != nil {
+				sampleRate = *m.SampleRate
+			}
+			g.SampleRate = strconv.FormatFloat(sampleRate, 'f', -1, 64)
+			if !strings.Contains(g.SampleRate, ".") {
+				g.SampleRate += ".0"
+			}
+		}
+		ms = append(ms, g)
+	}
+
+	var buf bytes.Buffer
+	err := fileTmpl.Execute(&buf, struct {
+		SpecName string
+		Package  string
+		Metrics  []genMetric
+	}{
+		SpecName: specName,
+		Package:  s.Package,
+		Metrics:  ms,
+	})
+	if err != nil {
+		return nil, err
+	}
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, buf.Bytes())
+	}
+	return b, nil
+}
+
+// wrapString splits s into quoted string literals of at most width characters each where possible,
+// breaking after spaces, so that long descriptions are readable in the generated code.
+func wrapString(s string, width int) []string {
+	var lines []string
+	for len(s) > width {
+		i := strings.LastIndex(s[:width], " ")
+		if i <= 0 {
+			break
+		}
+		lines = append(lines, strconv.Quote(s[:i+1]))
+		s = s[i+1:]
+	}
+	return append(lines, strconv.Quote(s))
+}
+
+var fileTmpl = template.Must(template.New("file").Parse(`// Code generated by metrics-gen from {{.SpecName}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"github.com/bradenaw/metrics"
+)
+
+var (
+{{- range .Metrics}}
+	{{.Variable}} = metrics.{{.Func}}(
+		{{.Name}},
+		{{range $i, $line := .Description}}{{if $i}}+
+			{{end}}{{$line}}{{end}},
+		metrics.{{.Unit}},
+		{{- if .Keys}}
+		[...]string{ {{- range $i, $key := .Keys}}{{if $i}}, {{end}}{{$key}}{{end -}} },
+		{{- end}}
+		{{- if .SampleRate}}
+		{{.SampleRate}},
+		{{- end}}
+	)
+{{end -}}
+)
+`))
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bradenaw/metrics"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestLoadUnits(t *testing.T) {
+	units, err := loadUnits(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if units[string(metrics.UnitRequest)] != "UnitRequest" {
+		t.Fatalf("expected %q to be UnitRequest, got %q", metrics.UnitRequest, units["request"])
+	}
+	if units[string(metrics.UnitDegreeCelsius)] != "UnitDegreeCelsius" {
+		t.Fatalf(
+			"expected %q to be UnitDegreeCelsius, got %q",
+			metrics.UnitDegreeCelsius,
+			units[string(metrics.UnitDegreeCelsius)],
+		)
+	}
+}
+
+// testdata/rpc/metrics.go is generated from testdata/rpc/metrics.yaml. Regenerate it with
+//
+//	go run . --spec testdata/rpc/metrics.yaml
+func TestGenerateGolden(t *testing.T) {
+	b, err := os.ReadFile("testdata/rpc/metrics.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := parseSpec("metrics.yaml", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	units, err := loadUnits(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := s.validate(units)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	generated, err := generate(s, units, "metrics.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing, err := os.ReadFile("testdata/rpc/metrics.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = checkUpToDate("testdata/rpc/metrics.go", "metrics.yaml", existing, generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Make sure the generated code compiles.
+	pkgs, err := packages.Load(
+		&packages.Config{Mode: packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax},
+		"./testdata/rpc",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packages.PrintErrors(pkgs)
+	if len(pkgs) != 1 || len(pkgs[0].Errors) > 0 {
+		t.Fatal("generated code doesn't compile")
+	}
+}
+
+func TestCheckUpToDate(t *testing.T) {
+	err := checkUpToDate("metrics.go", "metrics.yaml", []byte("a\nb\nc\n"), []byte("a\nb\nc\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = checkUpToDate("metrics.go", "metrics.yaml", []byte("a\nx\nc\n"), []byte("a\nb\nc\n"))
+	if err == nil || !strings.Contains(err.Error(), `line 2:`+"\n\thave: \"x\"\n\twant: \"b\"") {
+		t.Fatalf("expected difference at line 2, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	b := []byte(`
+package: rpc
+metrics:
+  - name: Bad-Name
+    type: histogram
+    unit: requests
+    tags:
+      - key: host
+        type: string
+      - key: method
+        type: "*Request"
+  - name: rpc_latency
+    type: counter
+    unit: second
+    sampleRate: 0.5
+  - name: rpc_latency
+    type: distribution
+    unit: second
+    variable: rpcLatency
+`)
+	s, err := parseSpec("metrics.yaml", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	units, err := loadUnits(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := s.validate(units)
+	var msgs []string
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	all := strings.Join(msgs, "\n")
+	for _, expected := range []string{
+		"metrics[0] (Bad-Name): name doesn't match",
+		`metrics[0] (Bad-Name): unknown type "histogram"`,
+		`metrics[0] (Bad-Name): unknown unit "requests"`,
+		`metrics[0] (Bad-Name): uses reserved tag key "host"`,
+		`metrics[0] (Bad-Name): tag "method" has unsupported type "*Request"`,
+		"metrics[1] (rpc_latency): sampleRate only applies to distributions and sets",
+		"metrics[2] (rpc_latency): duplicate metric name",
+		`metrics[2] (rpc_latency): variable "rpcLatency" should have a name ending in Def`,
+	} {
+		if !strings.Contains(all, expected) {
+			t.Errorf("expected error containing %q, got:\n%s", expected, all)
+		}
+	}
+}
+
+func TestParseSpecUnknownField(t *testing.T) {
+	_, err := parseSpec("metrics.yaml", []byte("package: rpc\nmetrics:\n  - name: a\n    unti: second\n"))
+	if err == nil {
+		t.Fatal("expected unknown field to be an error")
+	}
+	_, err = parseSpec("metrics.json", []byte(`{"package": "rpc", "metrix": []}`))
+	if err == nil {
+		t.Fatal("expected unknown field to be an error")
+	}
+}
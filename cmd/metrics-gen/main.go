@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	err := main2()
+	if err != nil {
+		panic(err)
+	}
+}
+
+var usage = `
+metrics-gen generates the metrics.go for a package from a spec file, so that metric definitions can
+be reviewed by people who don't read Go. The spec can be YAML or JSON (if its name ends in .json),
+for example:
+
+	package: rpc
+	metrics:
+	  - name: rpc_responses
+	    type: counter
+	    description: Counts responses sent by the server.
+	    unit: response
+	    tags:
+	      - key: method
+	        type: string
+	      - key: code
+	        type: int
+	  - name: rpc_latency
+	    type: distribution
+	    description: How long it takes to respond to each request.
+	    unit: second
+	    sampleRate: 0.1
+
+Types are counter, gauge, distribution, and set. Units are as listed in
+https://docs.datadoghq.com/metrics/units/#unit-list. Tag types are any of Go's predeclared types,
+like string, int, or bool. Each metric gets a variable named from its name and type, for example
+rpcResponsesCounterDef, unless given with variable.
+
+Normally this is run with go:generate next to the spec:
+
+	//go:generate metrics-gen --spec metrics.yaml
+
+and with --check in CI, which fails if metrics.go is out of date with the spec instead of writing
+it.
+`
+
+func main2() error {
+	specPath := flag.String(
+		"spec",
+		"metrics.yaml", // default
+		"The path to the spec file.",
+	)
+	out := flag.String(
+		"out",
+		"", // default
+		"The path to write the generated code to. Defaults to metrics.go in the same directory as "+
+			"--spec, which is where definitions must be for github.com/bradenaw/metrics to accept "+
+			"them.",
+	)
+	check := flag.Bool(
+		"check",
+		false, // default
+		"If set, doesn't write --out and instead fails if it doesn't match what would be written.",
+	)
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+		fmt.Fprint(out, usage)
+		fmt.Fprint(out, "\n\n")
+		fmt.Fprint(out, "Flags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *out == "" {
+		*out = filepath.Join(filepath.Dir(*specPath), "metrics.go")
+	}
+
+	b, err := os.ReadFile(*specPath)
+	if err != nil {
+		return err
+	}
+	s, err := parseSpec(*specPath, b)
+	if err != nil {
+		return err
+	}
+	units, err := loadUnits(filepath.Dir(*specPath))
+	if err != nil {
+		return err
+	}
+	errs := s.validate(units)
+	if len(errs) > 0 {
+		return fmt.Errorf("%s is invalid:\n%w", *specPath, errors.Join(errs...))
+	}
+
+	generated, err := generate(s, units, filepath.Base(*specPath))
+	if err != nil {
+		return err
+	}
+
+	if *check {
+		existing, err := os.ReadFile(*out)
+		if err != nil {
+			return err
+		}
+		return checkUpToDate(*out, *specPath, existing, generated)
+	}
+	return os.WriteFile(*out, generated, 0o644)
+}
+
+// checkUpToDate returns an error pointing at the first difference if existing isn't the same as
+// generated.
+func checkUpToDate(outPath string, specPath string, existing []byte, generated []byte) error {
+	if bytes.Equal(existing, generated) {
+		return nil
+	}
+	existingLines := strings.Split(string(existing), "\n")
+	generatedLines := strings.Split(string(generated), "\n")
+	i := 0
+	for i < len(existingLines) && i < len(generatedLines) && existingLines[i] == generatedLines[i] {
+		i++
+	}
+	lineAt := func(lines []string) string {
+		if i >= len(lines) {
+			return "<end of file>"
+		}
+		return strconv.Quote(lines[i])
+	}
+	return fmt.Errorf(
+		"%s is out of date with %s, run metrics-gen to regenerate it\n"+
+			"first difference at line %d:\n\thave: %s\n\twant: %s",
+		outPath, specPath, i+1, lineAt(existingLines), lineAt(generatedLines),
+	)
+}
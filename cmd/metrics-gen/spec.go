@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// spec is the declarative definition of the metrics for a single package.
+type spec struct {
+	// The name of the Go package to generate.
+	Package string       `json:"package" yaml:"package"`
+	Metrics []metricSpec `json:"metrics" yaml:"metrics"`
+}
+
+type metricSpec struct {
+	Name string `json:"name" yaml:"name"`
+	// One of counter, gauge, distribution, or set.
+	Type        string `json:"type" yaml:"type"`
+	Description string `json:"description" yaml:"description"`
+	// As given in https://docs.datadoghq.com/metrics/units/#unit-list, for example "request".
+	Unit string    `json:"unit" yaml:"unit"`
+	Tags []tagSpec `json:"tags" yaml:"tags"`
+	// Only for distributions and sets, defaults to 1.
+	SampleRate *float64 `json:"sampleRate" yaml:"sampleRate"`
+	// The name of the generated variable. If empty, it's made from the name and type, for example
+	// rpcResponsesCounterDef for a counter called rpc_responses.
+	Variable string `json:"variable" yaml:"variable"`
+}
+
+type tagSpec struct {
+	Key string `json:"key" yaml:"key"`
+	// The Go type of the tag's values, for example string or int.
+	Type string `json:"type" yaml:"type"`
+}
+
+// parseSpec parses b as JSON if path ends in .json and YAML otherwise. Unknown fields are an error,
+// since they're most likely typos.
+func parseSpec(path string, b []byte) (spec, error) {
+	var s spec
+	if filepath.Ext(path) == ".json" {
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.DisallowUnknownFields()
+		err := d.Decode(&s)
+		if err != nil {
+			return spec{}, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	} else {
+		d := yaml.NewDecoder(bytes.NewReader(b))
+		d.KnownFields(true)
+		err := d.Decode(&s)
+		if err != nil {
+			return spec{}, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	return s, nil
+}
+
+// The below are kept in sync with Registry.define in github.com/bradenaw/metrics, so that problems
+// are reported against the spec rather than as a panic from the generated code.
+
+// https://docs.datadoghq.com/metrics/custom_metrics/#naming-custom-metrics
+var nameRegexp = regexp.MustCompile("^[a-z][a-zA-Z0-9_.]{0,199}$")
+
+// https://docs.datadoghq.com/getting_started/tagging/
+var tagKeyRegexp = regexp.MustCompile("^(|[a-z][a-zA-Z0-9_./-]{0,199})$")
+
+// From https://docs.datadoghq.com/getting_started/tagging/
+var reservedTagKeys = map[string]struct{}{
+	"host":    {},
+	"device":  {},
+	"source":  {},
+	"service": {},
+	"env":     {},
+	"version": {},
+}
+
+var identRegexp = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+const (
+	maxDescriptionLen = 400
+	maxTags           = 8
+)
+
+var metricTypes = map[string]string{
+	"counter":      "Counter",
+	"gauge":        "Gauge",
+	"distribution": "Distribution",
+	"set":          "Set",
+}
+
+// Tag value types are limited to the predeclared types so that specs can be reviewed without
+// knowing about any Go packages.
+var tagTypes = map[string]struct{}{
+	"string": {},
+	"bool":   {},
+	"int":    {},
+	"int8":   {},
+	"int16":  {},
+	"int32":  {},
+	"int64":  {},
+	"uint":   {},
+	"uint8":  {},
+	"uint16": {},
+	"uint32": {},
+	"uint64": {},
+}
+
+// validate returns every problem with s, so that they can all be fixed at once. units maps each
+// valid unit to the name of its constant in the metrics package.
+func (s spec) validate(units map[string]string) []error {
+	var errs []error
+	errorf := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !identRegexp.MatchString(s.Package) {
+		errorf("package %q is not a valid package name", s.Package)
+	}
+
+	names := make(map[string]struct{}, len(s.Metrics))
+	variables := make(map[string]string, len(s.Metrics))
+	for i, m := range s.Metrics {
+		where := fmt.Sprintf("metrics[%d] (%s)", i, m.Name)
+		if !nameRegexp.MatchString(m.Name) {
+			errorf("%s: name doesn't match %s", where, nameRegexp)
+		}
+		if _, ok := names[m.Name]; ok {
+			errorf("%s: duplicate metric name", where)
+		}
+		names[m.Name] = struct{}{}
+
+		_, ok := metricTypes[m.Type]
+		if !ok {
+			errorf("%s: unknown type %q, expected counter, gauge, distribution, or set", where, m.Type)
+		}
+		if len(m.Description) > maxDescriptionLen {
+			errorf(
+				"%s: description cannot be more than %d characters, this one is %d",
+				where, maxDescriptionLen, len(m.Description),
+			)
+		}
+		if _, ok := units[m.Unit]; !ok {
+			errorf(
+				"%s: unknown unit %q, see https://docs.datadoghq.com/metrics/units/#unit-list",
+				where, m.Unit,
+			)
+		}
+
+		if len(m.Tags) > maxTags {
+			errorf("%s: at most %d tags are supported, this has %d", where, maxTags, len(m.Tags))
+		}
+		keys := make(map[string]struct{}, len(m.Tags))
+		for _, tag := range m.Tags {
+			if _, reserved := reservedTagKeys[tag.Key]; reserved {
+				errorf("%s: uses reserved tag key %q", where, tag.Key)
+			} else if !tagKeyRegexp.MatchString(tag.Key) {
+				errorf("%s: tag key %q doesn't match %s", where, tag.Key, tagKeyRegexp)
+			}
+			if _, ok := keys[tag.Key]; ok && tag.Key != "" {
+				errorf("%s: duplicate tag key %q", where, tag.Key)
+			}
+			keys[tag.Key] = struct{}{}
+			if _, ok := tagTypes[tag.Type]; !ok {
+				errorf("%s: tag %q has unsupported type %q", where, tag.Key, tag.Type)
+			}
+		}
+
+		if m.SampleRate != nil {
+			if m.Type != "distribution" && m.Type != "set" {
+				errorf("%s: sampleRate only applies to distributions and sets", where)
+			} else if *m.SampleRate <= 0 || *m.SampleRate > 1 {
+				errorf("%s: sampleRate must be in (0, 1], got %v", where, *m.SampleRate)
+			}
+		}
+
+		variable := m.variable()
+		if !identRegexp.MatchString(variable) {
+			errorf("%s: variable %q is not a valid identifier", where, variable)
+		} else if !strings.HasSuffix(variable, "Def") {
+			errorf("%s: variable %q should have a name ending in Def", where, variable)
+		}
+		if other, ok := variables[variable]; ok {
+			errorf("%s: variable %s is also used by %s", where, variable, other)
+		}
+		variables[variable] = m.Name
+	}
+	return errs
+}
+
+// variable returns the name of the variable to generate for m.
+func (m metricSpec) variable() string {
+	if m.Variable != "" {
+		return m.Variable
+	}
+	var sb strings.Builder
+	for i, word := range strings.FieldsFunc(m.Name, func(r rune) bool { return r == '_' || r == '.' }) {
+		if i == 0 {
+			sb.WriteString(word)
+		} else {
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	sb.WriteString(metricTypes[m.Type])
+	sb.WriteString("Def")
+	return sb.String()
+}
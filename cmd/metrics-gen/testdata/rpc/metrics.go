@@ -0,0 +1,38 @@
+// Code generated by metrics-gen from metrics.yaml. DO NOT EDIT.
+
+package rpc
+
+import (
+	"github.com/bradenaw/metrics"
+)
+
+var (
+	rpcResponsesCounterDef = metrics.NewCounterDef2[string, int](
+		"rpc_responses",
+		"Counts responses sent by the server, by the method that was called and the status code "+
+			"that was returned.",
+		metrics.UnitResponse,
+		[...]string{"method", "code"},
+	)
+
+	rpcLatencyDistributionDef = metrics.NewDistributionDef(
+		"rpc_latency",
+		"How long it takes to respond to each request.",
+		metrics.UnitSecond,
+		0.1,
+	)
+
+	inFlightDef = metrics.NewGaugeDef(
+		"rpc.in_flight",
+		"The number of requests currently being handled.",
+		metrics.UnitRequest,
+	)
+
+	rpcClientsSetDef = metrics.NewSetDef1[bool](
+		"rpc_clients",
+		"The unique clients that have made requests.",
+		metrics.UnitConnection,
+		[...]string{""},
+		1.0,
+	)
+)
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const metricsPkgPath = "github.com/bradenaw/metrics"
+
+// loadUnits returns a map from each unit to the name of its constant in the version of the metrics
+// package that's used by the module in dir, for example "request" to "UnitRequest".
+func loadUnits(dir string) (map[string]string, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes,
+		Dir:  dir,
+	}, metricsPkgPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected to load 1 package for %s, got %d", metricsPkgPath, len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		errs := make([]error, len(pkg.Errors))
+		for i := range pkg.Errors {
+			errs[i] = pkg.Errors[i]
+		}
+		return nil, fmt.Errorf("loading %s: %w", metricsPkgPath, errors.Join(errs...))
+	}
+
+	unitType := pkg.Types.Scope().Lookup("Unit")
+	if unitType == nil {
+		return nil, fmt.Errorf("%s has no type Unit", metricsPkgPath)
+	}
+	units := make(map[string]string)
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !c.Exported() || !types.Identical(c.Type(), unitType.Type()) {
+			continue
+		}
+		units[constant.StringVal(c.Val())] = name
+	}
+	return units, nil
+}
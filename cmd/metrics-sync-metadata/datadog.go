@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
+	"github.com/bradenaw/juniper/xmaps"
+	"github.com/bradenaw/metrics"
+)
+
+// DataDog's flush interval. See the comment on flushInterval in github.com/bradenaw/metrics.
+const datadogFlushIntervalSeconds = 10
+
+// datadogSink syncs metadata to DataDog using the V1 Metrics API.
+type datadogSink struct {
+	api         *datadogV1.MetricsApi
+	concurrency int
+}
+
+// newDatadogSink returns a sink that talks to the DataDog API, authenticated using the DD_API_KEY
+// and DD_APP_KEY environment variables. If url is non-empty, requests are sent there instead of to
+// DataDog, which is useful for testing against a stand-in server.
+func newDatadogSink(url string, concurrency int) *datadogSink {
+	configuration := datadog.NewConfiguration()
+	// Even with limited concurrency we may end up getting 429'd.
+	configuration.RetryConfiguration.EnableRetry = true
+	configuration.RetryConfiguration.MaxRetries = 20
+	if url != "" {
+		configuration.Servers = datadog.ServerConfigurations{{URL: url}}
+	}
+	return &datadogSink{
+		api:         datadogV1.NewMetricsApi(datadog.NewAPIClient(configuration)),
+		concurrency: concurrency,
+	}
+}
+
+func (s *datadogSink) Diff(ctx context.Context, items []syncItem) ([]string, error) {
+	ctx = datadog.NewDefaultContext(ctx)
+
+	// Check against ListActiveMetrics to avoid accidentally polluting if, for example,
+	// --metric-prefix is missing or incorrect.
+	resp, _, err := s.api.ListActiveMetrics(
+		ctx,
+		time.Now().Add(-30*24*time.Hour).Unix(),
+		*datadogV1.NewListActiveMetricsOptionalParameters(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	metricNames := xmaps.SetFromSlice(resp.Metrics)
+	for i := range items {
+		if !metricNames.Contains(items[i].fullName) {
+			items[i].skipped = "has not been emitted to DataDog in the last 30d"
+		}
+	}
+
+	forEachParallel(items, s.concurrency, func(item *syncItem) {
+		if item.skipped != "" {
+			return
+		}
+		current, r, err := s.api.GetMetricMetadata(ctx, item.fullName)
+		if err != nil {
+			item.err = fmt.Errorf(
+				"calling `MetricsApi.GetMetricMetadata` for %s: %w\nFull HTTP response: %v",
+				item.fullName, err, r,
+			)
+			return
+		}
+		item.changes = diffMetadata(current, desiredMetadata(item.md))
+	})
+	// DataDog metrics can't be deleted, they just stop being reported.
+	return nil, nil
+}
+
+func (s *datadogSink) Apply(ctx context.Context, items []syncItem) error {
+	ctx = datadog.NewDefaultContext(ctx)
+
+	forEachParallel(items, s.concurrency, func(item *syncItem) {
+		if item.err != nil || item.skipped != "" || len(item.changes) == 0 {
+			return
+		}
+		_, r, err := s.api.UpdateMetricMetadata(ctx, item.fullName, desiredMetadata(item.md))
+		if err != nil {
+			item.err = fmt.Errorf(
+				"calling `MetricsApi.UpdateMetricMetadata` for %s: %w\nFull HTTP response: %v",
+				item.fullName, err, r,
+			)
+		}
+	})
+	return nil
+}
+
+// desiredMetadata returns the metadata that DataDog should have for md.
+func desiredMetadata(md metrics.Metadata) datadogV1.MetricMetadata {
+	// If longer, UpdateMetricMetadata will return 400 with no additional information. The top-level
+	// package keeps descriptions themselves shorter than this, but the rest of the metadata may push
+	// it over.
+	description := describe(md, 400)
+
+	desired := datadogV1.MetricMetadata{
+		Unit:        datadog.PtrString(string(md.Unit)),
+		Description: &description,
+	}
+
+	// https://docs.datadoghq.com/metrics/types/?tab=count#metric-types
+	switch md.MetricType {
+	case metrics.CounterType:
+		desired.Type = datadog.PtrString("count")
+		// Counts are aggregated by the agent into buckets of its flush interval.
+		desired.StatsdInterval = datadog.PtrInt64(datadogFlushIntervalSeconds)
+	case metrics.GaugeType:
+		desired.Type = datadog.PtrString("gauge")
+	case metrics.DistributionType:
+		desired.Type = datadog.PtrString("distribution")
+	case metrics.SetType:
+		// Sets are stored as gauges of the number of unique values.
+		desired.Type = datadog.PtrString("gauge")
+	}
+
+	if md.PerUnit != "" {
+		desired.PerUnit = datadog.PtrString(string(md.PerUnit))
+	}
+	if md.ShortName != "" {
+		desired.ShortName = datadog.PtrString(md.ShortName)
+	}
+	return desired
+}
+
+// diffMetadata returns the fields set in desired that are different in current. Fields that are nil
+// in desired are left as they are, so they're never considered changed. DataDog returns null for
+// empty fields, for example the unit of a metric without one, so nil in current is the same as "".
+func diffMetadata(current datadogV1.MetricMetadata, desired datadogV1.MetricMetadata) []fieldChange {
+	var changes []fieldChange
+	diffString := func(field string, current *string, desired *string) {
+		if desired == nil {
+			return
+		}
+		if derefOr(current, "") != *desired {
+			changes = append(changes, fieldChange{
+				field:  field,
+				before: derefOr(current, ""),
+				after:  *desired,
+			})
+		}
+	}
+
+	diffString("type", current.Type, desired.Type)
+	diffString("unit", current.Unit, desired.Unit)
+	diffString("per_unit", current.PerUnit, desired.PerUnit)
+	diffString("short_name", current.ShortName, desired.ShortName)
+	diffString("description", current.Description, desired.Description)
+	if desired.StatsdInterval != nil &&
+		(current.StatsdInterval == nil || *current.StatsdInterval != *desired.StatsdInterval) {
+		before := ""
+		if current.StatsdInterval != nil {
+			before = strconv.FormatInt(*current.StatsdInterval, 10)
+		}
+		changes = append(changes, fieldChange{
+			field:  "statsd_interval",
+			before: before,
+			after:  strconv.FormatInt(*desired.StatsdInterval, 10),
+		})
+	}
+	return changes
+}
@@ -1,8 +1,6 @@
 module github.com/bradenaw/metrics/cmd/metrics-sync-metadata
 
-go 1.21
-
-toolchain go1.21.1
+go 1.22
 
 require (
 	github.com/DataDog/datadog-api-client-go/v2 v2.17.0
@@ -20,3 +18,5 @@ require (
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/protobuf v1.31.0 // indirect
 )
+
+replace github.com/bradenaw/metrics => ../..
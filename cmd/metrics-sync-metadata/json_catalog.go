@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/bradenaw/metrics"
+)
+
+// jsonCatalogSink writes metadata to a local JSON file in the same format as metrics.DumpDefs(), but
+// keyed by full name, so that it can be read by the other metrics-* tools. The file is rewritten to
+// contain exactly the synced metrics.
+type jsonCatalogSink struct {
+	path string
+}
+
+func (s *jsonCatalogSink) Diff(ctx context.Context, items []syncItem) ([]string, error) {
+	current, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	desiredNames := make(map[string]struct{}, len(items))
+	for i := range items {
+		desiredNames[items[i].fullName] = struct{}{}
+		var currentFields []field
+		if md, ok := current[items[i].fullName]; ok {
+			currentFields, err = jsonFields(md)
+			if err != nil {
+				return nil, err
+			}
+		}
+		desiredFields, err := jsonFields(catalogMetadata(items[i]))
+		if err != nil {
+			return nil, err
+		}
+		items[i].changes = diffFields(currentFields, desiredFields)
+	}
+	var removed []string
+	for name := range current {
+		if _, ok := desiredNames[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+func (s *jsonCatalogSink) Apply(ctx context.Context, items []syncItem) error {
+	catalog := make(map[string]metrics.Metadata, len(items))
+	for _, item := range items {
+		if item.skipped != "" {
+			continue
+		}
+		catalog[item.fullName] = catalogMetadata(item)
+	}
+	b, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, append(b, '\n'))
+}
+
+func (s *jsonCatalogSink) read() (map[string]metrics.Metadata, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var current map[string]metrics.Metadata
+	err = json.Unmarshal(b, &current)
+	if err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// catalogMetadata returns the metadata as it's stored in the catalog, under its full name.
+func catalogMetadata(item syncItem) metrics.Metadata {
+	md := item.md
+	md.Name = item.fullName
+	return md
+}
+
+// jsonFields returns each of the top-level JSON fields of md, so that fields are compared the same
+// way they're stored and new fields in metrics.Metadata are included automatically.
+func jsonFields(md metrics.Metadata) ([]field, error) {
+	b, err := json.Marshal(md)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]json.RawMessage
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]field, 0, len(m))
+	for name, value := range m {
+		fields = append(fields, field{name: name, value: string(value)})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+	return fields, nil
+}
@@ -3,15 +3,13 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
-	"time"
 
-	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
-	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
-	"github.com/bradenaw/juniper/xmaps"
 	"github.com/bradenaw/metrics"
 )
 
@@ -28,6 +26,12 @@ definitions in github.com/bradenaw/metrics also require entering units and descr
 these live in source alongside the code that emits them.  This program takes that metadata and sends
 it to DataDog so that it appears the same way in the UI, including units on graphs.
 
+It first fetches the current metadata of each metric and prints the changes it's going to make, then
+only updates the metrics that differ. With --dry-run, it stops after printing the changes.
+
+With --sink, the same metadata can instead be synced to a Prometheus metadata file, an OpenTelemetry
+schema registry, or a local JSON catalog.
+
 This program parses metric metadata from stdin in the same format that it's emitted from
 metrics.DumpDefs(). Normally, your binary that emits metrics should accept an additional flag that
 makes it call metrics.DumpDefs() and exit.  After building for production, run the binary with that
@@ -66,14 +70,54 @@ For example:
 ==== after building ./foo ==========================================================================
 
 	./foo --dump-metric-defs | DD_API_KEY="<DD_API_KEY>" DD_APP_KEY="<DD_APP_KEY>" metrics-sync-metadata
+
+Alternatively, metrics-extract-defs produces the same output from source without building or running
+anything:
+
+	metrics-extract-defs ./... | DD_API_KEY="<DD_API_KEY>" DD_APP_KEY="<DD_APP_KEY>" metrics-sync-metadata
 `
 
 func main2() error {
+	sinkName := flag.String(
+		"sink",
+		"datadog", // default
+		"Where to sync metadata to, one of:\n"+
+			"  datadog     the DataDog API, using DD_API_KEY and DD_APP_KEY from the environment\n"+
+			"  prometheus  a file of Prometheus/OpenMetrics HELP, TYPE, and UNIT lines at --output\n"+
+			"  otlp        an OpenTelemetry schema registry at --otlp-registry-url\n"+
+			"  json        a local JSON catalog at --output in the same format as metrics.DumpDefs()",
+	)
+	output := flag.String(
+		"output",
+		"", // default
+		"The file to write to for --sink=prometheus and --sink=json.",
+	)
+	datadogURL := flag.String(
+		"datadog-url",
+		"", // default
+		"If set, sends requests for --sink=datadog here instead of to DataDog, for example to a "+
+			"stand-in server for testing.",
+	)
+	otlpRegistryURL := flag.String(
+		"otlp-registry-url",
+		"", // default
+		"The base URL of the schema registry for --sink=otlp.",
+	)
 	metricPrefix := flag.String(
 		"metric-prefix",
 		"", // default
 		"If supplied, places this plus a dot before each metric name.",
 	)
+	dryRun := flag.Bool(
+		"dry-run",
+		false, // default
+		"If set, prints the changes that would be made without making them.",
+	)
+	concurrency := flag.Int(
+		"concurrency",
+		8, // default
+		"The maximum number of requests to have in flight at once, for sinks that make requests.",
+	)
 	flag.Usage = func() {
 		out := flag.CommandLine.Output()
 		fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
@@ -84,6 +128,32 @@ func main2() error {
 	}
 	flag.Parse()
 
+	var sink MetadataSink
+	switch *sinkName {
+	case "datadog":
+		sink = newDatadogSink(*datadogURL, *concurrency)
+	case "prometheus", "json":
+		if *output == "" {
+			return fmt.Errorf("--output is required for --sink=%s", *sinkName)
+		}
+		if *sinkName == "prometheus" {
+			sink = &prometheusSink{path: *output}
+		} else {
+			sink = &jsonCatalogSink{path: *output}
+		}
+	case "otlp":
+		if *otlpRegistryURL == "" {
+			return errors.New("--otlp-registry-url is required for --sink=otlp")
+		}
+		sink = &otlpSink{
+			url:         *otlpRegistryURL,
+			client:      http.DefaultClient,
+			concurrency: *concurrency,
+		}
+	default:
+		return fmt.Errorf("unknown --sink %q", *sinkName)
+	}
+
 	b, err := io.ReadAll(os.Stdin)
 	if err != nil {
 		return err
@@ -94,60 +164,6 @@ func main2() error {
 		return err
 	}
 
-	ctx := datadog.NewDefaultContext(context.Background())
-	configuration := datadog.NewConfiguration()
-	// Since we're making a bunch of calls to update metrics, we may end up getting 429'd.
-	configuration.RetryConfiguration.EnableRetry = true
-	configuration.RetryConfiguration.MaxRetries = 1_000_000
-	apiClient := datadog.NewAPIClient(configuration)
-	api := datadogV1.NewMetricsApi(apiClient)
-
-	// Check against ListActiveMetrics to avoid accidentally polluting if, for example,
-	// --metric-prefix is missing or incorrect.
-	resp, _, err := api.ListActiveMetrics(
-		ctx,
-		time.Now().Add(-30*24*time.Hour).Unix(),
-		*datadogV1.NewListActiveMetricsOptionalParameters(),
-	)
-	if err != nil {
-		return err
-	}
-
-	metricNames := xmaps.SetFromSlice(resp.Metrics)
-
-	for name, metadata := range defs {
-		fullName := name
-		if len(*metricPrefix) > 0 {
-			fullName = *metricPrefix + "." + name
-		}
-
-		if !metricNames.Contains(fullName) {
-			fmt.Fprintf(
-				os.Stderr,
-				"%s in defs, but has not been emitted to datadog in the last 30d, so not updating "+
-					"metadata\n",
-				fullName,
-			)
-			continue
-		}
-
-		description := metadata.Description
-		if len(description) > 400 {
-			// If longer, UpdateMetricMetadata will return 400 with no additional information.
-			// Truncate here because the top-level package is supposed to keep this from happening
-			// anyway.
-			description = description[:400]
-		}
-
-		_, r, err := api.UpdateMetricMetadata(ctx, fullName, datadogV1.MetricMetadata{
-			Unit:        datadog.PtrString(string(metadata.Unit)),
-			Description: &description,
-		})
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error when calling `MetricsApi.UpdateMetricMetadata` for %s: %v\n", name, err)
-			fmt.Fprintf(os.Stderr, "Full HTTP response: %v\n", r)
-			return err
-		}
-	}
-	return nil
+	items := syncItems(defs, *metricPrefix)
+	return syncMetadata(context.Background(), sink, items, *dryRun, os.Stdout, os.Stderr)
 }
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bradenaw/metrics"
+)
+
+// otlpSink syncs metadata to a schema registry for OpenTelemetry metrics over HTTP. Each metric is a
+// JSON document, described by otlpMetricSchema, at <url>/v1/metrics/<name> that is read with GET and
+// written with PUT. A 404 from GET means the registry doesn't have the metric yet.
+type otlpSink struct {
+	url         string
+	client      *http.Client
+	concurrency int
+}
+
+// otlpMetricSchema describes a metric using OpenTelemetry's terms.
+//
+// https://opentelemetry.io/docs/specs/otel/metrics/data-model/
+type otlpMetricSchema struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// In UCUM, see otlpUnit.
+	Unit string `json:"unit"`
+	// One of counter, gauge, or histogram.
+	Instrument string   `json:"instrument"`
+	Attributes []string `json:"attributes"`
+	// key:value pairs of the rest of the metadata, like owner:team-storage.
+	Tags []string `json:"tags"`
+}
+
+func (s *otlpSink) Diff(ctx context.Context, items []syncItem) ([]string, error) {
+	forEachParallel(items, s.concurrency, func(item *syncItem) {
+		current, err := s.get(ctx, item.fullName)
+		if err != nil {
+			item.err = fmt.Errorf("getting %s from OTLP schema registry: %w", item.fullName, err)
+			return
+		}
+		item.changes = diffFields(otlpFields(current), otlpFields(otlpSchema(item.fullName, item.md)))
+	})
+	// The registry may be shared with other services, so never remove anything from it.
+	return nil, nil
+}
+
+func (s *otlpSink) Apply(ctx context.Context, items []syncItem) error {
+	forEachParallel(items, s.concurrency, func(item *syncItem) {
+		if item.err != nil || item.skipped != "" || len(item.changes) == 0 {
+			return
+		}
+		err := s.put(ctx, otlpSchema(item.fullName, item.md))
+		if err != nil {
+			item.err = fmt.Errorf("updating %s in OTLP schema registry: %w", item.fullName, err)
+		}
+	})
+	return nil
+}
+
+func (s *otlpSink) metricURL(name string) string {
+	return strings.TrimSuffix(s.url, "/") + "/v1/metrics/" + url.PathEscape(name)
+}
+
+func (s *otlpSink) get(ctx context.Context, name string) (otlpMetricSchema, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.metricURL(name), nil)
+	if err != nil {
+		return otlpMetricSchema{}, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return otlpMetricSchema{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return otlpMetricSchema{}, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return otlpMetricSchema{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return otlpMetricSchema{}, fmt.Errorf("%s: %s", resp.Status, body)
+	}
+	var schema otlpMetricSchema
+	err = json.Unmarshal(body, &schema)
+	if err != nil {
+		return otlpMetricSchema{}, err
+	}
+	return schema, nil
+}
+
+func (s *otlpSink) put(ctx context.Context, schema otlpMetricSchema) error {
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPut,
+		s.metricURL(schema.Name),
+		bytes.NewReader(b),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, body)
+	}
+	return nil
+}
+
+func otlpSchema(fullName string, md metrics.Metadata) otlpMetricSchema {
+	var instrument string
+	switch md.MetricType {
+	case metrics.CounterType:
+		instrument = "counter"
+	case metrics.GaugeType, metrics.SetType:
+		instrument = "gauge"
+	case metrics.DistributionType:
+		instrument = "histogram"
+	}
+	unit := otlpUnit(md.Unit)
+	if md.PerUnit != "" {
+		unit += "/" + otlpUnit(md.PerUnit)
+	}
+	attributes := md.Keys
+	if attributes == nil {
+		attributes = []string{}
+	}
+	tags := metadataTags(md)
+	if tags == nil {
+		tags = []string{}
+	}
+	return otlpMetricSchema{
+		Name:        fullName,
+		Description: md.Description,
+		Unit:        unit,
+		Instrument:  instrument,
+		Attributes:  attributes,
+		Tags:        tags,
+	}
+}
+
+func otlpFields(schema otlpMetricSchema) []field {
+	return []field{
+		{name: "instrument", value: schema.Instrument},
+		{name: "unit", value: schema.Unit},
+		{name: "description", value: schema.Description},
+		{name: "attributes", value: strings.Join(schema.Attributes, ",")},
+		{name: "tags", value: strings.Join(schema.Tags, ",")},
+	}
+}
+
+// OpenTelemetry units are in UCUM (https://ucum.org/ucum). Units that don't have a UCUM equivalent
+// become annotations, which UCUM treats as dimensionless counts, the same way that OpenTelemetry's
+// semantic conventions do for things like {request}.
+var ucumUnits = map[metrics.Unit]string{
+	metrics.UnitBit:         "bit",
+	metrics.UnitByte:        "By",
+	metrics.UnitKibibyte:    "KiBy",
+	metrics.UnitMebibyte:    "MiBy",
+	metrics.UnitGibibyte:    "GiBy",
+	metrics.UnitNanosecond:  "ns",
+	metrics.UnitMicrosecond: "us",
+	metrics.UnitMillisecond: "ms",
+	metrics.UnitSecond:      "s",
+	metrics.UnitMinute:      "min",
+	metrics.UnitHour:        "h",
+	metrics.UnitDay:         "d",
+	metrics.UnitPercent:     "%",
+	metrics.UnitFraction:    "1",
+	metrics.UnitHertz:       "Hz",
+}
+
+func otlpUnit(unit metrics.Unit) string {
+	if unit == "" {
+		return ""
+	}
+	ucum, ok := ucumUnits[unit]
+	if ok {
+		return ucum
+	}
+	return "{" + string(unit) + "}"
+}
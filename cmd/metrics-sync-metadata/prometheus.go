@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bradenaw/metrics"
+)
+
+// prometheusSink writes metadata to a file in the Prometheus/OpenMetrics text format, with only the
+// HELP, TYPE, and UNIT lines for each metric and no samples. The file is rewritten to contain
+// exactly the synced metrics.
+//
+// OpenMetrics requires the name of a metric with a UNIT to end with the unit, so UNIT is left out
+// for metrics whose names don't, rather than renaming them.
+//
+// https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md#metricfamily
+type prometheusSink struct {
+	path string
+}
+
+func (s *prometheusSink) Diff(ctx context.Context, items []syncItem) ([]string, error) {
+	current, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	desiredNames := make(map[string]struct{}, len(items))
+	for i := range items {
+		name := prometheusName(items[i].fullName)
+		desiredNames[name] = struct{}{}
+		items[i].changes = diffFields(current[name], prometheusFields(name, items[i].md))
+	}
+	var removed []string
+	for name := range current {
+		if _, ok := desiredNames[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+func (s *prometheusSink) Apply(ctx context.Context, items []syncItem) error {
+	var buf bytes.Buffer
+	for _, item := range items {
+		if item.skipped != "" {
+			continue
+		}
+		name := prometheusName(item.fullName)
+		for _, f := range prometheusFields(name, item.md) {
+			if f.value == "" {
+				continue
+			}
+			fmt.Fprintf(&buf, "# %s %s %s\n", strings.ToUpper(f.name), name, f.value)
+		}
+	}
+	buf.WriteString("# EOF\n")
+	return writeFileAtomic(s.path, buf.Bytes())
+}
+
+// read parses the metadata already in the file, if there is one.
+func (s *prometheusSink) read() (map[string][]field, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	current := make(map[string][]field)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(scanner.Text(), "# ")
+		if !ok || line == "EOF" {
+			continue
+		}
+		parts := strings.SplitN(line, " ", 3)
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("%s: malformed line %q", s.path, scanner.Text())
+		}
+		current[parts[1]] = append(current[parts[1]], field{
+			name:  strings.ToLower(parts[0]),
+			value: parts[2],
+		})
+	}
+	return current, scanner.Err()
+}
+
+// prometheusFields returns the metadata lines for md, published as name, in the order they're
+// written.
+func prometheusFields(name string, md metrics.Metadata) []field {
+	var typ string
+	switch md.MetricType {
+	case metrics.CounterType:
+		typ = "counter"
+	case metrics.GaugeType, metrics.SetType:
+		typ = "gauge"
+	case metrics.DistributionType:
+		typ = "histogram"
+	}
+	unit := string(md.Unit)
+	if md.PerUnit != "" {
+		unit += "_per_" + string(md.PerUnit)
+	}
+	if !strings.HasSuffix(name, "_"+unit) {
+		unit = ""
+	}
+	help := strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(describe(md, 0))
+	return []field{
+		{name: "type", value: typ},
+		{name: "unit", value: unit},
+		{name: "help", value: help},
+	}
+}
+
+// prometheusName returns the name that Prometheus would use for a metric, since Prometheus metric
+// names can't contain dots.
+func prometheusName(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// writeFileAtomic replaces the contents of path with b, so that readers never see a partially
+// written file.
+func writeFileAtomic(path string, b []byte) error {
+	tmp := path + ".tmp"
+	err := os.WriteFile(tmp, b, 0o644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
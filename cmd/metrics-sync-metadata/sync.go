@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/bradenaw/metrics"
+)
+
+// MetadataSink is somewhere that metric metadata can be synced to, for example DataDog or a local
+// file.
+type MetadataSink interface {
+	// Diff fills in the changes field of each of items with what Apply would need to change for the
+	// sink to have the metadata in md. It can instead set skipped if the sink won't sync an item, or
+	// err if finding the changes for just that item failed. It does not modify the sink.
+	//
+	// Sinks that hold exactly the given metrics, like files, also return the names of the metrics
+	// they have that aren't in items, which Apply will remove.
+	Diff(ctx context.Context, items []syncItem) (removed []string, err error)
+	// Apply makes the changes found by Diff. Items that have err or skipped set are left alone. Apply
+	// may set err on individual items if updating just that item failed.
+	Apply(ctx context.Context, items []syncItem) error
+}
+
+// syncItem is the state of syncing a single metric's metadata.
+type syncItem struct {
+	// Including --metric-prefix.
+	fullName string
+	md       metrics.Metadata
+	// If non-empty, the reason this metric isn't being synced.
+	skipped string
+	// The differences between the current metadata in the sink and md. Empty if nothing needs to be
+	// updated.
+	changes []fieldChange
+	err     error
+}
+
+// fieldChange is a difference in one field of a metric's metadata.
+type fieldChange struct {
+	field  string
+	before string
+	after  string
+}
+
+func (c fieldChange) String() string {
+	return fmt.Sprintf("%s: %q -> %q", c.field, c.before, c.after)
+}
+
+// field is a single piece of a metric's metadata, as it's represented in a sink.
+type field struct {
+	name  string
+	value string
+}
+
+// diffFields returns the fields in desired that are different in current, in the order they appear
+// in desired. Fields missing from either are treated as empty.
+func diffFields(current []field, desired []field) []fieldChange {
+	currentValues := make(map[string]string, len(current))
+	for _, f := range current {
+		currentValues[f.name] = f.value
+	}
+	var changes []fieldChange
+	for _, f := range desired {
+		before := currentValues[f.name]
+		if before != f.value {
+			changes = append(changes, fieldChange{field: f.name, before: before, after: f.value})
+		}
+	}
+	return changes
+}
+
+type summary struct {
+	unchanged int
+	changed   int
+	removed   int
+	skipped   int
+	failed    int
+}
+
+func (s summary) String(dryRun bool) string {
+	changedVerb := "updated"
+	removedVerb := "removed"
+	if dryRun {
+		changedVerb = "would be updated (dry run)"
+		removedVerb = "would be removed (dry run)"
+	}
+	return fmt.Sprintf(
+		"%d %s, %d %s, %d unchanged, %d skipped, %d failed",
+		s.changed, changedVerb, s.removed, removedVerb, s.unchanged, s.skipped, s.failed,
+	)
+}
+
+// syncMetadata prints the changes needed to make sink match items to stdout, and then makes them
+// unless dryRun is set. Problems with individual items are printed to stderr and also returned
+// together.
+func syncMetadata(
+	ctx context.Context,
+	sink MetadataSink,
+	items []syncItem,
+	dryRun bool,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	// Find everything first so that the diff can be printed in full before changing anything.
+	removed, err := sink.Diff(ctx, items)
+	if err != nil {
+		return err
+	}
+
+	var s summary
+	anyChanges := len(removed) > 0
+	for _, item := range items {
+		if item.skipped != "" {
+			fmt.Fprintf(stderr, "%s: not syncing: %s\n", item.fullName, item.skipped)
+			s.skipped++
+			continue
+		}
+		if item.err != nil {
+			continue
+		}
+		if len(item.changes) == 0 {
+			s.unchanged++
+			continue
+		}
+		anyChanges = true
+		fmt.Fprintf(stdout, "~ %s\n", item.fullName)
+		for _, c := range item.changes {
+			fmt.Fprintf(stdout, "    %s\n", c)
+		}
+	}
+	for _, name := range removed {
+		fmt.Fprintf(stdout, "- %s\n", name)
+	}
+	s.removed = len(removed)
+
+	if !dryRun && anyChanges {
+		err := sink.Apply(ctx, items)
+		if err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, item := range items {
+		if item.err != nil {
+			fmt.Fprintln(stderr, item.err)
+			errs = append(errs, item.err)
+			s.failed++
+		} else if item.skipped == "" && len(item.changes) > 0 {
+			s.changed++
+		}
+	}
+
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, s.String(dryRun))
+	return errors.Join(errs...)
+}
+
+// syncItems returns an item for each metric in defs, sorted by name. Metrics that are also published
+// under a previous name get an item for that name too, marked as deprecated in favor of the new one.
+func syncItems(defs map[string]metrics.Metadata, metricPrefix string) []syncItem {
+	fullName := func(name string) string {
+		if len(metricPrefix) > 0 {
+			return metricPrefix + "." + name
+		}
+		return name
+	}
+	items := make([]syncItem, 0, len(defs))
+	for name, md := range defs {
+		items = append(items, syncItem{fullName: fullName(name), md: md})
+		if md.PreviousName != "" {
+			previous := md
+			previous.Name = md.PreviousName
+			previous.PreviousName = ""
+			if previous.Deprecation == nil {
+				previous.Deprecation = &metrics.Deprecation{Replacement: name}
+			}
+			items = append(items, syncItem{fullName: fullName(md.PreviousName), md: previous})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].fullName < items[j].fullName })
+	return items
+}
+
+// metadataTags returns the parts of md's metadata that most sinks don't have dedicated fields for,
+// as key:value tags.
+func metadataTags(md metrics.Metadata) []string {
+	var tags []string
+	if md.Owner != "" {
+		tags = append(tags, "owner:"+md.Owner)
+	}
+	if md.Stability != "" {
+		tags = append(tags, "stability:"+string(md.Stability))
+	}
+	if md.RunbookURL != "" {
+		tags = append(tags, "runbook:"+md.RunbookURL)
+	}
+	for _, slo := range md.SLOs {
+		tags = append(tags, "slo:"+slo)
+	}
+	if md.Deprecation != nil {
+		tags = append(tags, "deprecated:true")
+		if md.Deprecation.Replacement != "" {
+			tags = append(tags, "replacement:"+md.Deprecation.Replacement)
+		}
+		if md.Deprecation.RemovalDate != "" {
+			tags = append(tags, "removal_date:"+md.Deprecation.RemovalDate)
+		}
+	}
+	return tags
+}
+
+// describe returns md's description followed by the parts of its metadata that most sinks don't
+// have dedicated fields for, so that they're visible alongside the metric. If maxLen is positive,
+// the result is at most maxLen bytes, shortening the description first.
+func describe(md metrics.Metadata, maxLen int) string {
+	var suffix strings.Builder
+	if md.Deprecation != nil {
+		suffix.WriteString(" [deprecated")
+		if md.Deprecation.Replacement != "" {
+			fmt.Fprintf(&suffix, ", use %s instead", md.Deprecation.Replacement)
+		}
+		if md.Deprecation.RemovalDate != "" {
+			fmt.Fprintf(&suffix, ", may be removed after %s", md.Deprecation.RemovalDate)
+		}
+		suffix.WriteString("]")
+	}
+	if md.Stability != "" {
+		fmt.Fprintf(&suffix, " [stability: %s]", md.Stability)
+	}
+	if md.Owner != "" {
+		fmt.Fprintf(&suffix, " [owner: %s]", md.Owner)
+	}
+	if len(md.SLOs) > 0 {
+		fmt.Fprintf(&suffix, " [SLOs: %s]", strings.Join(md.SLOs, ", "))
+	}
+	if md.RunbookURL != "" {
+		fmt.Fprintf(&suffix, " [runbook: %s]", md.RunbookURL)
+	}
+
+	description := md.Description
+	if maxLen > 0 && len(description)+suffix.Len() > maxLen {
+		if suffix.Len() >= maxLen {
+			return truncate(description+suffix.String(), maxLen)
+		}
+		description = truncate(description, maxLen-suffix.Len())
+	}
+	return description + suffix.String()
+}
+
+// truncate returns the longest prefix of s that is at most n bytes and doesn't split a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func derefOr[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// forEachParallel calls f on each of items, using at most concurrency goroutines at once.
+func forEachParallel[T any](items []T, concurrency int, f func(*T)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range items {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			f(&items[i])
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
+	"github.com/bradenaw/metrics"
+)
+
+func TestDiffMetadata(t *testing.T) {
+	desired := desiredMetadata(metrics.Metadata{
+		MetricType:  metrics.CounterType,
+		Name:        "rpc_responses",
+		Description: "Counts responses.",
+		Unit:        metrics.UnitResponse,
+		ShortName:   "RPC responses",
+	})
+
+	changes := diffMetadata(datadogV1.MetricMetadata{}, desired)
+	expected := []fieldChange{
+		{field: "type", before: "", after: "count"},
+		{field: "unit", before: "", after: "response"},
+		{field: "short_name", before: "", after: "RPC responses"},
+		{field: "description", before: "", after: "Counts responses."},
+		{field: "statsd_interval", before: "", after: "10"},
+	}
+	if !slices.Equal(changes, expected) {
+		t.Fatalf("expected %v, got %v", expected, changes)
+	}
+
+	changes = diffMetadata(datadogV1.MetricMetadata{
+		Type:           datadog.PtrString("count"),
+		Unit:           datadog.PtrString("response"),
+		PerUnit:        datadog.PtrString("unmanaged"),
+		ShortName:      datadog.PtrString("RPC responses"),
+		Description:    datadog.PtrString("Old description."),
+		StatsdInterval: datadog.PtrInt64(60),
+	}, desired)
+	expected = []fieldChange{
+		{field: "description", before: "Old description.", after: "Counts responses."},
+		{field: "statsd_interval", before: "60", after: "10"},
+	}
+	if !slices.Equal(changes, expected) {
+		t.Fatalf("expected %v, got %v", expected, changes)
+	}
+
+	changes = diffMetadata(desired, desired)
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v", changes)
+	}
+
+	// DataDog has no unit for metrics without one.
+	unitless := desiredMetadata(metrics.Metadata{
+		MetricType:  metrics.GaugeType,
+		Name:        "queue_depth",
+		Description: "The number of queued items.",
+		Unit:        metrics.NoUnits,
+	})
+	changes = diffMetadata(datadogV1.MetricMetadata{
+		Type:        datadog.PtrString("gauge"),
+		Description: unitless.Description,
+	}, unitless)
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v", changes)
+	}
+	changes = diffMetadata(datadogV1.MetricMetadata{
+		Type:        datadog.PtrString("gauge"),
+		Unit:        datadog.PtrString("item"),
+		Description: unitless.Description,
+	}, unitless)
+	expected = []fieldChange{{field: "unit", before: "item", after: ""}}
+	if !slices.Equal(changes, expected) {
+		t.Fatalf("expected %v, got %v", expected, changes)
+	}
+}
+
+func TestDesiredMetadata(t *testing.T) {
+	desired := desiredMetadata(metrics.Metadata{
+		MetricType: metrics.GaugeType,
+		Name:       "throughput",
+		Unit:       metrics.UnitByte,
+		PerUnit:    metrics.UnitSecond,
+	})
+	if *desired.Type != "gauge" {
+		t.Fatalf("expected type gauge, got %s", *desired.Type)
+	}
+	if desired.StatsdInterval != nil {
+		t.Fatalf("expected no statsd_interval for gauges, got %d", *desired.StatsdInterval)
+	}
+	if *desired.PerUnit != "second" {
+		t.Fatalf("expected per_unit second, got %s", *desired.PerUnit)
+	}
+	if desired.ShortName != nil {
+		t.Fatalf("expected no short_name, got %s", *desired.ShortName)
+	}
+}
+
+func TestForEachParallel(t *testing.T) {
+	items := make([]int, 100)
+	var inFlight, maxInFlight atomic.Int64
+	forEachParallel(items, 4, func(item *int) {
+		n := inFlight.Add(1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		*item = 1
+		inFlight.Add(-1)
+	})
+	for i, item := range items {
+		if item != 1 {
+			t.Fatalf("item %d not visited", i)
+		}
+	}
+	if maxInFlight.Load() > 4 {
+		t.Fatalf("expected at most 4 in flight, saw %d", maxInFlight.Load())
+	}
+}
+
+// datadogStandIn is a stand-in for the parts of the DataDog V1 Metrics API that datadogSink uses.
+type datadogStandIn struct {
+	mu       sync.Mutex
+	active   []string
+	metadata map[string]datadogV1.MetricMetadata
+	updates  int
+}
+
+func (s *datadogStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/api/v1/metrics" && r.Method == http.MethodGet {
+		_ = json.NewEncoder(w).Encode(datadogV1.MetricsListResponse{Metrics: s.active})
+		return
+	}
+	name, ok := strings.CutPrefix(r.URL.Path, "/api/v1/metrics/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		_ = json.NewEncoder(w).Encode(s.metadata[name])
+	case http.MethodPut:
+		var md datadogV1.MetricMetadata
+		err := json.NewDecoder(r.Body).Decode(&md)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.metadata[name] = md
+		s.updates++
+		_ = json.NewEncoder(w).Encode(md)
+	default:
+		http.Error(w, "", http.StatusMethodNotAllowed)
+	}
+}
+
+func testItems() []syncItem {
+	return []syncItem{
+		{
+			fullName: "svc.queue_depth",
+			md: metrics.Metadata{
+				MetricType:  metrics.GaugeType,
+				Name:        "queue_depth",
+				Description: "The number of items waiting in the queue.",
+				Unit:        metrics.UnitItem,
+				Keys:        []string{},
+			},
+		},
+		{
+			fullName: "svc.rpc_responses",
+			md: metrics.Metadata{
+				MetricType:  metrics.CounterType,
+				Name:        "rpc_responses",
+				Description: "Counts responses.",
+				Unit:        metrics.UnitResponse,
+				Keys:        []string{"method", "code"},
+			},
+		},
+	}
+}
+
+// Syncs testItems() to sink twice, checking that the first makes changes and the second doesn't.
+func testSinkIdempotent(t *testing.T, sink MetadataSink) {
+	var stdout, stderr bytes.Buffer
+	err := syncMetadata(context.Background(), sink, testItems(), false, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "2 updated") {
+		t.Fatalf("expected both metrics to be updated, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	err = syncMetadata(context.Background(), sink, testItems(), false, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "0 updated, 0 removed, 2 unchanged") {
+		t.Fatalf("expected no changes the second time, got:\n%s", stdout.String())
+	}
+}
+
+func TestDatadogSink(t *testing.T) {
+	standIn := &datadogStandIn{
+		active: []string{"svc.rpc_responses"},
+		metadata: map[string]datadogV1.MetricMetadata{
+			"svc.rpc_responses": {Unit: datadog.PtrString("request")},
+		},
+	}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+	sink := newDatadogSink(server.URL, 2)
+
+	var stdout, stderr bytes.Buffer
+	err := syncMetadata(context.Background(), sink, testItems(), true, &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if standIn.updates != 0 {
+		t.Fatalf("expected no updates in a dry run, got %d", standIn.updates)
+	}
+	if !strings.Contains(stdout.String(), `unit: "request" -> "response"`) {
+		t.Fatalf("expected diff to show unit change, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "svc.queue_depth: not syncing") {
+		t.Fatalf("expected svc.queue_depth to be skipped, got:\n%s", stderr.String())
+	}
+
+	stdout.Reset()
+	err = syncMetadata(context.Background(), sink, testItems(), false, &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if standIn.updates != 1 {
+		t.Fatalf("expected 1 update, got %d", standIn.updates)
+	}
+	if *standIn.metadata["svc.rpc_responses"].Unit != "response" {
+		t.Fatalf("expected unit to be updated, got %v", standIn.metadata["svc.rpc_responses"])
+	}
+	if _, ok := standIn.metadata["svc.queue_depth"]; ok {
+		t.Fatalf("expected metric that isn't active to be left alone")
+	}
+}
+
+func TestPrometheusSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.prom")
+	err := os.WriteFile(path, []byte("# HELP svc_removed Not defined anymore.\n# EOF\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &prometheusSink{path: path}
+
+	var stdout, stderr bytes.Buffer
+	err = syncMetadata(context.Background(), sink, testItems(), false, &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "- svc_removed") {
+		t.Fatalf("expected svc_removed to be removed, got:\n%s", stdout.String())
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Neither name ends with its unit, so neither has a UNIT line.
+	expected := "# TYPE svc_queue_depth gauge\n" +
+		"# HELP svc_queue_depth The number of items waiting in the queue.\n" +
+		"# TYPE svc_rpc_responses counter\n" +
+		"# HELP svc_rpc_responses Counts responses.\n" +
+		"# EOF\n"
+	if string(b) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, b)
+	}
+
+	testSinkIdempotent(t, &prometheusSink{path: filepath.Join(t.TempDir(), "metadata.prom")})
+
+	fields := prometheusFields(
+		"svc_upload_byte_per_second",
+		metrics.Metadata{
+			MetricType: metrics.GaugeType,
+			Unit:       metrics.UnitByte,
+			PerUnit:    metrics.UnitSecond,
+		},
+	)
+	if fields[1] != (field{name: "unit", value: "byte_per_second"}) {
+		t.Fatalf("expected unit byte_per_second, got %v", fields[1])
+	}
+}
+
+func TestJSONCatalogSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	testSinkIdempotent(t, &jsonCatalogSink{path: path})
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var catalog map[string]metrics.Metadata
+	err = json.Unmarshal(b, &catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, ok := catalog["svc.rpc_responses"]
+	if !ok {
+		t.Fatalf("expected svc.rpc_responses in catalog, got %v", catalog)
+	}
+	if md.Name != "svc.rpc_responses" || !slices.Equal(md.Keys, []string{"method", "code"}) {
+		t.Fatalf("unexpected catalog entry %#v", md)
+	}
+}
+
+func TestOTLPSink(t *testing.T) {
+	var mu sync.Mutex
+	registry := make(map[string]otlpMetricSchema)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		name, ok := strings.CutPrefix(r.URL.Path, "/v1/metrics/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			schema, ok := registry[name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(schema)
+		case http.MethodPut:
+			var schema otlpMetricSchema
+			err := json.NewDecoder(r.Body).Decode(&schema)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			registry[name] = schema
+		}
+	}))
+	defer server.Close()
+
+	testSinkIdempotent(t, &otlpSink{url: server.URL, client: server.Client(), concurrency: 2})
+
+	expected := otlpMetricSchema{
+		Name:        "svc.rpc_responses",
+		Description: "Counts responses.",
+		Unit:        "{response}",
+		Instrument:  "counter",
+		Attributes:  []string{"method", "code"},
+	}
+	actual := registry["svc.rpc_responses"]
+	if actual.Name != expected.Name || actual.Description != expected.Description ||
+		actual.Unit != expected.Unit || actual.Instrument != expected.Instrument ||
+		!slices.Equal(actual.Attributes, expected.Attributes) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestOTLPUnit(t *testing.T) {
+	schema := otlpSchema("throughput", metrics.Metadata{
+		MetricType: metrics.GaugeType,
+		Unit:       metrics.UnitByte,
+		PerUnit:    metrics.UnitSecond,
+	})
+	if schema.Unit != "By/s" {
+		t.Fatalf("expected By/s, got %s", schema.Unit)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	md := metrics.Metadata{
+		Description: "Counts responses.",
+		Owner:       "team-rpc",
+		Stability:   metrics.StabilityExperimental,
+		SLOs:        []string{"rpc-availability"},
+		RunbookURL:  "https://example.com/runbooks/rpc",
+		Deprecation: &metrics.Deprecation{Replacement: "rpc_responses_v2", RemovalDate: "2030-01-31"},
+	}
+	expected := "Counts responses. [deprecated, use rpc_responses_v2 instead, may be removed after " +
+		"2030-01-31] [stability: experimental] [owner: team-rpc] [SLOs: rpc-availability] " +
+		"[runbook: https://example.com/runbooks/rpc]"
+	if actual := describe(md, 0); actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+
+	// The description is shortened first so that the rest of the metadata survives.
+	md = metrics.Metadata{Description: strings.Repeat("a", 400), Owner: "team-rpc"}
+	actual := describe(md, 400)
+	if len(actual) != 400 || !strings.HasSuffix(actual, "a [owner: team-rpc]") {
+		t.Fatalf("expected 400 bytes ending with owner, got %d: %q", len(actual), actual)
+	}
+
+	// Runes aren't split.
+	md = metrics.Metadata{Description: "a" + strings.Repeat("é", 200), Owner: "team-rpc"}
+	actual = describe(md, 400)
+	if !utf8.ValidString(actual) || !strings.HasSuffix(actual, "é [owner: team-rpc]") {
+		t.Fatalf("expected valid UTF-8 ending with owner, got %q", actual)
+	}
+
+	expectedTags := []string{"owner:team-rpc"}
+	if actual := metadataTags(md); !slices.Equal(actual, expectedTags) {
+		t.Fatalf("expected %q, got %q", expectedTags, actual)
+	}
+}
+
+func TestSyncItems(t *testing.T) {
+	items := syncItems(map[string]metrics.Metadata{
+		"rpc.server.responses": {
+			MetricType:   metrics.CounterType,
+			Name:         "rpc.server.responses",
+			PreviousName: "rpc_responses",
+		},
+	}, "myservice")
+
+	var names []string
+	for _, item := range items {
+		names = append(names, item.fullName)
+	}
+	expectedNames := []string{"myservice.rpc.server.responses", "myservice.rpc_responses"}
+	if !slices.Equal(names, expectedNames) {
+		t.Fatalf("expected %q, got %q", expectedNames, names)
+	}
+	previous := items[1].md
+	if previous.Deprecation == nil || previous.Deprecation.Replacement != "rpc.server.responses" {
+		t.Fatalf("expected previous name to be deprecated in favor of the new one, got %#v", previous)
+	}
+}
@@ -0,0 +1,20 @@
+// metrics-vet checks that metric definitions made with github.com/bradenaw/metrics follow the
+// package's conventions. See the metricdefs package for details.
+//
+// It can be run on its own:
+//
+//	metrics-vet ./...
+//
+// or through go vet:
+//
+//	go vet -vettool=$(which metrics-vet) ./...
+package main
+
+import (
+	"github.com/bradenaw/metrics/cmd/metrics-vet/metricdefs"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(metricdefs.Analyzer)
+}
@@ -0,0 +1,287 @@
+// Package metricdefs defines an analyzer that checks that metric definitions made with
+// github.com/bradenaw/metrics follow the package's conventions.
+//
+// Most of these are also checked by the metrics package itself when the definition is made, but
+// only at runtime: invalid definitions panic at init time, and definitions made after init time
+// silently produce inert defs and a metrics.bad_metric_definitions gauge. This analyzer catches the
+// same problems at build time.
+package metricdefs
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const metricsPkgPath = "github.com/bradenaw/metrics"
+
+var Analyzer = &analysis.Analyzer{
+	Name: "metricdefs",
+	Doc: "check that github.com/bradenaw/metrics definitions follow the package's conventions\n\n" +
+		"NewMDefY calls must be made from a top-level var block or func init() of a file named " +
+		"metrics.go, with a literal name that is a valid metric name, valid and non-reserved tag " +
+		"keys, a description of at most 400 characters, and assigned to a variable whose name ends " +
+		"in Def.",
+	Run: run,
+}
+
+// The below are kept in sync with registerDef and Registry.define in github.com/bradenaw/metrics.
+
+var newDefRegexp = regexp.MustCompile(`^New(Counter|Gauge|Distribution|Set)Def[0-9]*$`)
+
+// https://docs.datadoghq.com/metrics/custom_metrics/#naming-custom-metrics
+var nameRegexp = regexp.MustCompile("^[a-z][a-zA-Z0-9_.]{0,199}$")
+
+// https://docs.datadoghq.com/getting_started/tagging/
+var tagKeyRegexp = regexp.MustCompile("^(|[a-z][a-zA-Z0-9_./-]{0,199})$")
+
+// From https://docs.datadoghq.com/getting_started/tagging/
+var reservedTagKeys = map[string]struct{}{
+	"host":    {},
+	"device":  {},
+	"source":  {},
+	"service": {},
+	"env":     {},
+	"version": {},
+}
+
+const maxDescriptionLen = 400
+
+func run(pass *analysis.Pass) (any, error) {
+	for _, file := range pass.Files {
+		filename := pass.Fset.Position(file.Pos()).Filename
+		inMetricsGo := filepath.Base(filename) == "metrics.go" ||
+			strings.HasSuffix(filename, "_example_test.go")
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				if decl.Tok != token.VAR {
+					checkNoDefs(pass, decl)
+					continue
+				}
+				varNames := make(map[*ast.CallExpr]*ast.Ident)
+				for _, spec := range decl.Specs {
+					spec := spec.(*ast.ValueSpec)
+					if len(spec.Names) != len(spec.Values) {
+						continue
+					}
+					for i, value := range spec.Values {
+						if call, ok := ast.Unparen(value).(*ast.CallExpr); ok {
+							varNames[call] = spec.Names[i]
+						}
+					}
+				}
+				checkInitTime(pass, decl, varNames, inMetricsGo)
+			case *ast.FuncDecl:
+				if decl.Recv != nil || decl.Name.Name != "init" || decl.Body == nil {
+					checkNoDefs(pass, decl)
+					continue
+				}
+				varNames := make(map[*ast.CallExpr]*ast.Ident)
+				ast.Inspect(decl.Body, func(n ast.Node) bool {
+					assign, ok := n.(*ast.AssignStmt)
+					if !ok || len(assign.Lhs) != len(assign.Rhs) {
+						return true
+					}
+					for i, rhs := range assign.Rhs {
+						call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+						ident, isIdent := assign.Lhs[i].(*ast.Ident)
+						if ok && isIdent {
+							varNames[call] = ident
+						}
+					}
+					return true
+				})
+				checkInitTime(pass, decl.Body, varNames, inMetricsGo)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Checks the definitions inside of node, which runs at init time: either a top-level var block or
+// the body of func init(). varNames maps calls to the variable they're assigned to, where known.
+func checkInitTime(
+	pass *analysis.Pass,
+	node ast.Node,
+	varNames map[*ast.CallExpr]*ast.Ident,
+	inMetricsGo bool,
+) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// Function literals may run at any time.
+			checkNoDefs(pass, n.Body)
+			return false
+		case *ast.CallExpr:
+			if fnName, ok := newDefFunc(pass, n); ok {
+				checkDef(pass, n, fnName, varNames[n], inMetricsGo)
+			}
+		}
+		return true
+	})
+}
+
+// Reports any definitions inside of node, since they're not in a position that's guaranteed to run
+// at init time.
+func checkNoDefs(pass *analysis.Pass, node ast.Node) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fnName, ok := newDefFunc(pass, call)
+		if !ok {
+			return true
+		}
+		pass.Reportf(
+			call.Pos(),
+			"metrics.%s must be called in a top-level var block or func init(), otherwise it "+
+				"will produce an inert definition",
+			fnName,
+		)
+		return true
+	})
+}
+
+func checkDef(
+	pass *analysis.Pass,
+	call *ast.CallExpr,
+	fnName string,
+	varName *ast.Ident,
+	inMetricsGo bool,
+) {
+	if !inMetricsGo {
+		pass.Reportf(call.Pos(), "metrics.%s must be called from a file named metrics.go", fnName)
+	}
+
+	if varName != nil && varName.Name != "_" && !strings.HasSuffix(varName.Name, "Def") {
+		pass.Reportf(
+			varName.Pos(),
+			"metric definition variable %s should have a name ending in Def",
+			varName.Name,
+		)
+	}
+
+	if len(call.Args) < 3 {
+		// Doesn't typecheck anyway.
+		return
+	}
+
+	name, ok := stringLit(call.Args[0])
+	if !ok {
+		pass.Reportf(
+			call.Args[0].Pos(),
+			"metric name must be a string literal so that metrics are easily greppable",
+		)
+	} else if !nameRegexp.MatchString(name) {
+		pass.Reportf(
+			call.Args[0].Pos(),
+			"metric name %q doesn't match %s (see "+
+				"https://docs.datadoghq.com/metrics/custom_metrics/#naming-custom-metrics)",
+			name,
+			nameRegexp,
+		)
+	}
+
+	tv, ok := pass.TypesInfo.Types[call.Args[1]]
+	if ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		description := constant.StringVal(tv.Value)
+		if len(description) > maxDescriptionLen {
+			pass.Reportf(
+				call.Args[1].Pos(),
+				"metric descriptions cannot be more than %d characters, this one is %d",
+				maxDescriptionLen,
+				len(description),
+			)
+		}
+	}
+
+	if fnName[len(fnName)-1] < '0' || fnName[len(fnName)-1] > '9' || len(call.Args) < 4 {
+		// No tags.
+		return
+	}
+	keys, ok := call.Args[3].(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	seen := make(map[string]bool, len(keys.Elts))
+	for _, elt := range keys.Elts {
+		key, ok := stringLit(elt)
+		if !ok {
+			continue
+		}
+		if _, reserved := reservedTagKeys[key]; reserved {
+			pass.Reportf(
+				elt.Pos(),
+				"metric uses reserved tag key %q (see "+
+					"https://docs.datadoghq.com/getting_started/tagging/#overview)",
+				key,
+			)
+		} else if !tagKeyRegexp.MatchString(key) {
+			pass.Reportf(
+				elt.Pos(),
+				"metric tag key %q doesn't match %s (see "+
+					"https://docs.datadoghq.com/getting_started/tagging/#define-tags)",
+				key,
+				tagKeyRegexp,
+			)
+		}
+		if key != "" && seen[key] {
+			pass.Reportf(elt.Pos(), "duplicate tag key %q", key)
+		}
+		seen[key] = true
+	}
+}
+
+// Returns the name of the function if call is to one of the NewMDefY functions in the metrics
+// package.
+func newDefFunc(pass *analysis.Pass, call *ast.CallExpr) (string, bool) {
+	fun := ast.Unparen(call.Fun)
+	// Generic functions may be explicitly instantiated, e.g. metrics.NewCounterDef1[string](...).
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+	var ident *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		ident = f
+	case *ast.SelectorExpr:
+		ident = f.Sel
+	default:
+		return "", false
+	}
+	fn, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != metricsPkgPath {
+		return "", false
+	}
+	if !newDefRegexp.MatchString(fn.Name()) {
+		return "", false
+	}
+	return fn.Name(), true
+}
+
+// Returns the value of expr if it's a single string literal.
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := ast.Unparen(expr).(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		panic(fmt.Sprintf("unquoting string literal %s: %s", lit.Value, err))
+	}
+	return s, true
+}
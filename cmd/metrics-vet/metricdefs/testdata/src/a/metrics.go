@@ -0,0 +1,77 @@
+package a
+
+import "github.com/bradenaw/metrics"
+
+const constName = "const_name"
+
+var (
+	goodCounterDef = metrics.NewCounterDef("good_counter", "A counter.", metrics.UnitItem)
+
+	goodGaugeDef = metrics.NewGaugeDef1[string](
+		"good_gauge",
+		"A gauge.",
+		metrics.UnitItem,
+		[...]string{"key"},
+	)
+
+	goodDistributionDef = metrics.NewDistributionDef2[string, int](
+		"good.distribution",
+		"A distribution "+
+			"with a long description.",
+		metrics.UnitItem,
+		[...]string{"a", ""},
+		1,
+	)
+
+	badVarName = metrics.NewCounterDef("bad_var_name", "", metrics.UnitItem) // want `metric definition variable badVarName should have a name ending in Def`
+
+	nonLiteralNameDef = metrics.NewCounterDef(constName, "", metrics.UnitItem) // want `metric name must be a string literal`
+
+	badNameDef = metrics.NewCounterDef("Bad-Name", "", metrics.UnitItem) // want `metric name "Bad-Name" doesn't match`
+
+	reservedKeyDef = metrics.NewGaugeDef1[string](
+		"reserved_key",
+		"",
+		metrics.UnitItem,
+		[...]string{"host"}, // want `metric uses reserved tag key "host"`
+	)
+
+	badKeyDef = metrics.NewGaugeDef1[string](
+		"bad_key",
+		"",
+		metrics.UnitItem,
+		[...]string{"Key"}, // want `metric tag key "Key" doesn't match`
+	)
+
+	duplicateKeyDef = metrics.NewDistributionDef2[string, string](
+		"duplicate_key",
+		"",
+		metrics.UnitItem,
+		[...]string{"a", "a"}, // want `duplicate tag key "a"`
+		1,
+	)
+
+	longDescriptionDef = metrics.NewCounterDef(
+		"long_description",
+		"0123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789"+ // want `metric descriptions cannot be more than 400 characters, this one is 401`
+			"0123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789"+
+			"0123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789"+
+			"0123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789"+
+			"0",
+		metrics.UnitItem,
+	)
+
+	inFuncLitDef = func() metrics.CounterDef {
+		return metrics.NewCounterDef("in_func_lit", "", metrics.UnitItem) // want `must be called in a top-level var block or func init\(\)`
+	}
+)
+
+var initDef metrics.CounterDef
+
+func init() {
+	initDef = metrics.NewCounterDef("init", "", metrics.UnitItem)
+}
+
+func notInit() {
+	_ = metrics.NewCounterDef("not_init", "", metrics.UnitItem) // want `must be called in a top-level var block or func init\(\)`
+}
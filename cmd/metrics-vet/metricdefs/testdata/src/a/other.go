@@ -0,0 +1,5 @@
+package a
+
+import "github.com/bradenaw/metrics"
+
+var otherFileDef = metrics.NewCounterDef("other_file", "", metrics.UnitItem) // want `metrics.NewCounterDef must be called from a file named metrics.go`
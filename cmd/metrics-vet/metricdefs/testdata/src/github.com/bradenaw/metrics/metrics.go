@@ -0,0 +1,31 @@
+// Package metrics is a stub of github.com/bradenaw/metrics with just enough to typecheck
+// definitions.
+package metrics
+
+type Unit string
+
+const UnitItem Unit = "item"
+
+type TagValue any
+
+type CounterDef struct{}
+type GaugeDef1[V0 TagValue] struct{}
+type DistributionDef2[V0 TagValue, V1 TagValue] struct{}
+
+func NewCounterDef(name string, description string, unit Unit) CounterDef {
+	return CounterDef{}
+}
+
+func NewGaugeDef1[V0 TagValue](name string, description string, unit Unit, keys [1]string) GaugeDef1[V0] {
+	return GaugeDef1[V0]{}
+}
+
+func NewDistributionDef2[V0 TagValue, V1 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [2]string,
+	sampleRate float64,
+) DistributionDef2[V0, V1] {
+	return DistributionDef2[V0, V1]{}
+}
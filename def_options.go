@@ -0,0 +1,262 @@
+package metrics
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DefOption is an optional setting for a metric definition. DefOptions are passed as trailing
+// arguments to the NewMDefY functions, for example:
+//
+//	queueDepthDef = metrics.NewGaugeDef(
+//		"queue_depth",
+//		"The number of items waiting in the queue.",
+//		metrics.UnitItem,
+//		metrics.WithGaugeAggregation(metrics.GaugeAggregationMax),
+//	)
+//
+// Not every option applies to every metric type. Using an option on a metric type it does not apply
+// to panics at init time the same way as other invalid definitions.
+//
+// Many options, such as WithOwner and WithRunbook, only describe the metric: they appear in its
+// Metadata for tools like dashboards and catalogs, and don't change what is reported.
+type DefOption func(*defOptions)
+
+type defOptions struct {
+	gaugeAggregation GaugeAggregation
+	onlyOnChange     bool
+	sharded          bool
+	perUnit          Unit
+	shortName        string
+	owner            string
+	runbookURL       string
+	slos             []string
+	stability        Stability
+	deprecation      *Deprecation
+	previousName     string
+	registry         *Registry
+}
+
+func makeDefOptions(opts []DefOption) defOptions {
+	o := defOptions{registry: defaultRegistry}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Returns a non-empty string describing the problem if o can't be used with the given metric type.
+func (o defOptions) validate(metricType MetricType) string {
+	if o.gaugeAggregation != "" {
+		if metricType != GaugeType {
+			return fmt.Sprintf("WithGaugeAggregation used on a %s", metricType)
+		}
+		switch o.gaugeAggregation {
+		case GaugeAggregationLast, GaugeAggregationMax, GaugeAggregationMin, GaugeAggregationMean,
+			GaugeAggregationSum:
+		default:
+			return fmt.Sprintf("unknown gauge aggregation %q", o.gaugeAggregation)
+		}
+	}
+	if o.onlyOnChange && metricType != GaugeType {
+		return fmt.Sprintf("WithPublishOnlyOnChange used on a %s", metricType)
+	}
+	if o.sharded && metricType != CounterType {
+		return fmt.Sprintf("WithShardedCounter used on a %s", metricType)
+	}
+	if o.previousName != "" && !nameRegexp.MatchString(o.previousName) {
+		return fmt.Sprintf(
+			"previous name %q doesn't match required %s",
+			o.previousName,
+			nameRegexp,
+		)
+	}
+	if o.runbookURL != "" {
+		u, err := url.Parse(o.runbookURL)
+		if err != nil || !u.IsAbs() {
+			return fmt.Sprintf("runbook %q is not an absolute URL", o.runbookURL)
+		}
+	}
+	switch o.stability {
+	case "", StabilityExperimental, StabilityStable:
+	default:
+		return fmt.Sprintf("unknown stability %q", o.stability)
+	}
+	if o.deprecation != nil {
+		if o.deprecation.Replacement != "" && !nameRegexp.MatchString(o.deprecation.Replacement) {
+			return fmt.Sprintf(
+				"deprecation replacement %q is not a valid metric name",
+				o.deprecation.Replacement,
+			)
+		}
+		if o.deprecation.RemovalDate != "" {
+			_, err := time.Parse(time.DateOnly, o.deprecation.RemovalDate)
+			if err != nil {
+				return fmt.Sprintf(
+					"deprecation removal date %q is not in the form YYYY-MM-DD",
+					o.deprecation.RemovalDate,
+				)
+			}
+		}
+	}
+	return ""
+}
+
+// GaugeAggregation controls what value a Gauge reports for each flush interval.
+type GaugeAggregation string
+
+const (
+	// GaugeAggregationLast reports the last value the gauge was set to. This is the default.
+	GaugeAggregationLast GaugeAggregation = "last"
+	// GaugeAggregationMax reports the largest value the gauge had during the interval, including the
+	// value it had at the start of the interval.
+	GaugeAggregationMax GaugeAggregation = "max"
+	// GaugeAggregationMin reports the smallest value the gauge had during the interval, including
+	// the value it had at the start of the interval.
+	GaugeAggregationMin GaugeAggregation = "min"
+	// GaugeAggregationMean reports the mean of the values the gauge was set to during the interval,
+	// or the last value if it was not set during the interval.
+	GaugeAggregationMean GaugeAggregation = "mean"
+	// GaugeAggregationSum reports the sum of the values the gauge was set to during the interval, or
+	// zero if it was not set during the interval.
+	GaugeAggregationSum GaugeAggregation = "sum"
+)
+
+// WithGaugeAggregation sets how a gauge aggregates the values it is set to between flushes. By
+// default, gauges report only the last value, which can hide short spikes.
+//
+// Gauge.Add counts as setting the gauge to its new value. For example with GaugeAggregationSum,
+// Set(4), Set(10), and then Add(-8) report 4+10+2 = 16.
+//
+// Only applies to gauges.
+func WithGaugeAggregation(a GaugeAggregation) DefOption {
+	return func(o *defOptions) {
+		o.gaugeAggregation = a
+	}
+}
+
+// WithPublishOnlyOnChange makes a gauge publish only when its value changes, plus a periodic
+// heartbeat so that the backend does not consider it missing. This is useful for gauges that rarely
+// change, like configuration values. See WithGaugeHeartbeat for the heartbeat interval.
+//
+// Only applies to gauges.
+func WithPublishOnlyOnChange() DefOption {
+	return func(o *defOptions) {
+		o.onlyOnChange = true
+	}
+}
+
+// WithShardedCounter spreads a counter's value across several shards so that goroutines on
+// different CPUs adding to it at the same time don't contend on the same cache line. This makes Add
+// faster for counters that are added to from many goroutines at once, at the cost of more memory
+// per counter and more work to publish it, so it's only worth it for the hottest counters.
+//
+// Only applies to counters.
+func WithShardedCounter() DefOption {
+	return func(o *defOptions) {
+		o.sharded = true
+	}
+}
+
+// WithPerUnit sets the unit that the metric's unit is per, for example UnitSecond for a gauge of
+// bytes per second.
+func WithPerUnit(perUnit Unit) DefOption {
+	return func(o *defOptions) {
+		o.perUnit = perUnit
+	}
+}
+
+// WithShortName sets a shorter, human-readable name for the metric to be displayed in place of its
+// full name.
+func WithShortName(shortName string) DefOption {
+	return func(o *defOptions) {
+		o.shortName = shortName
+	}
+}
+
+// WithOwner sets the team or person responsible for the metric, so that it's clear who to ask about
+// it.
+func WithOwner(owner string) DefOption {
+	return func(o *defOptions) {
+		o.owner = owner
+	}
+}
+
+// WithRunbook sets the URL of a runbook that explains what to do when the metric looks wrong.
+func WithRunbook(url string) DefOption {
+	return func(o *defOptions) {
+		o.runbookURL = url
+	}
+}
+
+// WithSLO links the metric to a service level objective that it's used to measure, by the SLO's
+// name or URL. It can be passed more than once for metrics that feed multiple SLOs.
+func WithSLO(slo string) DefOption {
+	return func(o *defOptions) {
+		o.slos = append(o.slos, slo)
+	}
+}
+
+// Stability is how much a metric's consumers can rely on it staying the same.
+type Stability string
+
+const (
+	// StabilityExperimental metrics may change or be removed at any time, and so shouldn't be used
+	// for dashboards or monitors that need to keep working.
+	StabilityExperimental Stability = "experimental"
+	// StabilityStable metrics only change in breaking ways after being deprecated. See
+	// WithDeprecated.
+	StabilityStable Stability = "stable"
+)
+
+// WithStability sets how much a metric's consumers can rely on it staying the same.
+func WithStability(stability Stability) DefOption {
+	return func(o *defOptions) {
+		o.stability = stability
+	}
+}
+
+// Deprecation describes a metric that is going to be removed.
+type Deprecation struct {
+	// The name of the metric that should be used instead, if any.
+	Replacement string `json:"replacement,omitempty"`
+	// The date in the form YYYY-MM-DD after which the metric may be removed, if known.
+	RemovalDate string `json:"removalDate,omitempty"`
+}
+
+// WithDeprecated marks a metric as deprecated. replacement is the name of the metric that should be
+// used instead and removalDate is the date in the form YYYY-MM-DD after which the metric may be
+// removed, either of which may be empty if not known.
+func WithDeprecated(replacement string, removalDate string) DefOption {
+	return func(o *defOptions) {
+		o.deprecation = &Deprecation{
+			Replacement: replacement,
+			RemovalDate: removalDate,
+		}
+	}
+}
+
+// WithPreviousName makes the metric also publish everything under previousName, so that a metric
+// can be renamed without breaking the dashboards and monitors that use the old name. Once they've
+// all moved to the new name, remove this option to stop publishing the old one.
+//
+// previousName is reserved the same way as the metric's own name, so no other def can use it. It's
+// the full name the metric was published as and isn't prefixed by the namespace of the metric's
+// registry, so that a library can move its existing metrics into a namespaced registry, e.g.
+// renaming requests to rpclib.requests with WithPreviousName("requests").
+func WithPreviousName(previousName string) DefOption {
+	return func(o *defOptions) {
+		o.previousName = previousName
+	}
+}
+
+// WithRegistry defines the metric in r instead of the default registry. Libraries use this with a
+// registry from NewNamespacedRegistry so that their metric names can't collide with those of the
+// programs that use them. The definition must still be made at init time in a file named
+// metrics.go. r must be from NewNamespacedRegistry, otherwise NewMDefY panics. See Registry.
+func WithRegistry(r *Registry) DefOption {
+	return func(o *defOptions) {
+		o.registry = r
+	}
+}
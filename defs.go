@@ -1,52 +1,109 @@
 package metrics
 
 type CounterDef struct {
-	name          string
-	tags          tags
-	allComparable bool
-	ok            bool
+	name         string
+	previousName string
+	tags         tags
+	sharded      bool
+	ok           bool
 }
 
 func NewCounterDef(
 	name string,
 	description string,
 	unit Unit,
+	opts ...DefOption,
 ) CounterDef {
-	ok := registerDef(CounterType, name, description, unit, nil, nil)
+	o := makeDefOptions(opts)
+	ok := registerDef(CounterType, name, description, unit, nil, nil, o)
 	return CounterDef{
-		name:          name,
-		allComparable: true,
-		ok:            ok,
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		sharded:      o.sharded,
+		ok:           ok,
 	}
 }
 
+// RegisterCounterDef defines a counter metric with no tags in r, returning an error if the
+// definition is invalid. Unlike NewCounterDef, it can be called at any time. See Registry.
+func RegisterCounterDef(
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	opts ...DefOption,
+) (CounterDef, error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(CounterType, name, description, unit, nil, nil, o)
+	if err != nil {
+		return CounterDef{}, err
+	}
+	return CounterDef{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		sharded:      o.sharded,
+		ok:           true,
+	}, nil
+}
+
 type GaugeDef struct {
-	name          string
-	tags          tags
-	allComparable bool
-	ok            bool
+	name         string
+	previousName string
+	tags         tags
+	aggregation  GaugeAggregation
+	onlyOnChange bool
+	ok           bool
 }
 
 func NewGaugeDef(
 	name string,
 	description string,
 	unit Unit,
+	opts ...DefOption,
 ) GaugeDef {
-	ok := registerDef(GaugeType, name, description, unit, nil, nil)
+	o := makeDefOptions(opts)
+	ok := registerDef(GaugeType, name, description, unit, nil, nil, o)
 	return GaugeDef{
-		name:          name,
-		allComparable: true,
-		ok:            ok,
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		aggregation:  o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,
+		ok:           ok,
 	}
 }
 
+// RegisterGaugeDef defines a gauge metric with no tags in r, returning an error if the definition
+// is invalid. Unlike NewGaugeDef, it can be called at any time. See Registry.
+func RegisterGaugeDef(
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	opts ...DefOption,
+) (GaugeDef, error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(GaugeType, name, description, unit, nil, nil, o)
+	if err != nil {
+		return GaugeDef{}, err
+	}
+	return GaugeDef{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		aggregation:  o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,
+		ok:           true,
+	}, nil
+}
+
 type DistributionDef struct {
-	name          string
-	unit          Unit
-	tags          tags
-	sampleRate    float64
-	allComparable bool
-	ok            bool
+	name         string
+	previousName string
+	unit         Unit
+	tags         tags
+	sampleRate   float64
+	ok           bool
 }
 
 func NewDistributionDef(
@@ -54,23 +111,50 @@ func NewDistributionDef(
 	description string,
 	unit Unit,
 	sampleRate float64,
+	opts ...DefOption,
 ) DistributionDef {
-	ok := registerDef(DistributionType, name, description, unit, nil, nil)
+	o := makeDefOptions(opts)
+	ok := registerDef(DistributionType, name, description, unit, nil, nil, o)
 	return DistributionDef{
-		name:          name,
-		unit:          unit,
-		sampleRate:    sampleRate,
-		allComparable: true,
-		ok:            ok,
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		unit:         unit,
+		sampleRate:   sampleRate,
+		ok:           ok,
+	}
+}
+
+// RegisterDistributionDef defines a distribution metric with no tags in r, returning an error if
+// the definition is invalid. Unlike NewDistributionDef, it can be called at any time. See Registry.
+func RegisterDistributionDef(
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	sampleRate float64,
+	opts ...DefOption,
+) (DistributionDef, error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(DistributionType, name, description, unit, nil, nil, o)
+	if err != nil {
+		return DistributionDef{}, err
 	}
+	return DistributionDef{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		unit:         unit,
+		sampleRate:   sampleRate,
+		ok:           true,
+	}, nil
 }
 
 type SetDef struct {
-	name          string
-	tags          tags
-	sampleRate    float64
-	allComparable bool
-	ok            bool
+	name         string
+	previousName string
+	tags         tags
+	sampleRate   float64
+	ok           bool
 }
 
 func NewSetDef(
@@ -78,12 +162,38 @@ func NewSetDef(
 	description string,
 	unit Unit,
 	sampleRate float64,
+	opts ...DefOption,
 ) SetDef {
-	ok := registerDef(SetType, name, description, unit, nil, nil)
+	o := makeDefOptions(opts)
+	ok := registerDef(SetType, name, description, unit, nil, nil, o)
 	return SetDef{
-		name:          name,
-		sampleRate:    sampleRate,
-		allComparable: true,
-		ok:            ok,
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		sampleRate:   sampleRate,
+		ok:           ok,
 	}
 }
+
+// RegisterSetDef defines a set metric with no tags in r, returning an error if the definition is
+// invalid. Unlike NewSetDef, it can be called at any time. See Registry.
+func RegisterSetDef(
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	sampleRate float64,
+	opts ...DefOption,
+) (SetDef, error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(SetType, name, description, unit, nil, nil, o)
+	if err != nil {
+		return SetDef{}, err
+	}
+	return SetDef{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		sampleRate:   sampleRate,
+		ok:           true,
+	}, nil
+}
@@ -4,49 +4,305 @@ import "reflect"
 
 // generated by `go run ./gen_defs > defs_generated.go && gofmt -w defs_generated.go`
 
-const maxTags = 5
+const maxTags = 8
+
+// valueTypes1 returns the types of the tag values of a def with 1 tag(s), for Metadata.
+func valueTypes1[V0 TagValue]() []reflect.Type {
+	var zero0 V0
+
+	return []reflect.Type{
+		reflect.TypeOf(zero0),
+	}
+}
+
+// tagValues1 holds the tag values of a def with 1 tag(s), for use as a map key.
+type tagValues1[V0 TagValue] struct {
+	v0 V0
+}
+
+// valueTypes2 returns the types of the tag values of a def with 2 tag(s), for Metadata.
+func valueTypes2[V0 TagValue, V1 TagValue]() []reflect.Type {
+	var zero0 V0
+	var zero1 V1
+
+	return []reflect.Type{
+		reflect.TypeOf(zero0),
+		reflect.TypeOf(zero1),
+	}
+}
+
+// tagValues2 holds the tag values of a def with 2 tag(s), for use as a map key.
+type tagValues2[V0 TagValue, V1 TagValue] struct {
+	v0 V0
+	v1 V1
+}
+
+// valueTypes3 returns the types of the tag values of a def with 3 tag(s), for Metadata.
+func valueTypes3[V0 TagValue, V1 TagValue, V2 TagValue]() []reflect.Type {
+	var zero0 V0
+	var zero1 V1
+	var zero2 V2
+
+	return []reflect.Type{
+		reflect.TypeOf(zero0),
+		reflect.TypeOf(zero1),
+		reflect.TypeOf(zero2),
+	}
+}
+
+// tagValues3 holds the tag values of a def with 3 tag(s), for use as a map key.
+type tagValues3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
+	v0 V0
+	v1 V1
+	v2 V2
+}
+
+// valueTypes4 returns the types of the tag values of a def with 4 tag(s), for Metadata.
+func valueTypes4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue]() []reflect.Type {
+	var zero0 V0
+	var zero1 V1
+	var zero2 V2
+	var zero3 V3
+
+	return []reflect.Type{
+		reflect.TypeOf(zero0),
+		reflect.TypeOf(zero1),
+		reflect.TypeOf(zero2),
+		reflect.TypeOf(zero3),
+	}
+}
+
+// tagValues4 holds the tag values of a def with 4 tag(s), for use as a map key.
+type tagValues4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
+	v0 V0
+	v1 V1
+	v2 V2
+	v3 V3
+}
+
+// valueTypes5 returns the types of the tag values of a def with 5 tag(s), for Metadata.
+func valueTypes5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue]() []reflect.Type {
+	var zero0 V0
+	var zero1 V1
+	var zero2 V2
+	var zero3 V3
+	var zero4 V4
+
+	return []reflect.Type{
+		reflect.TypeOf(zero0),
+		reflect.TypeOf(zero1),
+		reflect.TypeOf(zero2),
+		reflect.TypeOf(zero3),
+		reflect.TypeOf(zero4),
+	}
+}
+
+// tagValues5 holds the tag values of a def with 5 tag(s), for use as a map key.
+type tagValues5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
+	v0 V0
+	v1 V1
+	v2 V2
+	v3 V3
+	v4 V4
+}
+
+// valueTypes6 returns the types of the tag values of a def with 6 tag(s), for Metadata.
+func valueTypes6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue]() []reflect.Type {
+	var zero0 V0
+	var zero1 V1
+	var zero2 V2
+	var zero3 V3
+	var zero4 V4
+	var zero5 V5
+
+	return []reflect.Type{
+		reflect.TypeOf(zero0),
+		reflect.TypeOf(zero1),
+		reflect.TypeOf(zero2),
+		reflect.TypeOf(zero3),
+		reflect.TypeOf(zero4),
+		reflect.TypeOf(zero5),
+	}
+}
+
+// tagValues6 holds the tag values of a def with 6 tag(s), for use as a map key.
+type tagValues6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue] struct {
+	v0 V0
+	v1 V1
+	v2 V2
+	v3 V3
+	v4 V4
+	v5 V5
+}
+
+// valueTypes7 returns the types of the tag values of a def with 7 tag(s), for Metadata.
+func valueTypes7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue]() []reflect.Type {
+	var zero0 V0
+	var zero1 V1
+	var zero2 V2
+	var zero3 V3
+	var zero4 V4
+	var zero5 V5
+	var zero6 V6
+
+	return []reflect.Type{
+		reflect.TypeOf(zero0),
+		reflect.TypeOf(zero1),
+		reflect.TypeOf(zero2),
+		reflect.TypeOf(zero3),
+		reflect.TypeOf(zero4),
+		reflect.TypeOf(zero5),
+		reflect.TypeOf(zero6),
+	}
+}
+
+// tagValues7 holds the tag values of a def with 7 tag(s), for use as a map key.
+type tagValues7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue] struct {
+	v0 V0
+	v1 V1
+	v2 V2
+	v3 V3
+	v4 V4
+	v5 V5
+	v6 V6
+}
+
+// valueTypes8 returns the types of the tag values of a def with 8 tag(s), for Metadata.
+func valueTypes8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue]() []reflect.Type {
+	var zero0 V0
+	var zero1 V1
+	var zero2 V2
+	var zero3 V3
+	var zero4 V4
+	var zero5 V5
+	var zero6 V6
+	var zero7 V7
+
+	return []reflect.Type{
+		reflect.TypeOf(zero0),
+		reflect.TypeOf(zero1),
+		reflect.TypeOf(zero2),
+		reflect.TypeOf(zero3),
+		reflect.TypeOf(zero4),
+		reflect.TypeOf(zero5),
+		reflect.TypeOf(zero6),
+		reflect.TypeOf(zero7),
+	}
+}
+
+// tagValues8 holds the tag values of a def with 8 tag(s), for use as a map key.
+type tagValues8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue] struct {
+	v0 V0
+	v1 V1
+	v2 V2
+	v3 V3
+	v4 V4
+	v5 V5
+	v6 V6
+	v7 V7
+}
 
 // CounterDef1 is the definition of a counter metric with 1 tag(s).
 type CounterDef1[V0 TagValue] struct {
-	name string
+	name         string
+	previousName string
 
 	prefix tags
 	keys   [1]string
 
-	allComparable bool
-	ok            bool
+	sharded bool
+	format0 func(V0) string
+
+	ok bool
 }
 
 // NewCounterDef1 defines a counter metric with 1 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
 func NewCounterDef1[V0 TagValue](
 	name string,
 	description string,
 	unit Unit,
 	keys [1]string,
 
+	opts ...DefOption,
 ) CounterDef1[V0] {
-	var zero0 V0
-
+	o := makeDefOptions(opts)
 	ok := registerDef(
 		CounterType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-		},
+		valueTypes1[V0](),
+		o,
+	)
+	return makeCounterDef1[V0](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterCounterDef1 defines a counter metric with 1 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewCounterDef1, it can be called at any time.
+// See Registry.
+func RegisterCounterDef1[V0 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [1]string,
+
+	opts ...DefOption,
+) (CounterDef1[V0], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		CounterType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes1[V0](),
+		o,
 	)
+	if err != nil {
+		return CounterDef1[V0]{}, err
+	}
+	return makeCounterDef1[V0](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeCounterDef1[V0 TagValue](
+	name string,
+
+	keys [1]string,
+
+	o defOptions,
+	ok bool,
+) CounterDef1[V0] {
 	return CounterDef1[V0]{
-		name: name,
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
 
 		keys: keys,
 
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			true,
+		sharded: o.sharded,
+		format0: tagFormatter[V0](),
+
 		ok: ok,
 	}
 }
@@ -56,62 +312,151 @@ func NewCounterDef1[V0 TagValue](
 func (d CounterDef1[V0]) Values(v0 V0) CounterDef {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
 	return CounterDef{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		tags: d.prefix.append(t),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		ok:      d.ok,
+	}
+}
+
+// BoundCounterDef1 is a CounterDef1 bound to a Metrics, see CounterDef1.Bind.
+type BoundCounterDef1[V0 TagValue] struct {
+	m     *Metrics
+	d     CounterDef1[V0]
+	cache *metricMap[tagValues1[V0], *Counter]
+}
+
+// Bind returns a BoundCounterDef1 for looking up the counters of d in m. Get() is
+// the same as m.Counter(d.Values(...)), but remembers the counter for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d CounterDef1[V0]) Bind(m *Metrics) BoundCounterDef1[V0] {
+	return BoundCounterDef1[V0]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues1[V0], *Counter]{},
+	}
+}
+
+// Get returns the counter with the given tag values.
+func (b BoundCounterDef1[V0]) Get(v0 V0) *Counter {
+	k := tagValues1[V0]{v0}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Counter(b.d.Values(v0)))
 	}
+	return x
 }
 
 // CounterDef2 is the definition of a counter metric with 2 tag(s).
 type CounterDef2[V0 TagValue, V1 TagValue] struct {
-	name string
+	name         string
+	previousName string
 
 	prefix tags
 	keys   [2]string
 
-	allComparable bool
-	ok            bool
+	sharded bool
+	format0 func(V0) string
+	format1 func(V1) string
+
+	ok bool
 }
 
 // NewCounterDef2 defines a counter metric with 2 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
 func NewCounterDef2[V0 TagValue, V1 TagValue](
 	name string,
 	description string,
 	unit Unit,
 	keys [2]string,
 
+	opts ...DefOption,
 ) CounterDef2[V0, V1] {
-	var zero0 V0
-	var zero1 V1
-
+	o := makeDefOptions(opts)
 	ok := registerDef(
 		CounterType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-		},
+		valueTypes2[V0, V1](),
+		o,
+	)
+	return makeCounterDef2[V0, V1](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterCounterDef2 defines a counter metric with 2 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewCounterDef2, it can be called at any time.
+// See Registry.
+func RegisterCounterDef2[V0 TagValue, V1 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [2]string,
+
+	opts ...DefOption,
+) (CounterDef2[V0, V1], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		CounterType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes2[V0, V1](),
+		o,
 	)
+	if err != nil {
+		return CounterDef2[V0, V1]{}, err
+	}
+	return makeCounterDef2[V0, V1](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeCounterDef2[V0 TagValue, V1 TagValue](
+	name string,
+
+	keys [2]string,
+
+	o defOptions,
+	ok bool,
+) CounterDef2[V0, V1] {
 	return CounterDef2[V0, V1]{
-		name: name,
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
 
 		keys: keys,
 
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			true,
+		sharded: o.sharded,
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+
 		ok: ok,
 	}
 }
@@ -121,17 +466,47 @@ func NewCounterDef2[V0 TagValue, V1 TagValue](
 func (d CounterDef2[V0, V1]) Values(v0 V0, v1 V1) CounterDef {
 	t := tags{n: 2}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
 
 	return CounterDef{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		tags: d.prefix.append(t),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		ok:      d.ok,
+	}
+}
+
+// BoundCounterDef2 is a CounterDef2 bound to a Metrics, see CounterDef2.Bind.
+type BoundCounterDef2[V0 TagValue, V1 TagValue] struct {
+	m     *Metrics
+	d     CounterDef2[V0, V1]
+	cache *metricMap[tagValues2[V0, V1], *Counter]
+}
+
+// Bind returns a BoundCounterDef2 for looking up the counters of d in m. Get() is
+// the same as m.Counter(d.Values(...)), but remembers the counter for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d CounterDef2[V0, V1]) Bind(m *Metrics) BoundCounterDef2[V0, V1] {
+	return BoundCounterDef2[V0, V1]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues2[V0, V1], *Counter]{},
+	}
+}
+
+// Get returns the counter with the given tag values.
+func (b BoundCounterDef2[V0, V1]) Get(v0 V0, v1 V1) *Counter {
+	k := tagValues2[V0, V1]{v0, v1}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Counter(b.d.Values(v0, v1)))
 	}
+	return x
 }
 
 // Prefix1 sets the value of the first 1 tags, returning a CounterDef1 that
@@ -139,66 +514,127 @@ func (d CounterDef2[V0, V1]) Values(v0 V0, v1 V1) CounterDef {
 func (d CounterDef2[V0, V1]) Prefix1(v0 V0) CounterDef1[V1] {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
 	return CounterDef1[V1]{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
 		keys:   *((*[1]string)(d.keys[1:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format1,
+
+		ok: d.ok,
 	}
 }
 
 // CounterDef3 is the definition of a counter metric with 3 tag(s).
 type CounterDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
-	name string
+	name         string
+	previousName string
 
 	prefix tags
 	keys   [3]string
 
-	allComparable bool
-	ok            bool
+	sharded bool
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+
+	ok bool
 }
 
 // NewCounterDef3 defines a counter metric with 3 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
 func NewCounterDef3[V0 TagValue, V1 TagValue, V2 TagValue](
 	name string,
 	description string,
 	unit Unit,
 	keys [3]string,
 
+	opts ...DefOption,
 ) CounterDef3[V0, V1, V2] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
-
+	o := makeDefOptions(opts)
 	ok := registerDef(
 		CounterType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-		},
+		valueTypes3[V0, V1, V2](),
+		o,
+	)
+	return makeCounterDef3[V0, V1, V2](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterCounterDef3 defines a counter metric with 3 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewCounterDef3, it can be called at any time.
+// See Registry.
+func RegisterCounterDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [3]string,
+
+	opts ...DefOption,
+) (CounterDef3[V0, V1, V2], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		CounterType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes3[V0, V1, V2](),
+		o,
 	)
+	if err != nil {
+		return CounterDef3[V0, V1, V2]{}, err
+	}
+	return makeCounterDef3[V0, V1, V2](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeCounterDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	name string,
+
+	keys [3]string,
+
+	o defOptions,
+	ok bool,
+) CounterDef3[V0, V1, V2] {
 	return CounterDef3[V0, V1, V2]{
-		name: name,
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
 
 		keys: keys,
 
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			true,
+		sharded: o.sharded,
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+
 		ok: ok,
 	}
 }
@@ -208,18 +644,48 @@ func NewCounterDef3[V0 TagValue, V1 TagValue, V2 TagValue](
 func (d CounterDef3[V0, V1, V2]) Values(v0 V0, v1 V1, v2 V2) CounterDef {
 	t := tags{n: 3}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
 
 	return CounterDef{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		tags: d.prefix.append(t),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		ok:      d.ok,
+	}
+}
+
+// BoundCounterDef3 is a CounterDef3 bound to a Metrics, see CounterDef3.Bind.
+type BoundCounterDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
+	m     *Metrics
+	d     CounterDef3[V0, V1, V2]
+	cache *metricMap[tagValues3[V0, V1, V2], *Counter]
+}
+
+// Bind returns a BoundCounterDef3 for looking up the counters of d in m. Get() is
+// the same as m.Counter(d.Values(...)), but remembers the counter for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d CounterDef3[V0, V1, V2]) Bind(m *Metrics) BoundCounterDef3[V0, V1, V2] {
+	return BoundCounterDef3[V0, V1, V2]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues3[V0, V1, V2], *Counter]{},
+	}
+}
+
+// Get returns the counter with the given tag values.
+func (b BoundCounterDef3[V0, V1, V2]) Get(v0 V0, v1 V1, v2 V2) *Counter {
+	k := tagValues3[V0, V1, V2]{v0, v1, v2}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Counter(b.d.Values(v0, v1, v2)))
 	}
+	return x
 }
 
 // Prefix1 sets the value of the first 1 tags, returning a CounterDef2 that
@@ -227,16 +693,20 @@ func (d CounterDef3[V0, V1, V2]) Values(v0 V0, v1 V1, v2 V2) CounterDef {
 func (d CounterDef3[V0, V1, V2]) Prefix1(v0 V0) CounterDef2[V1, V2] {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
 	return CounterDef2[V1, V2]{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
 		keys:   *((*[2]string)(d.keys[1:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format1,
+		format1: d.format2,
+
+		ok: d.ok,
 	}
 }
 
@@ -245,70 +715,130 @@ func (d CounterDef3[V0, V1, V2]) Prefix1(v0 V0) CounterDef2[V1, V2] {
 func (d CounterDef3[V0, V1, V2]) Prefix2(v0 V0, v1 V1) CounterDef1[V2] {
 	t := tags{n: 2}
 	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
 
 	return CounterDef1[V2]{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
 		keys:   *((*[1]string)(d.keys[2:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format2,
+
+		ok: d.ok,
 	}
 }
 
 // CounterDef4 is the definition of a counter metric with 4 tag(s).
 type CounterDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
-	name string
+	name         string
+	previousName string
 
 	prefix tags
 	keys   [4]string
 
-	allComparable bool
-	ok            bool
+	sharded bool
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+
+	ok bool
 }
 
 // NewCounterDef4 defines a counter metric with 4 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
 func NewCounterDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
 	name string,
 	description string,
 	unit Unit,
 	keys [4]string,
 
+	opts ...DefOption,
 ) CounterDef4[V0, V1, V2, V3] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
-	var zero3 V3
-
+	o := makeDefOptions(opts)
 	ok := registerDef(
 		CounterType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-			reflect.TypeOf(zero3),
-		},
+		valueTypes4[V0, V1, V2, V3](),
+		o,
+	)
+	return makeCounterDef4[V0, V1, V2, V3](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterCounterDef4 defines a counter metric with 4 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewCounterDef4, it can be called at any time.
+// See Registry.
+func RegisterCounterDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [4]string,
+
+	opts ...DefOption,
+) (CounterDef4[V0, V1, V2, V3], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		CounterType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes4[V0, V1, V2, V3](),
+		o,
 	)
+	if err != nil {
+		return CounterDef4[V0, V1, V2, V3]{}, err
+	}
+	return makeCounterDef4[V0, V1, V2, V3](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeCounterDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	name string,
+
+	keys [4]string,
+
+	o defOptions,
+	ok bool,
+) CounterDef4[V0, V1, V2, V3] {
 	return CounterDef4[V0, V1, V2, V3]{
-		name: name,
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
 
 		keys: keys,
 
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			reflect.TypeOf(zero3).Comparable() &&
-			true,
+		sharded: o.sharded,
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+
 		ok: ok,
 	}
 }
@@ -318,36 +848,71 @@ func NewCounterDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
 func (d CounterDef4[V0, V1, V2, V3]) Values(v0 V0, v1 V1, v2 V2, v3 V3) CounterDef {
 	t := tags{n: 4}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
 
 	return CounterDef{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		tags: d.prefix.append(t),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		ok:      d.ok,
 	}
 }
 
+// BoundCounterDef4 is a CounterDef4 bound to a Metrics, see CounterDef4.Bind.
+type BoundCounterDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
+	m     *Metrics
+	d     CounterDef4[V0, V1, V2, V3]
+	cache *metricMap[tagValues4[V0, V1, V2, V3], *Counter]
+}
+
+// Bind returns a BoundCounterDef4 for looking up the counters of d in m. Get() is
+// the same as m.Counter(d.Values(...)), but remembers the counter for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d CounterDef4[V0, V1, V2, V3]) Bind(m *Metrics) BoundCounterDef4[V0, V1, V2, V3] {
+	return BoundCounterDef4[V0, V1, V2, V3]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues4[V0, V1, V2, V3], *Counter]{},
+	}
+}
+
+// Get returns the counter with the given tag values.
+func (b BoundCounterDef4[V0, V1, V2, V3]) Get(v0 V0, v1 V1, v2 V2, v3 V3) *Counter {
+	k := tagValues4[V0, V1, V2, V3]{v0, v1, v2, v3}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Counter(b.d.Values(v0, v1, v2, v3)))
+	}
+	return x
+}
+
 // Prefix1 sets the value of the first 1 tags, returning a CounterDef3 that
 // can be used to set the rest.
 func (d CounterDef4[V0, V1, V2, V3]) Prefix1(v0 V0) CounterDef3[V1, V2, V3] {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
 	return CounterDef3[V1, V2, V3]{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
 		keys:   *((*[3]string)(d.keys[1:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+
+		ok: d.ok,
 	}
 }
 
@@ -356,17 +921,21 @@ func (d CounterDef4[V0, V1, V2, V3]) Prefix1(v0 V0) CounterDef3[V1, V2, V3] {
 func (d CounterDef4[V0, V1, V2, V3]) Prefix2(v0 V0, v1 V1) CounterDef2[V2, V3] {
 	t := tags{n: 2}
 	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
 
 	return CounterDef2[V2, V3]{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
 		keys:   *((*[2]string)(d.keys[2:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format2,
+		format1: d.format3,
+
+		ok: d.ok,
 	}
 }
 
@@ -375,74 +944,133 @@ func (d CounterDef4[V0, V1, V2, V3]) Prefix2(v0 V0, v1 V1) CounterDef2[V2, V3] {
 func (d CounterDef4[V0, V1, V2, V3]) Prefix3(v0 V0, v1 V1, v2 V2) CounterDef1[V3] {
 	t := tags{n: 3}
 	copy(t.keys[:], d.keys[:3])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
 
 	return CounterDef1[V3]{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
 		keys:   *((*[1]string)(d.keys[3:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format3,
+
+		ok: d.ok,
 	}
 }
 
 // CounterDef5 is the definition of a counter metric with 5 tag(s).
 type CounterDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
-	name string
+	name         string
+	previousName string
 
 	prefix tags
 	keys   [5]string
 
-	allComparable bool
-	ok            bool
+	sharded bool
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+
+	ok bool
 }
 
 // NewCounterDef5 defines a counter metric with 5 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
 func NewCounterDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
 	name string,
 	description string,
 	unit Unit,
 	keys [5]string,
 
+	opts ...DefOption,
 ) CounterDef5[V0, V1, V2, V3, V4] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
-	var zero3 V3
-	var zero4 V4
-
+	o := makeDefOptions(opts)
 	ok := registerDef(
 		CounterType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-			reflect.TypeOf(zero3),
-			reflect.TypeOf(zero4),
-		},
+		valueTypes5[V0, V1, V2, V3, V4](),
+		o,
+	)
+	return makeCounterDef5[V0, V1, V2, V3, V4](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterCounterDef5 defines a counter metric with 5 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewCounterDef5, it can be called at any time.
+// See Registry.
+func RegisterCounterDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [5]string,
+
+	opts ...DefOption,
+) (CounterDef5[V0, V1, V2, V3, V4], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		CounterType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes5[V0, V1, V2, V3, V4](),
+		o,
 	)
+	if err != nil {
+		return CounterDef5[V0, V1, V2, V3, V4]{}, err
+	}
+	return makeCounterDef5[V0, V1, V2, V3, V4](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeCounterDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	name string,
+
+	keys [5]string,
+
+	o defOptions,
+	ok bool,
+) CounterDef5[V0, V1, V2, V3, V4] {
 	return CounterDef5[V0, V1, V2, V3, V4]{
-		name: name,
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
 
 		keys: keys,
 
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			reflect.TypeOf(zero3).Comparable() &&
-			reflect.TypeOf(zero4).Comparable() &&
-			true,
+		sharded: o.sharded,
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+
 		ok: ok,
 	}
 }
@@ -452,37 +1080,73 @@ func NewCounterDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagVa
 func (d CounterDef5[V0, V1, V2, V3, V4]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) CounterDef {
 	t := tags{n: 5}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
-	t.values[4] = v4
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
 
 	return CounterDef{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		tags: d.prefix.append(t),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		ok:      d.ok,
 	}
 }
 
+// BoundCounterDef5 is a CounterDef5 bound to a Metrics, see CounterDef5.Bind.
+type BoundCounterDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
+	m     *Metrics
+	d     CounterDef5[V0, V1, V2, V3, V4]
+	cache *metricMap[tagValues5[V0, V1, V2, V3, V4], *Counter]
+}
+
+// Bind returns a BoundCounterDef5 for looking up the counters of d in m. Get() is
+// the same as m.Counter(d.Values(...)), but remembers the counter for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d CounterDef5[V0, V1, V2, V3, V4]) Bind(m *Metrics) BoundCounterDef5[V0, V1, V2, V3, V4] {
+	return BoundCounterDef5[V0, V1, V2, V3, V4]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues5[V0, V1, V2, V3, V4], *Counter]{},
+	}
+}
+
+// Get returns the counter with the given tag values.
+func (b BoundCounterDef5[V0, V1, V2, V3, V4]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) *Counter {
+	k := tagValues5[V0, V1, V2, V3, V4]{v0, v1, v2, v3, v4}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Counter(b.d.Values(v0, v1, v2, v3, v4)))
+	}
+	return x
+}
+
 // Prefix1 sets the value of the first 1 tags, returning a CounterDef4 that
 // can be used to set the rest.
 func (d CounterDef5[V0, V1, V2, V3, V4]) Prefix1(v0 V0) CounterDef4[V1, V2, V3, V4] {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
 	return CounterDef4[V1, V2, V3, V4]{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
 		keys:   *((*[4]string)(d.keys[1:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+
+		ok: d.ok,
 	}
 }
 
@@ -491,17 +1155,22 @@ func (d CounterDef5[V0, V1, V2, V3, V4]) Prefix1(v0 V0) CounterDef4[V1, V2, V3,
 func (d CounterDef5[V0, V1, V2, V3, V4]) Prefix2(v0 V0, v1 V1) CounterDef3[V2, V3, V4] {
 	t := tags{n: 2}
 	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
 
 	return CounterDef3[V2, V3, V4]{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
 		keys:   *((*[3]string)(d.keys[2:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+
+		ok: d.ok,
 	}
 }
 
@@ -510,18 +1179,22 @@ func (d CounterDef5[V0, V1, V2, V3, V4]) Prefix2(v0 V0, v1 V1) CounterDef3[V2, V
 func (d CounterDef5[V0, V1, V2, V3, V4]) Prefix3(v0 V0, v1 V1, v2 V2) CounterDef2[V3, V4] {
 	t := tags{n: 3}
 	copy(t.keys[:], d.keys[:3])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
 
 	return CounterDef2[V3, V4]{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
 		keys:   *((*[2]string)(d.keys[3:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format3,
+		format1: d.format4,
+
+		ok: d.ok,
 	}
 }
 
@@ -530,1638 +1203,7401 @@ func (d CounterDef5[V0, V1, V2, V3, V4]) Prefix3(v0 V0, v1 V1, v2 V2) CounterDef
 func (d CounterDef5[V0, V1, V2, V3, V4]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) CounterDef1[V4] {
 	t := tags{n: 4}
 	copy(t.keys[:], d.keys[:4])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
 
 	return CounterDef1[V4]{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
 		keys:   *((*[1]string)(d.keys[4:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format4,
+
+		ok: d.ok,
 	}
 }
 
-// GaugeDef1 is the definition of a gauge metric with 1 tag(s).
-type GaugeDef1[V0 TagValue] struct {
-	name string
+// CounterDef6 is the definition of a counter metric with 6 tag(s).
+type CounterDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue] struct {
+	name         string
+	previousName string
 
 	prefix tags
-	keys   [1]string
+	keys   [6]string
+
+	sharded bool
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
 
-	allComparable bool
-	ok            bool
+	ok bool
 }
 
-// NewGaugeDef1 defines a gauge metric with 1 tag(s).
+// NewCounterDef6 defines a counter metric with 6 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
-func NewGaugeDef1[V0 TagValue](
+//
+// opts can be used for optional settings, see DefOption.
+func NewCounterDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
 	name string,
 	description string,
 	unit Unit,
-	keys [1]string,
-
-) GaugeDef1[V0] {
-	var zero0 V0
+	keys [6]string,
 
+	opts ...DefOption,
+) CounterDef6[V0, V1, V2, V3, V4, V5] {
+	o := makeDefOptions(opts)
 	ok := registerDef(
-		GaugeType,
+		CounterType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-		},
+		valueTypes6[V0, V1, V2, V3, V4, V5](),
+		o,
 	)
-	return GaugeDef1[V0]{
-		name: name,
-
-		keys: keys,
-
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			true,
-		ok: ok,
-	}
-}
-
-// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
-// Metrics.Gauge() to produce a metric to log data to.
-func (d GaugeDef1[V0]) Values(v0 V0) GaugeDef {
-	t := tags{n: 1}
-	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-
-	return GaugeDef{
-		name: d.name,
-
-		tags: d.prefix.append(t),
-
-		allComparable: d.allComparable,
-		ok:            d.ok,
-	}
-}
-
-// GaugeDef2 is the definition of a gauge metric with 2 tag(s).
-type GaugeDef2[V0 TagValue, V1 TagValue] struct {
-	name string
+	return makeCounterDef6[V0, V1, V2, V3, V4, V5](
+		name,
 
-	prefix tags
-	keys   [2]string
+		keys,
 
-	allComparable bool
-	ok            bool
+		o,
+		ok,
+	)
 }
 
-// NewGaugeDef2 defines a gauge metric with 2 tag(s).
-//
-// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
-// (if main() has not yet started) or return an inert def that will not produce any data.
-func NewGaugeDef2[V0 TagValue, V1 TagValue](
+// RegisterCounterDef6 defines a counter metric with 6 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewCounterDef6, it can be called at any time.
+// See Registry.
+func RegisterCounterDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	r *Registry,
 	name string,
 	description string,
 	unit Unit,
-	keys [2]string,
-
-) GaugeDef2[V0, V1] {
-	var zero0 V0
-	var zero1 V1
+	keys [6]string,
 
-	ok := registerDef(
-		GaugeType,
+	opts ...DefOption,
+) (CounterDef6[V0, V1, V2, V3, V4, V5], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		CounterType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-		},
+		valueTypes6[V0, V1, V2, V3, V4, V5](),
+		o,
 	)
-	return GaugeDef2[V0, V1]{
-		name: name,
+	if err != nil {
+		return CounterDef6[V0, V1, V2, V3, V4, V5]{}, err
+	}
+	return makeCounterDef6[V0, V1, V2, V3, V4, V5](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeCounterDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	name string,
+
+	keys [6]string,
+
+	o defOptions,
+	ok bool,
+) CounterDef6[V0, V1, V2, V3, V4, V5] {
+	return CounterDef6[V0, V1, V2, V3, V4, V5]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
 
 		keys: keys,
 
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			true,
+		sharded: o.sharded,
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+
 		ok: ok,
 	}
 }
 
-// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
-// Metrics.Gauge() to produce a metric to log data to.
-func (d GaugeDef2[V0, V1]) Values(v0 V0, v1 V1) GaugeDef {
-	t := tags{n: 2}
+// Values returns a CounterDef that has all of the given tag values bound. It can be passed to
+// Metrics.Counter() to produce a metric to log data to.
+func (d CounterDef6[V0, V1, V2, V3, V4, V5]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) CounterDef {
+	t := tags{n: 6}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
 
-	return GaugeDef{
-		name: d.name,
+	return CounterDef{
+		name:         d.name,
+		previousName: d.previousName,
 
 		tags: d.prefix.append(t),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		ok:      d.ok,
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a GaugeDef1 that
+// BoundCounterDef6 is a CounterDef6 bound to a Metrics, see CounterDef6.Bind.
+type BoundCounterDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue] struct {
+	m     *Metrics
+	d     CounterDef6[V0, V1, V2, V3, V4, V5]
+	cache *metricMap[tagValues6[V0, V1, V2, V3, V4, V5], *Counter]
+}
+
+// Bind returns a BoundCounterDef6 for looking up the counters of d in m. Get() is
+// the same as m.Counter(d.Values(...)), but remembers the counter for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d CounterDef6[V0, V1, V2, V3, V4, V5]) Bind(m *Metrics) BoundCounterDef6[V0, V1, V2, V3, V4, V5] {
+	return BoundCounterDef6[V0, V1, V2, V3, V4, V5]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues6[V0, V1, V2, V3, V4, V5], *Counter]{},
+	}
+}
+
+// Get returns the counter with the given tag values.
+func (b BoundCounterDef6[V0, V1, V2, V3, V4, V5]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) *Counter {
+	k := tagValues6[V0, V1, V2, V3, V4, V5]{v0, v1, v2, v3, v4, v5}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Counter(b.d.Values(v0, v1, v2, v3, v4, v5)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a CounterDef5 that
 // can be used to set the rest.
-func (d GaugeDef2[V0, V1]) Prefix1(v0 V0) GaugeDef1[V1] {
+func (d CounterDef6[V0, V1, V2, V3, V4, V5]) Prefix1(v0 V0) CounterDef5[V1, V2, V3, V4, V5] {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
-	return GaugeDef1[V1]{
-		name: d.name,
+	return CounterDef5[V1, V2, V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
-		keys:   *((*[1]string)(d.keys[1:])),
+		keys:   *((*[5]string)(d.keys[1:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
-	}
-}
-
-// GaugeDef3 is the definition of a gauge metric with 3 tag(s).
-type GaugeDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
-	name string
-
-	prefix tags
-	keys   [3]string
+		sharded: d.sharded,
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
 
-	allComparable bool
-	ok            bool
+		ok: d.ok,
+	}
 }
 
-// NewGaugeDef3 defines a gauge metric with 3 tag(s).
-//
-// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
-// (if main() has not yet started) or return an inert def that will not produce any data.
-func NewGaugeDef3[V0 TagValue, V1 TagValue, V2 TagValue](
-	name string,
-	description string,
-	unit Unit,
-	keys [3]string,
+// Prefix2 sets the value of the first 2 tags, returning a CounterDef4 that
+// can be used to set the rest.
+func (d CounterDef6[V0, V1, V2, V3, V4, V5]) Prefix2(v0 V0, v1 V1) CounterDef4[V2, V3, V4, V5] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
 
-) GaugeDef3[V0, V1, V2] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
+	return CounterDef4[V2, V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
 
-	ok := registerDef(
-		GaugeType,
-		name,
-		description,
-		unit,
-		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-		},
-	)
-	return GaugeDef3[V0, V1, V2]{
-		name: name,
+		prefix: t,
+		keys:   *((*[4]string)(d.keys[2:])),
 
-		keys: keys,
+		sharded: d.sharded,
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
 
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			true,
-		ok: ok,
+		ok: d.ok,
 	}
 }
 
-// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
-// Metrics.Gauge() to produce a metric to log data to.
-func (d GaugeDef3[V0, V1, V2]) Values(v0 V0, v1 V1, v2 V2) GaugeDef {
+// Prefix3 sets the value of the first 3 tags, returning a CounterDef3 that
+// can be used to set the rest.
+func (d CounterDef6[V0, V1, V2, V3, V4, V5]) Prefix3(v0 V0, v1 V1, v2 V2) CounterDef3[V3, V4, V5] {
 	t := tags{n: 3}
-	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
 
-	return GaugeDef{
-		name: d.name,
+	return CounterDef3[V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
 
-		tags: d.prefix.append(t),
+		prefix: t,
+		keys:   *((*[3]string)(d.keys[3:])),
+
+		sharded: d.sharded,
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		ok: d.ok,
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a GaugeDef2 that
+// Prefix4 sets the value of the first 4 tags, returning a CounterDef2 that
 // can be used to set the rest.
-func (d GaugeDef3[V0, V1, V2]) Prefix1(v0 V0) GaugeDef2[V1, V2] {
-	t := tags{n: 1}
-	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+func (d CounterDef6[V0, V1, V2, V3, V4, V5]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) CounterDef2[V4, V5] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
 
-	return GaugeDef2[V1, V2]{
-		name: d.name,
+	return CounterDef2[V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
-		keys:   *((*[2]string)(d.keys[1:])),
+		keys:   *((*[2]string)(d.keys[4:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format4,
+		format1: d.format5,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix2 sets the value of the first 2 tags, returning a GaugeDef1 that
+// Prefix5 sets the value of the first 5 tags, returning a CounterDef1 that
 // can be used to set the rest.
-func (d GaugeDef3[V0, V1, V2]) Prefix2(v0 V0, v1 V1) GaugeDef1[V2] {
-	t := tags{n: 2}
-	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+func (d CounterDef6[V0, V1, V2, V3, V4, V5]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) CounterDef1[V5] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
 
-	return GaugeDef1[V2]{
-		name: d.name,
+	return CounterDef1[V5]{
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
-		keys:   *((*[1]string)(d.keys[2:])),
+		keys:   *((*[1]string)(d.keys[5:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format5,
+
+		ok: d.ok,
 	}
 }
 
-// GaugeDef4 is the definition of a gauge metric with 4 tag(s).
-type GaugeDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
-	name string
+// CounterDef7 is the definition of a counter metric with 7 tag(s).
+type CounterDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue] struct {
+	name         string
+	previousName string
 
 	prefix tags
-	keys   [4]string
-
-	allComparable bool
-	ok            bool
+	keys   [7]string
+
+	sharded bool
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+	format6 func(V6) string
+
+	ok bool
 }
 
-// NewGaugeDef4 defines a gauge metric with 4 tag(s).
+// NewCounterDef7 defines a counter metric with 7 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
-func NewGaugeDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+//
+// opts can be used for optional settings, see DefOption.
+func NewCounterDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
 	name string,
 	description string,
 	unit Unit,
-	keys [4]string,
-
-) GaugeDef4[V0, V1, V2, V3] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
-	var zero3 V3
+	keys [7]string,
 
+	opts ...DefOption,
+) CounterDef7[V0, V1, V2, V3, V4, V5, V6] {
+	o := makeDefOptions(opts)
 	ok := registerDef(
-		GaugeType,
+		CounterType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-			reflect.TypeOf(zero3),
-		},
+		valueTypes7[V0, V1, V2, V3, V4, V5, V6](),
+		o,
 	)
-	return GaugeDef4[V0, V1, V2, V3]{
-		name: name,
+	return makeCounterDef7[V0, V1, V2, V3, V4, V5, V6](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterCounterDef7 defines a counter metric with 7 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewCounterDef7, it can be called at any time.
+// See Registry.
+func RegisterCounterDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [7]string,
+
+	opts ...DefOption,
+) (CounterDef7[V0, V1, V2, V3, V4, V5, V6], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		CounterType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes7[V0, V1, V2, V3, V4, V5, V6](),
+		o,
+	)
+	if err != nil {
+		return CounterDef7[V0, V1, V2, V3, V4, V5, V6]{}, err
+	}
+	return makeCounterDef7[V0, V1, V2, V3, V4, V5, V6](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeCounterDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	name string,
+
+	keys [7]string,
+
+	o defOptions,
+	ok bool,
+) CounterDef7[V0, V1, V2, V3, V4, V5, V6] {
+	return CounterDef7[V0, V1, V2, V3, V4, V5, V6]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
 
 		keys: keys,
 
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			reflect.TypeOf(zero3).Comparable() &&
-			true,
+		sharded: o.sharded,
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+		format6: tagFormatter[V6](),
+
 		ok: ok,
 	}
 }
 
-// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
-// Metrics.Gauge() to produce a metric to log data to.
-func (d GaugeDef4[V0, V1, V2, V3]) Values(v0 V0, v1 V1, v2 V2, v3 V3) GaugeDef {
-	t := tags{n: 4}
+// Values returns a CounterDef that has all of the given tag values bound. It can be passed to
+// Metrics.Counter() to produce a metric to log data to.
+func (d CounterDef7[V0, V1, V2, V3, V4, V5, V6]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) CounterDef {
+	t := tags{n: 7}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
 
-	return GaugeDef{
-		name: d.name,
+	return CounterDef{
+		name:         d.name,
+		previousName: d.previousName,
 
 		tags: d.prefix.append(t),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		ok:      d.ok,
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a GaugeDef3 that
+// BoundCounterDef7 is a CounterDef7 bound to a Metrics, see CounterDef7.Bind.
+type BoundCounterDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue] struct {
+	m     *Metrics
+	d     CounterDef7[V0, V1, V2, V3, V4, V5, V6]
+	cache *metricMap[tagValues7[V0, V1, V2, V3, V4, V5, V6], *Counter]
+}
+
+// Bind returns a BoundCounterDef7 for looking up the counters of d in m. Get() is
+// the same as m.Counter(d.Values(...)), but remembers the counter for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d CounterDef7[V0, V1, V2, V3, V4, V5, V6]) Bind(m *Metrics) BoundCounterDef7[V0, V1, V2, V3, V4, V5, V6] {
+	return BoundCounterDef7[V0, V1, V2, V3, V4, V5, V6]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues7[V0, V1, V2, V3, V4, V5, V6], *Counter]{},
+	}
+}
+
+// Get returns the counter with the given tag values.
+func (b BoundCounterDef7[V0, V1, V2, V3, V4, V5, V6]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) *Counter {
+	k := tagValues7[V0, V1, V2, V3, V4, V5, V6]{v0, v1, v2, v3, v4, v5, v6}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Counter(b.d.Values(v0, v1, v2, v3, v4, v5, v6)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a CounterDef6 that
 // can be used to set the rest.
-func (d GaugeDef4[V0, V1, V2, V3]) Prefix1(v0 V0) GaugeDef3[V1, V2, V3] {
+func (d CounterDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix1(v0 V0) CounterDef6[V1, V2, V3, V4, V5, V6] {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
-	return GaugeDef3[V1, V2, V3]{
-		name: d.name,
+	return CounterDef6[V1, V2, V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
-		keys:   *((*[3]string)(d.keys[1:])),
+		keys:   *((*[6]string)(d.keys[1:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+		format5: d.format6,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix2 sets the value of the first 2 tags, returning a GaugeDef2 that
+// Prefix2 sets the value of the first 2 tags, returning a CounterDef5 that
 // can be used to set the rest.
-func (d GaugeDef4[V0, V1, V2, V3]) Prefix2(v0 V0, v1 V1) GaugeDef2[V2, V3] {
+func (d CounterDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix2(v0 V0, v1 V1) CounterDef5[V2, V3, V4, V5, V6] {
 	t := tags{n: 2}
 	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
 
-	return GaugeDef2[V2, V3]{
-		name: d.name,
+	return CounterDef5[V2, V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
-		keys:   *((*[2]string)(d.keys[2:])),
+		keys:   *((*[5]string)(d.keys[2:])),
+
+		sharded: d.sharded,
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+		format4: d.format6,
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		ok: d.ok,
 	}
 }
 
-// Prefix3 sets the value of the first 3 tags, returning a GaugeDef1 that
+// Prefix3 sets the value of the first 3 tags, returning a CounterDef4 that
 // can be used to set the rest.
-func (d GaugeDef4[V0, V1, V2, V3]) Prefix3(v0 V0, v1 V1, v2 V2) GaugeDef1[V3] {
+func (d CounterDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix3(v0 V0, v1 V1, v2 V2) CounterDef4[V3, V4, V5, V6] {
 	t := tags{n: 3}
 	copy(t.keys[:], d.keys[:3])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
 
-	return GaugeDef1[V3]{
-		name: d.name,
+	return CounterDef4[V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
-		keys:   *((*[1]string)(d.keys[3:])),
+		keys:   *((*[4]string)(d.keys[3:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+		format3: d.format6,
+
+		ok: d.ok,
 	}
 }
 
-// GaugeDef5 is the definition of a gauge metric with 5 tag(s).
-type GaugeDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
-	name string
+// Prefix4 sets the value of the first 4 tags, returning a CounterDef3 that
+// can be used to set the rest.
+func (d CounterDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) CounterDef3[V4, V5, V6] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
 
-	prefix tags
-	keys   [5]string
+	return CounterDef3[V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
 
-	allComparable bool
-	ok            bool
+		prefix: t,
+		keys:   *((*[3]string)(d.keys[4:])),
+
+		sharded: d.sharded,
+		format0: d.format4,
+		format1: d.format5,
+		format2: d.format6,
+
+		ok: d.ok,
+	}
 }
 
-// NewGaugeDef5 defines a gauge metric with 5 tag(s).
+// Prefix5 sets the value of the first 5 tags, returning a CounterDef2 that
+// can be used to set the rest.
+func (d CounterDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) CounterDef2[V5, V6] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return CounterDef2[V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[2]string)(d.keys[5:])),
+
+		sharded: d.sharded,
+		format0: d.format5,
+		format1: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix6 sets the value of the first 6 tags, returning a CounterDef1 that
+// can be used to set the rest.
+func (d CounterDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix6(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) CounterDef1[V6] {
+	t := tags{n: 6}
+	copy(t.keys[:], d.keys[:6])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+
+	return CounterDef1[V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[1]string)(d.keys[6:])),
+
+		sharded: d.sharded,
+		format0: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// CounterDef8 is the definition of a counter metric with 8 tag(s).
+type CounterDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix tags
+	keys   [8]string
+
+	sharded bool
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+	format6 func(V6) string
+	format7 func(V7) string
+
+	ok bool
+}
+
+// NewCounterDef8 defines a counter metric with 8 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
-func NewGaugeDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+//
+// opts can be used for optional settings, see DefOption.
+func NewCounterDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
 	name string,
 	description string,
 	unit Unit,
-	keys [5]string,
-
-) GaugeDef5[V0, V1, V2, V3, V4] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
-	var zero3 V3
-	var zero4 V4
+	keys [8]string,
 
+	opts ...DefOption,
+) CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	o := makeDefOptions(opts)
 	ok := registerDef(
-		GaugeType,
+		CounterType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-			reflect.TypeOf(zero3),
-			reflect.TypeOf(zero4),
-		},
+		valueTypes8[V0, V1, V2, V3, V4, V5, V6, V7](),
+		o,
 	)
-	return GaugeDef5[V0, V1, V2, V3, V4]{
-		name: name,
+	return makeCounterDef8[V0, V1, V2, V3, V4, V5, V6, V7](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterCounterDef8 defines a counter metric with 8 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewCounterDef8, it can be called at any time.
+// See Registry.
+func RegisterCounterDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [8]string,
+
+	opts ...DefOption,
+) (CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		CounterType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes8[V0, V1, V2, V3, V4, V5, V6, V7](),
+		o,
+	)
+	if err != nil {
+		return CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]{}, err
+	}
+	return makeCounterDef8[V0, V1, V2, V3, V4, V5, V6, V7](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeCounterDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	name string,
+
+	keys [8]string,
+
+	o defOptions,
+	ok bool,
+) CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	return CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
 
 		keys: keys,
 
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			reflect.TypeOf(zero3).Comparable() &&
-			reflect.TypeOf(zero4).Comparable() &&
-			true,
+		sharded: o.sharded,
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+		format6: tagFormatter[V6](),
+		format7: tagFormatter[V7](),
+
 		ok: ok,
 	}
 }
 
-// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
-// Metrics.Gauge() to produce a metric to log data to.
-func (d GaugeDef5[V0, V1, V2, V3, V4]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) GaugeDef {
-	t := tags{n: 5}
+// Values returns a CounterDef that has all of the given tag values bound. It can be passed to
+// Metrics.Counter() to produce a metric to log data to.
+func (d CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6, v7 V7) CounterDef {
+	t := tags{n: 8}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
-	t.values[4] = v4
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
+	t.values[7] = formatTag(d.format7, v7)
 
-	return GaugeDef{
-		name: d.name,
+	return CounterDef{
+		name:         d.name,
+		previousName: d.previousName,
 
 		tags: d.prefix.append(t),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		ok:      d.ok,
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a GaugeDef4 that
+// BoundCounterDef8 is a CounterDef8 bound to a Metrics, see CounterDef8.Bind.
+type BoundCounterDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue] struct {
+	m     *Metrics
+	d     CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]
+	cache *metricMap[tagValues8[V0, V1, V2, V3, V4, V5, V6, V7], *Counter]
+}
+
+// Bind returns a BoundCounterDef8 for looking up the counters of d in m. Get() is
+// the same as m.Counter(d.Values(...)), but remembers the counter for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Bind(m *Metrics) BoundCounterDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	return BoundCounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues8[V0, V1, V2, V3, V4, V5, V6, V7], *Counter]{},
+	}
+}
+
+// Get returns the counter with the given tag values.
+func (b BoundCounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6, v7 V7) *Counter {
+	k := tagValues8[V0, V1, V2, V3, V4, V5, V6, V7]{v0, v1, v2, v3, v4, v5, v6, v7}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Counter(b.d.Values(v0, v1, v2, v3, v4, v5, v6, v7)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a CounterDef7 that
 // can be used to set the rest.
-func (d GaugeDef5[V0, V1, V2, V3, V4]) Prefix1(v0 V0) GaugeDef4[V1, V2, V3, V4] {
+func (d CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix1(v0 V0) CounterDef7[V1, V2, V3, V4, V5, V6, V7] {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
-	return GaugeDef4[V1, V2, V3, V4]{
-		name: d.name,
+	return CounterDef7[V1, V2, V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
-		keys:   *((*[4]string)(d.keys[1:])),
-
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		keys:   *((*[7]string)(d.keys[1:])),
+
+		sharded: d.sharded,
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+		format5: d.format6,
+		format6: d.format7,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix2 sets the value of the first 2 tags, returning a GaugeDef3 that
+// Prefix2 sets the value of the first 2 tags, returning a CounterDef6 that
 // can be used to set the rest.
-func (d GaugeDef5[V0, V1, V2, V3, V4]) Prefix2(v0 V0, v1 V1) GaugeDef3[V2, V3, V4] {
+func (d CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix2(v0 V0, v1 V1) CounterDef6[V2, V3, V4, V5, V6, V7] {
 	t := tags{n: 2}
 	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
 
-	return GaugeDef3[V2, V3, V4]{
-		name: d.name,
+	return CounterDef6[V2, V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
-		keys:   *((*[3]string)(d.keys[2:])),
+		keys:   *((*[6]string)(d.keys[2:])),
+
+		sharded: d.sharded,
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+		format4: d.format6,
+		format5: d.format7,
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		ok: d.ok,
 	}
 }
 
-// Prefix3 sets the value of the first 3 tags, returning a GaugeDef2 that
+// Prefix3 sets the value of the first 3 tags, returning a CounterDef5 that
 // can be used to set the rest.
-func (d GaugeDef5[V0, V1, V2, V3, V4]) Prefix3(v0 V0, v1 V1, v2 V2) GaugeDef2[V3, V4] {
+func (d CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix3(v0 V0, v1 V1, v2 V2) CounterDef5[V3, V4, V5, V6, V7] {
 	t := tags{n: 3}
 	copy(t.keys[:], d.keys[:3])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
 
-	return GaugeDef2[V3, V4]{
-		name: d.name,
+	return CounterDef5[V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
-		keys:   *((*[2]string)(d.keys[3:])),
+		keys:   *((*[5]string)(d.keys[3:])),
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		sharded: d.sharded,
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+		format3: d.format6,
+		format4: d.format7,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix4 sets the value of the first 4 tags, returning a GaugeDef1 that
+// Prefix4 sets the value of the first 4 tags, returning a CounterDef4 that
 // can be used to set the rest.
-func (d GaugeDef5[V0, V1, V2, V3, V4]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) GaugeDef1[V4] {
+func (d CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) CounterDef4[V4, V5, V6, V7] {
 	t := tags{n: 4}
 	copy(t.keys[:], d.keys[:4])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
 
-	return GaugeDef1[V4]{
-		name: d.name,
+	return CounterDef4[V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
 
 		prefix: t,
-		keys:   *((*[1]string)(d.keys[4:])),
+		keys:   *((*[4]string)(d.keys[4:])),
+
+		sharded: d.sharded,
+		format0: d.format4,
+		format1: d.format5,
+		format2: d.format6,
+		format3: d.format7,
 
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		ok: d.ok,
 	}
 }
 
-// DistributionDef1 is the definition of a distribution metric with 1 tag(s).
-type DistributionDef1[V0 TagValue] struct {
-	name          string
-	unit          Unit
-	prefix        tags
-	keys          [1]string
-	sampleRate    float64
-	allComparable bool
-	ok            bool
+// Prefix5 sets the value of the first 5 tags, returning a CounterDef3 that
+// can be used to set the rest.
+func (d CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) CounterDef3[V5, V6, V7] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return CounterDef3[V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[3]string)(d.keys[5:])),
+
+		sharded: d.sharded,
+		format0: d.format5,
+		format1: d.format6,
+		format2: d.format7,
+
+		ok: d.ok,
+	}
 }
 
-// NewDistributionDef1 defines a distribution metric with 1 tag(s).
+// Prefix6 sets the value of the first 6 tags, returning a CounterDef2 that
+// can be used to set the rest.
+func (d CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix6(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) CounterDef2[V6, V7] {
+	t := tags{n: 6}
+	copy(t.keys[:], d.keys[:6])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+
+	return CounterDef2[V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[2]string)(d.keys[6:])),
+
+		sharded: d.sharded,
+		format0: d.format6,
+		format1: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix7 sets the value of the first 7 tags, returning a CounterDef1 that
+// can be used to set the rest.
+func (d CounterDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix7(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) CounterDef1[V7] {
+	t := tags{n: 7}
+	copy(t.keys[:], d.keys[:7])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
+
+	return CounterDef1[V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[1]string)(d.keys[7:])),
+
+		sharded: d.sharded,
+		format0: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// GaugeDef1 is the definition of a gauge metric with 1 tag(s).
+type GaugeDef1[V0 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix tags
+	keys   [1]string
+
+	aggregation  GaugeAggregation
+	onlyOnChange bool
+
+	format0 func(V0) string
+
+	ok bool
+}
+
+// NewGaugeDef1 defines a gauge metric with 1 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
-func NewDistributionDef1[V0 TagValue](
+//
+// opts can be used for optional settings, see DefOption.
+func NewGaugeDef1[V0 TagValue](
 	name string,
 	description string,
 	unit Unit,
 	keys [1]string,
-	sampleRate float64,
-) DistributionDef1[V0] {
-	var zero0 V0
 
+	opts ...DefOption,
+) GaugeDef1[V0] {
+	o := makeDefOptions(opts)
 	ok := registerDef(
-		DistributionType,
+		GaugeType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-		},
+		valueTypes1[V0](),
+		o,
 	)
-	return DistributionDef1[V0]{
-		name:       name,
-		unit:       unit,
-		keys:       keys,
-		sampleRate: sampleRate,
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			true,
-		ok: ok,
-	}
-}
-
-// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
-// Metrics.Distribution() to produce a metric to log data to.
-func (d DistributionDef1[V0]) Values(v0 V0) DistributionDef {
-	t := tags{n: 1}
-	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
+	return makeGaugeDef1[V0](
+		name,
 
-	return DistributionDef{
-		name:          d.name,
-		unit:          d.unit,
-		tags:          d.prefix.append(t),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
-	}
-}
+		keys,
 
-// DistributionDef2 is the definition of a distribution metric with 2 tag(s).
-type DistributionDef2[V0 TagValue, V1 TagValue] struct {
-	name          string
-	unit          Unit
-	prefix        tags
-	keys          [2]string
-	sampleRate    float64
-	allComparable bool
-	ok            bool
+		o,
+		ok,
+	)
 }
 
-// NewDistributionDef2 defines a distribution metric with 2 tag(s).
-//
-// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
-// (if main() has not yet started) or return an inert def that will not produce any data.
-func NewDistributionDef2[V0 TagValue, V1 TagValue](
+// RegisterGaugeDef1 defines a gauge metric with 1 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewGaugeDef1, it can be called at any time.
+// See Registry.
+func RegisterGaugeDef1[V0 TagValue](
+	r *Registry,
 	name string,
 	description string,
 	unit Unit,
-	keys [2]string,
-	sampleRate float64,
-) DistributionDef2[V0, V1] {
-	var zero0 V0
-	var zero1 V1
+	keys [1]string,
 
-	ok := registerDef(
-		DistributionType,
+	opts ...DefOption,
+) (GaugeDef1[V0], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		GaugeType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-		},
+		valueTypes1[V0](),
+		o,
 	)
-	return DistributionDef2[V0, V1]{
-		name:       name,
-		unit:       unit,
-		keys:       keys,
-		sampleRate: sampleRate,
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			true,
+	if err != nil {
+		return GaugeDef1[V0]{}, err
+	}
+	return makeGaugeDef1[V0](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeGaugeDef1[V0 TagValue](
+	name string,
+
+	keys [1]string,
+
+	o defOptions,
+	ok bool,
+) GaugeDef1[V0] {
+	return GaugeDef1[V0]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys: keys,
+
+		aggregation:  o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,
+
+		format0: tagFormatter[V0](),
+
 		ok: ok,
 	}
 }
 
-// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
-// Metrics.Distribution() to produce a metric to log data to.
-func (d DistributionDef2[V0, V1]) Values(v0 V0, v1 V1) DistributionDef {
-	t := tags{n: 2}
+// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
+// Metrics.Gauge() to produce a metric to log data to.
+func (d GaugeDef1[V0]) Values(v0 V0) GaugeDef {
+	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
 
-	return DistributionDef{
-		name:          d.name,
-		unit:          d.unit,
-		tags:          d.prefix.append(t),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return GaugeDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags: d.prefix.append(t),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a DistributionDef1 that
-// can be used to set the rest.
-func (d DistributionDef2[V0, V1]) Prefix1(v0 V0) DistributionDef1[V1] {
-	t := tags{n: 1}
-	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+// BoundGaugeDef1 is a GaugeDef1 bound to a Metrics, see GaugeDef1.Bind.
+type BoundGaugeDef1[V0 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef1[V0]
+	cache *metricMap[tagValues1[V0], *Gauge]
+}
 
-	return DistributionDef1[V1]{
-		name:          d.name,
-		unit:          d.unit,
-		prefix:        t,
-		keys:          *((*[1]string)(d.keys[1:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+// Bind returns a BoundGaugeDef1 for looking up the gauges of d in m. Get() is
+// the same as m.Gauge(d.Values(...)), but remembers the gauge for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d GaugeDef1[V0]) Bind(m *Metrics) BoundGaugeDef1[V0] {
+	return BoundGaugeDef1[V0]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues1[V0], *Gauge]{},
 	}
 }
 
-// DistributionDef3 is the definition of a distribution metric with 3 tag(s).
-type DistributionDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
-	name          string
-	unit          Unit
-	prefix        tags
-	keys          [3]string
-	sampleRate    float64
-	allComparable bool
-	ok            bool
+// Get returns the gauge with the given tag values.
+func (b BoundGaugeDef1[V0]) Get(v0 V0) *Gauge {
+	k := tagValues1[V0]{v0}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Gauge(b.d.Values(v0)))
+	}
+	return x
 }
 
-// NewDistributionDef3 defines a distribution metric with 3 tag(s).
+// GaugeDef2 is the definition of a gauge metric with 2 tag(s).
+type GaugeDef2[V0 TagValue, V1 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix tags
+	keys   [2]string
+
+	aggregation  GaugeAggregation
+	onlyOnChange bool
+
+	format0 func(V0) string
+	format1 func(V1) string
+
+	ok bool
+}
+
+// NewGaugeDef2 defines a gauge metric with 2 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
-func NewDistributionDef3[V0 TagValue, V1 TagValue, V2 TagValue](
-	name string,
+//
+// opts can be used for optional settings, see DefOption.
+func NewGaugeDef2[V0 TagValue, V1 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [2]string,
+
+	opts ...DefOption,
+) GaugeDef2[V0, V1] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes2[V0, V1](),
+		o,
+	)
+	return makeGaugeDef2[V0, V1](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterGaugeDef2 defines a gauge metric with 2 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewGaugeDef2, it can be called at any time.
+// See Registry.
+func RegisterGaugeDef2[V0 TagValue, V1 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [2]string,
+
+	opts ...DefOption,
+) (GaugeDef2[V0, V1], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes2[V0, V1](),
+		o,
+	)
+	if err != nil {
+		return GaugeDef2[V0, V1]{}, err
+	}
+	return makeGaugeDef2[V0, V1](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeGaugeDef2[V0 TagValue, V1 TagValue](
+	name string,
+
+	keys [2]string,
+
+	o defOptions,
+	ok bool,
+) GaugeDef2[V0, V1] {
+	return GaugeDef2[V0, V1]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys: keys,
+
+		aggregation:  o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
+// Metrics.Gauge() to produce a metric to log data to.
+func (d GaugeDef2[V0, V1]) Values(v0 V0, v1 V1) GaugeDef {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return GaugeDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags: d.prefix.append(t),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		ok: d.ok,
+	}
+}
+
+// BoundGaugeDef2 is a GaugeDef2 bound to a Metrics, see GaugeDef2.Bind.
+type BoundGaugeDef2[V0 TagValue, V1 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef2[V0, V1]
+	cache *metricMap[tagValues2[V0, V1], *Gauge]
+}
+
+// Bind returns a BoundGaugeDef2 for looking up the gauges of d in m. Get() is
+// the same as m.Gauge(d.Values(...)), but remembers the gauge for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d GaugeDef2[V0, V1]) Bind(m *Metrics) BoundGaugeDef2[V0, V1] {
+	return BoundGaugeDef2[V0, V1]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues2[V0, V1], *Gauge]{},
+	}
+}
+
+// Get returns the gauge with the given tag values.
+func (b BoundGaugeDef2[V0, V1]) Get(v0 V0, v1 V1) *Gauge {
+	k := tagValues2[V0, V1]{v0, v1}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Gauge(b.d.Values(v0, v1)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a GaugeDef1 that
+// can be used to set the rest.
+func (d GaugeDef2[V0, V1]) Prefix1(v0 V0) GaugeDef1[V1] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return GaugeDef1[V1]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[1]string)(d.keys[1:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format1,
+
+		ok: d.ok,
+	}
+}
+
+// GaugeDef3 is the definition of a gauge metric with 3 tag(s).
+type GaugeDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix tags
+	keys   [3]string
+
+	aggregation  GaugeAggregation
+	onlyOnChange bool
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+
+	ok bool
+}
+
+// NewGaugeDef3 defines a gauge metric with 3 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewGaugeDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [3]string,
+
+	opts ...DefOption,
+) GaugeDef3[V0, V1, V2] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes3[V0, V1, V2](),
+		o,
+	)
+	return makeGaugeDef3[V0, V1, V2](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterGaugeDef3 defines a gauge metric with 3 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewGaugeDef3, it can be called at any time.
+// See Registry.
+func RegisterGaugeDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [3]string,
+
+	opts ...DefOption,
+) (GaugeDef3[V0, V1, V2], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes3[V0, V1, V2](),
+		o,
+	)
+	if err != nil {
+		return GaugeDef3[V0, V1, V2]{}, err
+	}
+	return makeGaugeDef3[V0, V1, V2](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeGaugeDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	name string,
+
+	keys [3]string,
+
+	o defOptions,
+	ok bool,
+) GaugeDef3[V0, V1, V2] {
+	return GaugeDef3[V0, V1, V2]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys: keys,
+
+		aggregation:  o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
+// Metrics.Gauge() to produce a metric to log data to.
+func (d GaugeDef3[V0, V1, V2]) Values(v0 V0, v1 V1, v2 V2) GaugeDef {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return GaugeDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags: d.prefix.append(t),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		ok: d.ok,
+	}
+}
+
+// BoundGaugeDef3 is a GaugeDef3 bound to a Metrics, see GaugeDef3.Bind.
+type BoundGaugeDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef3[V0, V1, V2]
+	cache *metricMap[tagValues3[V0, V1, V2], *Gauge]
+}
+
+// Bind returns a BoundGaugeDef3 for looking up the gauges of d in m. Get() is
+// the same as m.Gauge(d.Values(...)), but remembers the gauge for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d GaugeDef3[V0, V1, V2]) Bind(m *Metrics) BoundGaugeDef3[V0, V1, V2] {
+	return BoundGaugeDef3[V0, V1, V2]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues3[V0, V1, V2], *Gauge]{},
+	}
+}
+
+// Get returns the gauge with the given tag values.
+func (b BoundGaugeDef3[V0, V1, V2]) Get(v0 V0, v1 V1, v2 V2) *Gauge {
+	k := tagValues3[V0, V1, V2]{v0, v1, v2}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Gauge(b.d.Values(v0, v1, v2)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a GaugeDef2 that
+// can be used to set the rest.
+func (d GaugeDef3[V0, V1, V2]) Prefix1(v0 V0) GaugeDef2[V1, V2] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return GaugeDef2[V1, V2]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[2]string)(d.keys[1:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format1,
+		format1: d.format2,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a GaugeDef1 that
+// can be used to set the rest.
+func (d GaugeDef3[V0, V1, V2]) Prefix2(v0 V0, v1 V1) GaugeDef1[V2] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return GaugeDef1[V2]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[1]string)(d.keys[2:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format2,
+
+		ok: d.ok,
+	}
+}
+
+// GaugeDef4 is the definition of a gauge metric with 4 tag(s).
+type GaugeDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix tags
+	keys   [4]string
+
+	aggregation  GaugeAggregation
+	onlyOnChange bool
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+
+	ok bool
+}
+
+// NewGaugeDef4 defines a gauge metric with 4 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewGaugeDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [4]string,
+
+	opts ...DefOption,
+) GaugeDef4[V0, V1, V2, V3] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes4[V0, V1, V2, V3](),
+		o,
+	)
+	return makeGaugeDef4[V0, V1, V2, V3](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterGaugeDef4 defines a gauge metric with 4 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewGaugeDef4, it can be called at any time.
+// See Registry.
+func RegisterGaugeDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [4]string,
+
+	opts ...DefOption,
+) (GaugeDef4[V0, V1, V2, V3], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes4[V0, V1, V2, V3](),
+		o,
+	)
+	if err != nil {
+		return GaugeDef4[V0, V1, V2, V3]{}, err
+	}
+	return makeGaugeDef4[V0, V1, V2, V3](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeGaugeDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	name string,
+
+	keys [4]string,
+
+	o defOptions,
+	ok bool,
+) GaugeDef4[V0, V1, V2, V3] {
+	return GaugeDef4[V0, V1, V2, V3]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys: keys,
+
+		aggregation:  o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
+// Metrics.Gauge() to produce a metric to log data to.
+func (d GaugeDef4[V0, V1, V2, V3]) Values(v0 V0, v1 V1, v2 V2, v3 V3) GaugeDef {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return GaugeDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags: d.prefix.append(t),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		ok: d.ok,
+	}
+}
+
+// BoundGaugeDef4 is a GaugeDef4 bound to a Metrics, see GaugeDef4.Bind.
+type BoundGaugeDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef4[V0, V1, V2, V3]
+	cache *metricMap[tagValues4[V0, V1, V2, V3], *Gauge]
+}
+
+// Bind returns a BoundGaugeDef4 for looking up the gauges of d in m. Get() is
+// the same as m.Gauge(d.Values(...)), but remembers the gauge for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d GaugeDef4[V0, V1, V2, V3]) Bind(m *Metrics) BoundGaugeDef4[V0, V1, V2, V3] {
+	return BoundGaugeDef4[V0, V1, V2, V3]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues4[V0, V1, V2, V3], *Gauge]{},
+	}
+}
+
+// Get returns the gauge with the given tag values.
+func (b BoundGaugeDef4[V0, V1, V2, V3]) Get(v0 V0, v1 V1, v2 V2, v3 V3) *Gauge {
+	k := tagValues4[V0, V1, V2, V3]{v0, v1, v2, v3}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Gauge(b.d.Values(v0, v1, v2, v3)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a GaugeDef3 that
+// can be used to set the rest.
+func (d GaugeDef4[V0, V1, V2, V3]) Prefix1(v0 V0) GaugeDef3[V1, V2, V3] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return GaugeDef3[V1, V2, V3]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[3]string)(d.keys[1:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a GaugeDef2 that
+// can be used to set the rest.
+func (d GaugeDef4[V0, V1, V2, V3]) Prefix2(v0 V0, v1 V1) GaugeDef2[V2, V3] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return GaugeDef2[V2, V3]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[2]string)(d.keys[2:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format2,
+		format1: d.format3,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a GaugeDef1 that
+// can be used to set the rest.
+func (d GaugeDef4[V0, V1, V2, V3]) Prefix3(v0 V0, v1 V1, v2 V2) GaugeDef1[V3] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return GaugeDef1[V3]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[1]string)(d.keys[3:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format3,
+
+		ok: d.ok,
+	}
+}
+
+// GaugeDef5 is the definition of a gauge metric with 5 tag(s).
+type GaugeDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix tags
+	keys   [5]string
+
+	aggregation  GaugeAggregation
+	onlyOnChange bool
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+
+	ok bool
+}
+
+// NewGaugeDef5 defines a gauge metric with 5 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewGaugeDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [5]string,
+
+	opts ...DefOption,
+) GaugeDef5[V0, V1, V2, V3, V4] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes5[V0, V1, V2, V3, V4](),
+		o,
+	)
+	return makeGaugeDef5[V0, V1, V2, V3, V4](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterGaugeDef5 defines a gauge metric with 5 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewGaugeDef5, it can be called at any time.
+// See Registry.
+func RegisterGaugeDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [5]string,
+
+	opts ...DefOption,
+) (GaugeDef5[V0, V1, V2, V3, V4], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes5[V0, V1, V2, V3, V4](),
+		o,
+	)
+	if err != nil {
+		return GaugeDef5[V0, V1, V2, V3, V4]{}, err
+	}
+	return makeGaugeDef5[V0, V1, V2, V3, V4](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeGaugeDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	name string,
+
+	keys [5]string,
+
+	o defOptions,
+	ok bool,
+) GaugeDef5[V0, V1, V2, V3, V4] {
+	return GaugeDef5[V0, V1, V2, V3, V4]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys: keys,
+
+		aggregation:  o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
+// Metrics.Gauge() to produce a metric to log data to.
+func (d GaugeDef5[V0, V1, V2, V3, V4]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) GaugeDef {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return GaugeDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags: d.prefix.append(t),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		ok: d.ok,
+	}
+}
+
+// BoundGaugeDef5 is a GaugeDef5 bound to a Metrics, see GaugeDef5.Bind.
+type BoundGaugeDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef5[V0, V1, V2, V3, V4]
+	cache *metricMap[tagValues5[V0, V1, V2, V3, V4], *Gauge]
+}
+
+// Bind returns a BoundGaugeDef5 for looking up the gauges of d in m. Get() is
+// the same as m.Gauge(d.Values(...)), but remembers the gauge for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d GaugeDef5[V0, V1, V2, V3, V4]) Bind(m *Metrics) BoundGaugeDef5[V0, V1, V2, V3, V4] {
+	return BoundGaugeDef5[V0, V1, V2, V3, V4]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues5[V0, V1, V2, V3, V4], *Gauge]{},
+	}
+}
+
+// Get returns the gauge with the given tag values.
+func (b BoundGaugeDef5[V0, V1, V2, V3, V4]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) *Gauge {
+	k := tagValues5[V0, V1, V2, V3, V4]{v0, v1, v2, v3, v4}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Gauge(b.d.Values(v0, v1, v2, v3, v4)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a GaugeDef4 that
+// can be used to set the rest.
+func (d GaugeDef5[V0, V1, V2, V3, V4]) Prefix1(v0 V0) GaugeDef4[V1, V2, V3, V4] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return GaugeDef4[V1, V2, V3, V4]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[4]string)(d.keys[1:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a GaugeDef3 that
+// can be used to set the rest.
+func (d GaugeDef5[V0, V1, V2, V3, V4]) Prefix2(v0 V0, v1 V1) GaugeDef3[V2, V3, V4] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return GaugeDef3[V2, V3, V4]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[3]string)(d.keys[2:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a GaugeDef2 that
+// can be used to set the rest.
+func (d GaugeDef5[V0, V1, V2, V3, V4]) Prefix3(v0 V0, v1 V1, v2 V2) GaugeDef2[V3, V4] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return GaugeDef2[V3, V4]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[2]string)(d.keys[3:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format3,
+		format1: d.format4,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix4 sets the value of the first 4 tags, returning a GaugeDef1 that
+// can be used to set the rest.
+func (d GaugeDef5[V0, V1, V2, V3, V4]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) GaugeDef1[V4] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return GaugeDef1[V4]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[1]string)(d.keys[4:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format4,
+
+		ok: d.ok,
+	}
+}
+
+// GaugeDef6 is the definition of a gauge metric with 6 tag(s).
+type GaugeDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix tags
+	keys   [6]string
+
+	aggregation  GaugeAggregation
+	onlyOnChange bool
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+
+	ok bool
+}
+
+// NewGaugeDef6 defines a gauge metric with 6 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewGaugeDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [6]string,
+
+	opts ...DefOption,
+) GaugeDef6[V0, V1, V2, V3, V4, V5] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes6[V0, V1, V2, V3, V4, V5](),
+		o,
+	)
+	return makeGaugeDef6[V0, V1, V2, V3, V4, V5](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterGaugeDef6 defines a gauge metric with 6 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewGaugeDef6, it can be called at any time.
+// See Registry.
+func RegisterGaugeDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [6]string,
+
+	opts ...DefOption,
+) (GaugeDef6[V0, V1, V2, V3, V4, V5], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes6[V0, V1, V2, V3, V4, V5](),
+		o,
+	)
+	if err != nil {
+		return GaugeDef6[V0, V1, V2, V3, V4, V5]{}, err
+	}
+	return makeGaugeDef6[V0, V1, V2, V3, V4, V5](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeGaugeDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	name string,
+
+	keys [6]string,
+
+	o defOptions,
+	ok bool,
+) GaugeDef6[V0, V1, V2, V3, V4, V5] {
+	return GaugeDef6[V0, V1, V2, V3, V4, V5]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys: keys,
+
+		aggregation:  o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
+// Metrics.Gauge() to produce a metric to log data to.
+func (d GaugeDef6[V0, V1, V2, V3, V4, V5]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) GaugeDef {
+	t := tags{n: 6}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+
+	return GaugeDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags: d.prefix.append(t),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		ok: d.ok,
+	}
+}
+
+// BoundGaugeDef6 is a GaugeDef6 bound to a Metrics, see GaugeDef6.Bind.
+type BoundGaugeDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef6[V0, V1, V2, V3, V4, V5]
+	cache *metricMap[tagValues6[V0, V1, V2, V3, V4, V5], *Gauge]
+}
+
+// Bind returns a BoundGaugeDef6 for looking up the gauges of d in m. Get() is
+// the same as m.Gauge(d.Values(...)), but remembers the gauge for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d GaugeDef6[V0, V1, V2, V3, V4, V5]) Bind(m *Metrics) BoundGaugeDef6[V0, V1, V2, V3, V4, V5] {
+	return BoundGaugeDef6[V0, V1, V2, V3, V4, V5]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues6[V0, V1, V2, V3, V4, V5], *Gauge]{},
+	}
+}
+
+// Get returns the gauge with the given tag values.
+func (b BoundGaugeDef6[V0, V1, V2, V3, V4, V5]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) *Gauge {
+	k := tagValues6[V0, V1, V2, V3, V4, V5]{v0, v1, v2, v3, v4, v5}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Gauge(b.d.Values(v0, v1, v2, v3, v4, v5)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a GaugeDef5 that
+// can be used to set the rest.
+func (d GaugeDef6[V0, V1, V2, V3, V4, V5]) Prefix1(v0 V0) GaugeDef5[V1, V2, V3, V4, V5] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return GaugeDef5[V1, V2, V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[5]string)(d.keys[1:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a GaugeDef4 that
+// can be used to set the rest.
+func (d GaugeDef6[V0, V1, V2, V3, V4, V5]) Prefix2(v0 V0, v1 V1) GaugeDef4[V2, V3, V4, V5] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return GaugeDef4[V2, V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[4]string)(d.keys[2:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a GaugeDef3 that
+// can be used to set the rest.
+func (d GaugeDef6[V0, V1, V2, V3, V4, V5]) Prefix3(v0 V0, v1 V1, v2 V2) GaugeDef3[V3, V4, V5] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return GaugeDef3[V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[3]string)(d.keys[3:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix4 sets the value of the first 4 tags, returning a GaugeDef2 that
+// can be used to set the rest.
+func (d GaugeDef6[V0, V1, V2, V3, V4, V5]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) GaugeDef2[V4, V5] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return GaugeDef2[V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[2]string)(d.keys[4:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format4,
+		format1: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix5 sets the value of the first 5 tags, returning a GaugeDef1 that
+// can be used to set the rest.
+func (d GaugeDef6[V0, V1, V2, V3, V4, V5]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) GaugeDef1[V5] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return GaugeDef1[V5]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[1]string)(d.keys[5:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// GaugeDef7 is the definition of a gauge metric with 7 tag(s).
+type GaugeDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix tags
+	keys   [7]string
+
+	aggregation  GaugeAggregation
+	onlyOnChange bool
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+	format6 func(V6) string
+
+	ok bool
+}
+
+// NewGaugeDef7 defines a gauge metric with 7 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewGaugeDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [7]string,
+
+	opts ...DefOption,
+) GaugeDef7[V0, V1, V2, V3, V4, V5, V6] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes7[V0, V1, V2, V3, V4, V5, V6](),
+		o,
+	)
+	return makeGaugeDef7[V0, V1, V2, V3, V4, V5, V6](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterGaugeDef7 defines a gauge metric with 7 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewGaugeDef7, it can be called at any time.
+// See Registry.
+func RegisterGaugeDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [7]string,
+
+	opts ...DefOption,
+) (GaugeDef7[V0, V1, V2, V3, V4, V5, V6], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes7[V0, V1, V2, V3, V4, V5, V6](),
+		o,
+	)
+	if err != nil {
+		return GaugeDef7[V0, V1, V2, V3, V4, V5, V6]{}, err
+	}
+	return makeGaugeDef7[V0, V1, V2, V3, V4, V5, V6](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeGaugeDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	name string,
+
+	keys [7]string,
+
+	o defOptions,
+	ok bool,
+) GaugeDef7[V0, V1, V2, V3, V4, V5, V6] {
+	return GaugeDef7[V0, V1, V2, V3, V4, V5, V6]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys: keys,
+
+		aggregation:  o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+		format6: tagFormatter[V6](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
+// Metrics.Gauge() to produce a metric to log data to.
+func (d GaugeDef7[V0, V1, V2, V3, V4, V5, V6]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) GaugeDef {
+	t := tags{n: 7}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
+
+	return GaugeDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags: d.prefix.append(t),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		ok: d.ok,
+	}
+}
+
+// BoundGaugeDef7 is a GaugeDef7 bound to a Metrics, see GaugeDef7.Bind.
+type BoundGaugeDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef7[V0, V1, V2, V3, V4, V5, V6]
+	cache *metricMap[tagValues7[V0, V1, V2, V3, V4, V5, V6], *Gauge]
+}
+
+// Bind returns a BoundGaugeDef7 for looking up the gauges of d in m. Get() is
+// the same as m.Gauge(d.Values(...)), but remembers the gauge for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d GaugeDef7[V0, V1, V2, V3, V4, V5, V6]) Bind(m *Metrics) BoundGaugeDef7[V0, V1, V2, V3, V4, V5, V6] {
+	return BoundGaugeDef7[V0, V1, V2, V3, V4, V5, V6]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues7[V0, V1, V2, V3, V4, V5, V6], *Gauge]{},
+	}
+}
+
+// Get returns the gauge with the given tag values.
+func (b BoundGaugeDef7[V0, V1, V2, V3, V4, V5, V6]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) *Gauge {
+	k := tagValues7[V0, V1, V2, V3, V4, V5, V6]{v0, v1, v2, v3, v4, v5, v6}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Gauge(b.d.Values(v0, v1, v2, v3, v4, v5, v6)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a GaugeDef6 that
+// can be used to set the rest.
+func (d GaugeDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix1(v0 V0) GaugeDef6[V1, V2, V3, V4, V5, V6] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return GaugeDef6[V1, V2, V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[6]string)(d.keys[1:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+		format5: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a GaugeDef5 that
+// can be used to set the rest.
+func (d GaugeDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix2(v0 V0, v1 V1) GaugeDef5[V2, V3, V4, V5, V6] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return GaugeDef5[V2, V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[5]string)(d.keys[2:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+		format4: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a GaugeDef4 that
+// can be used to set the rest.
+func (d GaugeDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix3(v0 V0, v1 V1, v2 V2) GaugeDef4[V3, V4, V5, V6] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return GaugeDef4[V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[4]string)(d.keys[3:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+		format3: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix4 sets the value of the first 4 tags, returning a GaugeDef3 that
+// can be used to set the rest.
+func (d GaugeDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) GaugeDef3[V4, V5, V6] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return GaugeDef3[V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[3]string)(d.keys[4:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format4,
+		format1: d.format5,
+		format2: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix5 sets the value of the first 5 tags, returning a GaugeDef2 that
+// can be used to set the rest.
+func (d GaugeDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) GaugeDef2[V5, V6] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return GaugeDef2[V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[2]string)(d.keys[5:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format5,
+		format1: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix6 sets the value of the first 6 tags, returning a GaugeDef1 that
+// can be used to set the rest.
+func (d GaugeDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix6(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) GaugeDef1[V6] {
+	t := tags{n: 6}
+	copy(t.keys[:], d.keys[:6])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+
+	return GaugeDef1[V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[1]string)(d.keys[6:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// GaugeDef8 is the definition of a gauge metric with 8 tag(s).
+type GaugeDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix tags
+	keys   [8]string
+
+	aggregation  GaugeAggregation
+	onlyOnChange bool
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+	format6 func(V6) string
+	format7 func(V7) string
+
+	ok bool
+}
+
+// NewGaugeDef8 defines a gauge metric with 8 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewGaugeDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [8]string,
+
+	opts ...DefOption,
+) GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes8[V0, V1, V2, V3, V4, V5, V6, V7](),
+		o,
+	)
+	return makeGaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7](
+		name,
+
+		keys,
+
+		o,
+		ok,
+	)
+}
+
+// RegisterGaugeDef8 defines a gauge metric with 8 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewGaugeDef8, it can be called at any time.
+// See Registry.
+func RegisterGaugeDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [8]string,
+
+	opts ...DefOption,
+) (GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		GaugeType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes8[V0, V1, V2, V3, V4, V5, V6, V7](),
+		o,
+	)
+	if err != nil {
+		return GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]{}, err
+	}
+	return makeGaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7](
+		name,
+
+		keys,
+
+		o,
+		true,
+	), nil
+}
+
+func makeGaugeDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	name string,
+
+	keys [8]string,
+
+	o defOptions,
+	ok bool,
+) GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	return GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys: keys,
+
+		aggregation:  o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+		format6: tagFormatter[V6](),
+		format7: tagFormatter[V7](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a GaugeDef that has all of the given tag values bound. It can be passed to
+// Metrics.Gauge() to produce a metric to log data to.
+func (d GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6, v7 V7) GaugeDef {
+	t := tags{n: 8}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
+	t.values[7] = formatTag(d.format7, v7)
+
+	return GaugeDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags: d.prefix.append(t),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		ok: d.ok,
+	}
+}
+
+// BoundGaugeDef8 is a GaugeDef8 bound to a Metrics, see GaugeDef8.Bind.
+type BoundGaugeDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]
+	cache *metricMap[tagValues8[V0, V1, V2, V3, V4, V5, V6, V7], *Gauge]
+}
+
+// Bind returns a BoundGaugeDef8 for looking up the gauges of d in m. Get() is
+// the same as m.Gauge(d.Values(...)), but remembers the gauge for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Bind(m *Metrics) BoundGaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	return BoundGaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues8[V0, V1, V2, V3, V4, V5, V6, V7], *Gauge]{},
+	}
+}
+
+// Get returns the gauge with the given tag values.
+func (b BoundGaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6, v7 V7) *Gauge {
+	k := tagValues8[V0, V1, V2, V3, V4, V5, V6, V7]{v0, v1, v2, v3, v4, v5, v6, v7}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Gauge(b.d.Values(v0, v1, v2, v3, v4, v5, v6, v7)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a GaugeDef7 that
+// can be used to set the rest.
+func (d GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix1(v0 V0) GaugeDef7[V1, V2, V3, V4, V5, V6, V7] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return GaugeDef7[V1, V2, V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[7]string)(d.keys[1:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+		format5: d.format6,
+		format6: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a GaugeDef6 that
+// can be used to set the rest.
+func (d GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix2(v0 V0, v1 V1) GaugeDef6[V2, V3, V4, V5, V6, V7] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return GaugeDef6[V2, V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[6]string)(d.keys[2:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+		format4: d.format6,
+		format5: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a GaugeDef5 that
+// can be used to set the rest.
+func (d GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix3(v0 V0, v1 V1, v2 V2) GaugeDef5[V3, V4, V5, V6, V7] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return GaugeDef5[V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[5]string)(d.keys[3:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+		format3: d.format6,
+		format4: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix4 sets the value of the first 4 tags, returning a GaugeDef4 that
+// can be used to set the rest.
+func (d GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) GaugeDef4[V4, V5, V6, V7] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return GaugeDef4[V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[4]string)(d.keys[4:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format4,
+		format1: d.format5,
+		format2: d.format6,
+		format3: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix5 sets the value of the first 5 tags, returning a GaugeDef3 that
+// can be used to set the rest.
+func (d GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) GaugeDef3[V5, V6, V7] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return GaugeDef3[V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[3]string)(d.keys[5:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format5,
+		format1: d.format6,
+		format2: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix6 sets the value of the first 6 tags, returning a GaugeDef2 that
+// can be used to set the rest.
+func (d GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix6(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) GaugeDef2[V6, V7] {
+	t := tags{n: 6}
+	copy(t.keys[:], d.keys[:6])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+
+	return GaugeDef2[V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[2]string)(d.keys[6:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format6,
+		format1: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix7 sets the value of the first 7 tags, returning a GaugeDef1 that
+// can be used to set the rest.
+func (d GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix7(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) GaugeDef1[V7] {
+	t := tags{n: 7}
+	copy(t.keys[:], d.keys[:7])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
+
+	return GaugeDef1[V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix: t,
+		keys:   *((*[1]string)(d.keys[7:])),
+
+		aggregation:  d.aggregation,
+		onlyOnChange: d.onlyOnChange,
+
+		format0: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// DistributionDef1 is the definition of a distribution metric with 1 tag(s).
+type DistributionDef1[V0 TagValue] struct {
+	name         string
+	previousName string
+	unit         Unit
+	prefix       tags
+	keys         [1]string
+	sampleRate   float64
+
+	format0 func(V0) string
+
+	ok bool
+}
+
+// NewDistributionDef1 defines a distribution metric with 1 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewDistributionDef1[V0 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [1]string,
+	sampleRate float64,
+	opts ...DefOption,
+) DistributionDef1[V0] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes1[V0](),
+		o,
+	)
+	return makeDistributionDef1[V0](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterDistributionDef1 defines a distribution metric with 1 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewDistributionDef1, it can be called at any time.
+// See Registry.
+func RegisterDistributionDef1[V0 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [1]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (DistributionDef1[V0], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes1[V0](),
+		o,
+	)
+	if err != nil {
+		return DistributionDef1[V0]{}, err
+	}
+	return makeDistributionDef1[V0](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeDistributionDef1[V0 TagValue](
+	name string,
+	unit Unit,
+	keys [1]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) DistributionDef1[V0] {
+	return DistributionDef1[V0]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		unit:         unit,
+		keys:         keys,
+		sampleRate:   sampleRate,
+
+		format0: tagFormatter[V0](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
+// Metrics.Distribution() to produce a metric to log data to.
+func (d DistributionDef1[V0]) Values(v0 V0) DistributionDef {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return DistributionDef{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		tags:         d.prefix.append(t),
+		sampleRate:   d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundDistributionDef1 is a DistributionDef1 bound to a Metrics, see DistributionDef1.Bind.
+type BoundDistributionDef1[V0 TagValue] struct {
+	m     *Metrics
+	d     DistributionDef1[V0]
+	cache *metricMap[tagValues1[V0], *Distribution]
+}
+
+// Bind returns a BoundDistributionDef1 for looking up the distributions of d in m. Get() is
+// the same as m.Distribution(d.Values(...)), but remembers the distribution for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d DistributionDef1[V0]) Bind(m *Metrics) BoundDistributionDef1[V0] {
+	return BoundDistributionDef1[V0]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues1[V0], *Distribution]{},
+	}
+}
+
+// Get returns the distribution with the given tag values.
+func (b BoundDistributionDef1[V0]) Get(v0 V0) *Distribution {
+	k := tagValues1[V0]{v0}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Distribution(b.d.Values(v0)))
+	}
+	return x
+}
+
+// DistributionDef2 is the definition of a distribution metric with 2 tag(s).
+type DistributionDef2[V0 TagValue, V1 TagValue] struct {
+	name         string
+	previousName string
+	unit         Unit
+	prefix       tags
+	keys         [2]string
+	sampleRate   float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+
+	ok bool
+}
+
+// NewDistributionDef2 defines a distribution metric with 2 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewDistributionDef2[V0 TagValue, V1 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [2]string,
+	sampleRate float64,
+	opts ...DefOption,
+) DistributionDef2[V0, V1] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes2[V0, V1](),
+		o,
+	)
+	return makeDistributionDef2[V0, V1](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterDistributionDef2 defines a distribution metric with 2 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewDistributionDef2, it can be called at any time.
+// See Registry.
+func RegisterDistributionDef2[V0 TagValue, V1 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [2]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (DistributionDef2[V0, V1], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes2[V0, V1](),
+		o,
+	)
+	if err != nil {
+		return DistributionDef2[V0, V1]{}, err
+	}
+	return makeDistributionDef2[V0, V1](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeDistributionDef2[V0 TagValue, V1 TagValue](
+	name string,
+	unit Unit,
+	keys [2]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) DistributionDef2[V0, V1] {
+	return DistributionDef2[V0, V1]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		unit:         unit,
+		keys:         keys,
+		sampleRate:   sampleRate,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
+// Metrics.Distribution() to produce a metric to log data to.
+func (d DistributionDef2[V0, V1]) Values(v0 V0, v1 V1) DistributionDef {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return DistributionDef{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		tags:         d.prefix.append(t),
+		sampleRate:   d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundDistributionDef2 is a DistributionDef2 bound to a Metrics, see DistributionDef2.Bind.
+type BoundDistributionDef2[V0 TagValue, V1 TagValue] struct {
+	m     *Metrics
+	d     DistributionDef2[V0, V1]
+	cache *metricMap[tagValues2[V0, V1], *Distribution]
+}
+
+// Bind returns a BoundDistributionDef2 for looking up the distributions of d in m. Get() is
+// the same as m.Distribution(d.Values(...)), but remembers the distribution for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d DistributionDef2[V0, V1]) Bind(m *Metrics) BoundDistributionDef2[V0, V1] {
+	return BoundDistributionDef2[V0, V1]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues2[V0, V1], *Distribution]{},
+	}
+}
+
+// Get returns the distribution with the given tag values.
+func (b BoundDistributionDef2[V0, V1]) Get(v0 V0, v1 V1) *Distribution {
+	k := tagValues2[V0, V1]{v0, v1}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Distribution(b.d.Values(v0, v1)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a DistributionDef1 that
+// can be used to set the rest.
+func (d DistributionDef2[V0, V1]) Prefix1(v0 V0) DistributionDef1[V1] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return DistributionDef1[V1]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[1]string)(d.keys[1:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format1,
+
+		ok: d.ok,
+	}
+}
+
+// DistributionDef3 is the definition of a distribution metric with 3 tag(s).
+type DistributionDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
+	name         string
+	previousName string
+	unit         Unit
+	prefix       tags
+	keys         [3]string
+	sampleRate   float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+
+	ok bool
+}
+
+// NewDistributionDef3 defines a distribution metric with 3 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewDistributionDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [3]string,
+	sampleRate float64,
+	opts ...DefOption,
+) DistributionDef3[V0, V1, V2] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes3[V0, V1, V2](),
+		o,
+	)
+	return makeDistributionDef3[V0, V1, V2](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterDistributionDef3 defines a distribution metric with 3 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewDistributionDef3, it can be called at any time.
+// See Registry.
+func RegisterDistributionDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [3]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (DistributionDef3[V0, V1, V2], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes3[V0, V1, V2](),
+		o,
+	)
+	if err != nil {
+		return DistributionDef3[V0, V1, V2]{}, err
+	}
+	return makeDistributionDef3[V0, V1, V2](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeDistributionDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	name string,
+	unit Unit,
+	keys [3]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) DistributionDef3[V0, V1, V2] {
+	return DistributionDef3[V0, V1, V2]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		unit:         unit,
+		keys:         keys,
+		sampleRate:   sampleRate,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
+// Metrics.Distribution() to produce a metric to log data to.
+func (d DistributionDef3[V0, V1, V2]) Values(v0 V0, v1 V1, v2 V2) DistributionDef {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return DistributionDef{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		tags:         d.prefix.append(t),
+		sampleRate:   d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundDistributionDef3 is a DistributionDef3 bound to a Metrics, see DistributionDef3.Bind.
+type BoundDistributionDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
+	m     *Metrics
+	d     DistributionDef3[V0, V1, V2]
+	cache *metricMap[tagValues3[V0, V1, V2], *Distribution]
+}
+
+// Bind returns a BoundDistributionDef3 for looking up the distributions of d in m. Get() is
+// the same as m.Distribution(d.Values(...)), but remembers the distribution for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d DistributionDef3[V0, V1, V2]) Bind(m *Metrics) BoundDistributionDef3[V0, V1, V2] {
+	return BoundDistributionDef3[V0, V1, V2]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues3[V0, V1, V2], *Distribution]{},
+	}
+}
+
+// Get returns the distribution with the given tag values.
+func (b BoundDistributionDef3[V0, V1, V2]) Get(v0 V0, v1 V1, v2 V2) *Distribution {
+	k := tagValues3[V0, V1, V2]{v0, v1, v2}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Distribution(b.d.Values(v0, v1, v2)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a DistributionDef2 that
+// can be used to set the rest.
+func (d DistributionDef3[V0, V1, V2]) Prefix1(v0 V0) DistributionDef2[V1, V2] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return DistributionDef2[V1, V2]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[2]string)(d.keys[1:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a DistributionDef1 that
+// can be used to set the rest.
+func (d DistributionDef3[V0, V1, V2]) Prefix2(v0 V0, v1 V1) DistributionDef1[V2] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return DistributionDef1[V2]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[1]string)(d.keys[2:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format2,
+
+		ok: d.ok,
+	}
+}
+
+// DistributionDef4 is the definition of a distribution metric with 4 tag(s).
+type DistributionDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
+	name         string
+	previousName string
+	unit         Unit
+	prefix       tags
+	keys         [4]string
+	sampleRate   float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+
+	ok bool
+}
+
+// NewDistributionDef4 defines a distribution metric with 4 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewDistributionDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [4]string,
+	sampleRate float64,
+	opts ...DefOption,
+) DistributionDef4[V0, V1, V2, V3] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes4[V0, V1, V2, V3](),
+		o,
+	)
+	return makeDistributionDef4[V0, V1, V2, V3](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterDistributionDef4 defines a distribution metric with 4 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewDistributionDef4, it can be called at any time.
+// See Registry.
+func RegisterDistributionDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [4]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (DistributionDef4[V0, V1, V2, V3], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes4[V0, V1, V2, V3](),
+		o,
+	)
+	if err != nil {
+		return DistributionDef4[V0, V1, V2, V3]{}, err
+	}
+	return makeDistributionDef4[V0, V1, V2, V3](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeDistributionDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	name string,
+	unit Unit,
+	keys [4]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) DistributionDef4[V0, V1, V2, V3] {
+	return DistributionDef4[V0, V1, V2, V3]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		unit:         unit,
+		keys:         keys,
+		sampleRate:   sampleRate,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
+// Metrics.Distribution() to produce a metric to log data to.
+func (d DistributionDef4[V0, V1, V2, V3]) Values(v0 V0, v1 V1, v2 V2, v3 V3) DistributionDef {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return DistributionDef{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		tags:         d.prefix.append(t),
+		sampleRate:   d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundDistributionDef4 is a DistributionDef4 bound to a Metrics, see DistributionDef4.Bind.
+type BoundDistributionDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
+	m     *Metrics
+	d     DistributionDef4[V0, V1, V2, V3]
+	cache *metricMap[tagValues4[V0, V1, V2, V3], *Distribution]
+}
+
+// Bind returns a BoundDistributionDef4 for looking up the distributions of d in m. Get() is
+// the same as m.Distribution(d.Values(...)), but remembers the distribution for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d DistributionDef4[V0, V1, V2, V3]) Bind(m *Metrics) BoundDistributionDef4[V0, V1, V2, V3] {
+	return BoundDistributionDef4[V0, V1, V2, V3]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues4[V0, V1, V2, V3], *Distribution]{},
+	}
+}
+
+// Get returns the distribution with the given tag values.
+func (b BoundDistributionDef4[V0, V1, V2, V3]) Get(v0 V0, v1 V1, v2 V2, v3 V3) *Distribution {
+	k := tagValues4[V0, V1, V2, V3]{v0, v1, v2, v3}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Distribution(b.d.Values(v0, v1, v2, v3)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a DistributionDef3 that
+// can be used to set the rest.
+func (d DistributionDef4[V0, V1, V2, V3]) Prefix1(v0 V0) DistributionDef3[V1, V2, V3] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return DistributionDef3[V1, V2, V3]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[3]string)(d.keys[1:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a DistributionDef2 that
+// can be used to set the rest.
+func (d DistributionDef4[V0, V1, V2, V3]) Prefix2(v0 V0, v1 V1) DistributionDef2[V2, V3] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return DistributionDef2[V2, V3]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[2]string)(d.keys[2:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format2,
+		format1: d.format3,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a DistributionDef1 that
+// can be used to set the rest.
+func (d DistributionDef4[V0, V1, V2, V3]) Prefix3(v0 V0, v1 V1, v2 V2) DistributionDef1[V3] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return DistributionDef1[V3]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[1]string)(d.keys[3:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format3,
+
+		ok: d.ok,
+	}
+}
+
+// DistributionDef5 is the definition of a distribution metric with 5 tag(s).
+type DistributionDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
+	name         string
+	previousName string
+	unit         Unit
+	prefix       tags
+	keys         [5]string
+	sampleRate   float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+
+	ok bool
+}
+
+// NewDistributionDef5 defines a distribution metric with 5 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewDistributionDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [5]string,
+	sampleRate float64,
+	opts ...DefOption,
+) DistributionDef5[V0, V1, V2, V3, V4] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes5[V0, V1, V2, V3, V4](),
+		o,
+	)
+	return makeDistributionDef5[V0, V1, V2, V3, V4](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterDistributionDef5 defines a distribution metric with 5 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewDistributionDef5, it can be called at any time.
+// See Registry.
+func RegisterDistributionDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [5]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (DistributionDef5[V0, V1, V2, V3, V4], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes5[V0, V1, V2, V3, V4](),
+		o,
+	)
+	if err != nil {
+		return DistributionDef5[V0, V1, V2, V3, V4]{}, err
+	}
+	return makeDistributionDef5[V0, V1, V2, V3, V4](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeDistributionDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	name string,
+	unit Unit,
+	keys [5]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) DistributionDef5[V0, V1, V2, V3, V4] {
+	return DistributionDef5[V0, V1, V2, V3, V4]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		unit:         unit,
+		keys:         keys,
+		sampleRate:   sampleRate,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
+// Metrics.Distribution() to produce a metric to log data to.
+func (d DistributionDef5[V0, V1, V2, V3, V4]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) DistributionDef {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return DistributionDef{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		tags:         d.prefix.append(t),
+		sampleRate:   d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundDistributionDef5 is a DistributionDef5 bound to a Metrics, see DistributionDef5.Bind.
+type BoundDistributionDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
+	m     *Metrics
+	d     DistributionDef5[V0, V1, V2, V3, V4]
+	cache *metricMap[tagValues5[V0, V1, V2, V3, V4], *Distribution]
+}
+
+// Bind returns a BoundDistributionDef5 for looking up the distributions of d in m. Get() is
+// the same as m.Distribution(d.Values(...)), but remembers the distribution for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d DistributionDef5[V0, V1, V2, V3, V4]) Bind(m *Metrics) BoundDistributionDef5[V0, V1, V2, V3, V4] {
+	return BoundDistributionDef5[V0, V1, V2, V3, V4]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues5[V0, V1, V2, V3, V4], *Distribution]{},
+	}
+}
+
+// Get returns the distribution with the given tag values.
+func (b BoundDistributionDef5[V0, V1, V2, V3, V4]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) *Distribution {
+	k := tagValues5[V0, V1, V2, V3, V4]{v0, v1, v2, v3, v4}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Distribution(b.d.Values(v0, v1, v2, v3, v4)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a DistributionDef4 that
+// can be used to set the rest.
+func (d DistributionDef5[V0, V1, V2, V3, V4]) Prefix1(v0 V0) DistributionDef4[V1, V2, V3, V4] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return DistributionDef4[V1, V2, V3, V4]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[4]string)(d.keys[1:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a DistributionDef3 that
+// can be used to set the rest.
+func (d DistributionDef5[V0, V1, V2, V3, V4]) Prefix2(v0 V0, v1 V1) DistributionDef3[V2, V3, V4] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return DistributionDef3[V2, V3, V4]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[3]string)(d.keys[2:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a DistributionDef2 that
+// can be used to set the rest.
+func (d DistributionDef5[V0, V1, V2, V3, V4]) Prefix3(v0 V0, v1 V1, v2 V2) DistributionDef2[V3, V4] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return DistributionDef2[V3, V4]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[2]string)(d.keys[3:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format3,
+		format1: d.format4,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix4 sets the value of the first 4 tags, returning a DistributionDef1 that
+// can be used to set the rest.
+func (d DistributionDef5[V0, V1, V2, V3, V4]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) DistributionDef1[V4] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return DistributionDef1[V4]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[1]string)(d.keys[4:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format4,
+
+		ok: d.ok,
+	}
+}
+
+// DistributionDef6 is the definition of a distribution metric with 6 tag(s).
+type DistributionDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue] struct {
+	name         string
+	previousName string
+	unit         Unit
+	prefix       tags
+	keys         [6]string
+	sampleRate   float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+
+	ok bool
+}
+
+// NewDistributionDef6 defines a distribution metric with 6 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewDistributionDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [6]string,
+	sampleRate float64,
+	opts ...DefOption,
+) DistributionDef6[V0, V1, V2, V3, V4, V5] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes6[V0, V1, V2, V3, V4, V5](),
+		o,
+	)
+	return makeDistributionDef6[V0, V1, V2, V3, V4, V5](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterDistributionDef6 defines a distribution metric with 6 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewDistributionDef6, it can be called at any time.
+// See Registry.
+func RegisterDistributionDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [6]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (DistributionDef6[V0, V1, V2, V3, V4, V5], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes6[V0, V1, V2, V3, V4, V5](),
+		o,
+	)
+	if err != nil {
+		return DistributionDef6[V0, V1, V2, V3, V4, V5]{}, err
+	}
+	return makeDistributionDef6[V0, V1, V2, V3, V4, V5](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeDistributionDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	name string,
+	unit Unit,
+	keys [6]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) DistributionDef6[V0, V1, V2, V3, V4, V5] {
+	return DistributionDef6[V0, V1, V2, V3, V4, V5]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		unit:         unit,
+		keys:         keys,
+		sampleRate:   sampleRate,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
+// Metrics.Distribution() to produce a metric to log data to.
+func (d DistributionDef6[V0, V1, V2, V3, V4, V5]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) DistributionDef {
+	t := tags{n: 6}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+
+	return DistributionDef{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		tags:         d.prefix.append(t),
+		sampleRate:   d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundDistributionDef6 is a DistributionDef6 bound to a Metrics, see DistributionDef6.Bind.
+type BoundDistributionDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue] struct {
+	m     *Metrics
+	d     DistributionDef6[V0, V1, V2, V3, V4, V5]
+	cache *metricMap[tagValues6[V0, V1, V2, V3, V4, V5], *Distribution]
+}
+
+// Bind returns a BoundDistributionDef6 for looking up the distributions of d in m. Get() is
+// the same as m.Distribution(d.Values(...)), but remembers the distribution for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d DistributionDef6[V0, V1, V2, V3, V4, V5]) Bind(m *Metrics) BoundDistributionDef6[V0, V1, V2, V3, V4, V5] {
+	return BoundDistributionDef6[V0, V1, V2, V3, V4, V5]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues6[V0, V1, V2, V3, V4, V5], *Distribution]{},
+	}
+}
+
+// Get returns the distribution with the given tag values.
+func (b BoundDistributionDef6[V0, V1, V2, V3, V4, V5]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) *Distribution {
+	k := tagValues6[V0, V1, V2, V3, V4, V5]{v0, v1, v2, v3, v4, v5}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Distribution(b.d.Values(v0, v1, v2, v3, v4, v5)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a DistributionDef5 that
+// can be used to set the rest.
+func (d DistributionDef6[V0, V1, V2, V3, V4, V5]) Prefix1(v0 V0) DistributionDef5[V1, V2, V3, V4, V5] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return DistributionDef5[V1, V2, V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[5]string)(d.keys[1:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a DistributionDef4 that
+// can be used to set the rest.
+func (d DistributionDef6[V0, V1, V2, V3, V4, V5]) Prefix2(v0 V0, v1 V1) DistributionDef4[V2, V3, V4, V5] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return DistributionDef4[V2, V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[4]string)(d.keys[2:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a DistributionDef3 that
+// can be used to set the rest.
+func (d DistributionDef6[V0, V1, V2, V3, V4, V5]) Prefix3(v0 V0, v1 V1, v2 V2) DistributionDef3[V3, V4, V5] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return DistributionDef3[V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[3]string)(d.keys[3:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix4 sets the value of the first 4 tags, returning a DistributionDef2 that
+// can be used to set the rest.
+func (d DistributionDef6[V0, V1, V2, V3, V4, V5]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) DistributionDef2[V4, V5] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return DistributionDef2[V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[2]string)(d.keys[4:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format4,
+		format1: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix5 sets the value of the first 5 tags, returning a DistributionDef1 that
+// can be used to set the rest.
+func (d DistributionDef6[V0, V1, V2, V3, V4, V5]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) DistributionDef1[V5] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return DistributionDef1[V5]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[1]string)(d.keys[5:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// DistributionDef7 is the definition of a distribution metric with 7 tag(s).
+type DistributionDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue] struct {
+	name         string
+	previousName string
+	unit         Unit
+	prefix       tags
+	keys         [7]string
+	sampleRate   float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+	format6 func(V6) string
+
+	ok bool
+}
+
+// NewDistributionDef7 defines a distribution metric with 7 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewDistributionDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [7]string,
+	sampleRate float64,
+	opts ...DefOption,
+) DistributionDef7[V0, V1, V2, V3, V4, V5, V6] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes7[V0, V1, V2, V3, V4, V5, V6](),
+		o,
+	)
+	return makeDistributionDef7[V0, V1, V2, V3, V4, V5, V6](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterDistributionDef7 defines a distribution metric with 7 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewDistributionDef7, it can be called at any time.
+// See Registry.
+func RegisterDistributionDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [7]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (DistributionDef7[V0, V1, V2, V3, V4, V5, V6], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes7[V0, V1, V2, V3, V4, V5, V6](),
+		o,
+	)
+	if err != nil {
+		return DistributionDef7[V0, V1, V2, V3, V4, V5, V6]{}, err
+	}
+	return makeDistributionDef7[V0, V1, V2, V3, V4, V5, V6](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeDistributionDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	name string,
+	unit Unit,
+	keys [7]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) DistributionDef7[V0, V1, V2, V3, V4, V5, V6] {
+	return DistributionDef7[V0, V1, V2, V3, V4, V5, V6]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		unit:         unit,
+		keys:         keys,
+		sampleRate:   sampleRate,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+		format6: tagFormatter[V6](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
+// Metrics.Distribution() to produce a metric to log data to.
+func (d DistributionDef7[V0, V1, V2, V3, V4, V5, V6]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) DistributionDef {
+	t := tags{n: 7}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
+
+	return DistributionDef{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		tags:         d.prefix.append(t),
+		sampleRate:   d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundDistributionDef7 is a DistributionDef7 bound to a Metrics, see DistributionDef7.Bind.
+type BoundDistributionDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue] struct {
+	m     *Metrics
+	d     DistributionDef7[V0, V1, V2, V3, V4, V5, V6]
+	cache *metricMap[tagValues7[V0, V1, V2, V3, V4, V5, V6], *Distribution]
+}
+
+// Bind returns a BoundDistributionDef7 for looking up the distributions of d in m. Get() is
+// the same as m.Distribution(d.Values(...)), but remembers the distribution for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d DistributionDef7[V0, V1, V2, V3, V4, V5, V6]) Bind(m *Metrics) BoundDistributionDef7[V0, V1, V2, V3, V4, V5, V6] {
+	return BoundDistributionDef7[V0, V1, V2, V3, V4, V5, V6]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues7[V0, V1, V2, V3, V4, V5, V6], *Distribution]{},
+	}
+}
+
+// Get returns the distribution with the given tag values.
+func (b BoundDistributionDef7[V0, V1, V2, V3, V4, V5, V6]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) *Distribution {
+	k := tagValues7[V0, V1, V2, V3, V4, V5, V6]{v0, v1, v2, v3, v4, v5, v6}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Distribution(b.d.Values(v0, v1, v2, v3, v4, v5, v6)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a DistributionDef6 that
+// can be used to set the rest.
+func (d DistributionDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix1(v0 V0) DistributionDef6[V1, V2, V3, V4, V5, V6] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return DistributionDef6[V1, V2, V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[6]string)(d.keys[1:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+		format5: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a DistributionDef5 that
+// can be used to set the rest.
+func (d DistributionDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix2(v0 V0, v1 V1) DistributionDef5[V2, V3, V4, V5, V6] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return DistributionDef5[V2, V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[5]string)(d.keys[2:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+		format4: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a DistributionDef4 that
+// can be used to set the rest.
+func (d DistributionDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix3(v0 V0, v1 V1, v2 V2) DistributionDef4[V3, V4, V5, V6] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return DistributionDef4[V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[4]string)(d.keys[3:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+		format3: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix4 sets the value of the first 4 tags, returning a DistributionDef3 that
+// can be used to set the rest.
+func (d DistributionDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) DistributionDef3[V4, V5, V6] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return DistributionDef3[V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[3]string)(d.keys[4:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format4,
+		format1: d.format5,
+		format2: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix5 sets the value of the first 5 tags, returning a DistributionDef2 that
+// can be used to set the rest.
+func (d DistributionDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) DistributionDef2[V5, V6] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return DistributionDef2[V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[2]string)(d.keys[5:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format5,
+		format1: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix6 sets the value of the first 6 tags, returning a DistributionDef1 that
+// can be used to set the rest.
+func (d DistributionDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix6(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) DistributionDef1[V6] {
+	t := tags{n: 6}
+	copy(t.keys[:], d.keys[:6])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+
+	return DistributionDef1[V6]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[1]string)(d.keys[6:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format6,
+
+		ok: d.ok,
+	}
+}
+
+// DistributionDef8 is the definition of a distribution metric with 8 tag(s).
+type DistributionDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue] struct {
+	name         string
+	previousName string
+	unit         Unit
+	prefix       tags
+	keys         [8]string
+	sampleRate   float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+	format6 func(V6) string
+	format7 func(V7) string
+
+	ok bool
+}
+
+// NewDistributionDef8 defines a distribution metric with 8 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewDistributionDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [8]string,
+	sampleRate float64,
+	opts ...DefOption,
+) DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes8[V0, V1, V2, V3, V4, V5, V6, V7](),
+		o,
+	)
+	return makeDistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterDistributionDef8 defines a distribution metric with 8 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewDistributionDef8, it can be called at any time.
+// See Registry.
+func RegisterDistributionDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [8]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		DistributionType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes8[V0, V1, V2, V3, V4, V5, V6, V7](),
+		o,
+	)
+	if err != nil {
+		return DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]{}, err
+	}
+	return makeDistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7](
+		name,
+		unit,
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeDistributionDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	name string,
+	unit Unit,
+	keys [8]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	return DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+		unit:         unit,
+		keys:         keys,
+		sampleRate:   sampleRate,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+		format6: tagFormatter[V6](),
+		format7: tagFormatter[V7](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
+// Metrics.Distribution() to produce a metric to log data to.
+func (d DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6, v7 V7) DistributionDef {
+	t := tags{n: 8}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
+	t.values[7] = formatTag(d.format7, v7)
+
+	return DistributionDef{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		tags:         d.prefix.append(t),
+		sampleRate:   d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundDistributionDef8 is a DistributionDef8 bound to a Metrics, see DistributionDef8.Bind.
+type BoundDistributionDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue] struct {
+	m     *Metrics
+	d     DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]
+	cache *metricMap[tagValues8[V0, V1, V2, V3, V4, V5, V6, V7], *Distribution]
+}
+
+// Bind returns a BoundDistributionDef8 for looking up the distributions of d in m. Get() is
+// the same as m.Distribution(d.Values(...)), but remembers the distribution for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Bind(m *Metrics) BoundDistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	return BoundDistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues8[V0, V1, V2, V3, V4, V5, V6, V7], *Distribution]{},
+	}
+}
+
+// Get returns the distribution with the given tag values.
+func (b BoundDistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6, v7 V7) *Distribution {
+	k := tagValues8[V0, V1, V2, V3, V4, V5, V6, V7]{v0, v1, v2, v3, v4, v5, v6, v7}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Distribution(b.d.Values(v0, v1, v2, v3, v4, v5, v6, v7)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a DistributionDef7 that
+// can be used to set the rest.
+func (d DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix1(v0 V0) DistributionDef7[V1, V2, V3, V4, V5, V6, V7] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return DistributionDef7[V1, V2, V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[7]string)(d.keys[1:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+		format5: d.format6,
+		format6: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a DistributionDef6 that
+// can be used to set the rest.
+func (d DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix2(v0 V0, v1 V1) DistributionDef6[V2, V3, V4, V5, V6, V7] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return DistributionDef6[V2, V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[6]string)(d.keys[2:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+		format4: d.format6,
+		format5: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a DistributionDef5 that
+// can be used to set the rest.
+func (d DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix3(v0 V0, v1 V1, v2 V2) DistributionDef5[V3, V4, V5, V6, V7] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return DistributionDef5[V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[5]string)(d.keys[3:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+		format3: d.format6,
+		format4: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix4 sets the value of the first 4 tags, returning a DistributionDef4 that
+// can be used to set the rest.
+func (d DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) DistributionDef4[V4, V5, V6, V7] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return DistributionDef4[V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[4]string)(d.keys[4:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format4,
+		format1: d.format5,
+		format2: d.format6,
+		format3: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix5 sets the value of the first 5 tags, returning a DistributionDef3 that
+// can be used to set the rest.
+func (d DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) DistributionDef3[V5, V6, V7] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return DistributionDef3[V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[3]string)(d.keys[5:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format5,
+		format1: d.format6,
+		format2: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix6 sets the value of the first 6 tags, returning a DistributionDef2 that
+// can be used to set the rest.
+func (d DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix6(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) DistributionDef2[V6, V7] {
+	t := tags{n: 6}
+	copy(t.keys[:], d.keys[:6])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+
+	return DistributionDef2[V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[2]string)(d.keys[6:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format6,
+		format1: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix7 sets the value of the first 7 tags, returning a DistributionDef1 that
+// can be used to set the rest.
+func (d DistributionDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix7(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) DistributionDef1[V7] {
+	t := tags{n: 7}
+	copy(t.keys[:], d.keys[:7])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
+
+	return DistributionDef1[V7]{
+		name:         d.name,
+		previousName: d.previousName,
+		unit:         d.unit,
+		prefix:       t,
+		keys:         *((*[1]string)(d.keys[7:])),
+		sampleRate:   d.sampleRate,
+
+		format0: d.format7,
+
+		ok: d.ok,
+	}
+}
+
+// SetDef1 is the definition of a set metric with 1 tag(s).
+type SetDef1[V0 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix     tags
+	keys       [1]string
+	sampleRate float64
+
+	format0 func(V0) string
+
+	ok bool
+}
+
+// NewSetDef1 defines a set metric with 1 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewSetDef1[V0 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [1]string,
+	sampleRate float64,
+	opts ...DefOption,
+) SetDef1[V0] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes1[V0](),
+		o,
+	)
+	return makeSetDef1[V0](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterSetDef1 defines a set metric with 1 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewSetDef1, it can be called at any time.
+// See Registry.
+func RegisterSetDef1[V0 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [1]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (SetDef1[V0], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes1[V0](),
+		o,
+	)
+	if err != nil {
+		return SetDef1[V0]{}, err
+	}
+	return makeSetDef1[V0](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeSetDef1[V0 TagValue](
+	name string,
+
+	keys [1]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) SetDef1[V0] {
+	return SetDef1[V0]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys:       keys,
+		sampleRate: sampleRate,
+
+		format0: tagFormatter[V0](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a SetDef that has all of the given tag values bound. It can be passed to
+// Metrics.Set() to produce a metric to log data to.
+func (d SetDef1[V0]) Values(v0 V0) SetDef {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return SetDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags:       d.prefix.append(t),
+		sampleRate: d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundSetDef1 is a SetDef1 bound to a Metrics, see SetDef1.Bind.
+type BoundSetDef1[V0 TagValue] struct {
+	m     *Metrics
+	d     SetDef1[V0]
+	cache *metricMap[tagValues1[V0], *Set]
+}
+
+// Bind returns a BoundSetDef1 for looking up the sets of d in m. Get() is
+// the same as m.Set(d.Values(...)), but remembers the set for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d SetDef1[V0]) Bind(m *Metrics) BoundSetDef1[V0] {
+	return BoundSetDef1[V0]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues1[V0], *Set]{},
+	}
+}
+
+// Get returns the set with the given tag values.
+func (b BoundSetDef1[V0]) Get(v0 V0) *Set {
+	k := tagValues1[V0]{v0}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Set(b.d.Values(v0)))
+	}
+	return x
+}
+
+// SetDef2 is the definition of a set metric with 2 tag(s).
+type SetDef2[V0 TagValue, V1 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix     tags
+	keys       [2]string
+	sampleRate float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+
+	ok bool
+}
+
+// NewSetDef2 defines a set metric with 2 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewSetDef2[V0 TagValue, V1 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [2]string,
+	sampleRate float64,
+	opts ...DefOption,
+) SetDef2[V0, V1] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes2[V0, V1](),
+		o,
+	)
+	return makeSetDef2[V0, V1](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterSetDef2 defines a set metric with 2 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewSetDef2, it can be called at any time.
+// See Registry.
+func RegisterSetDef2[V0 TagValue, V1 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [2]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (SetDef2[V0, V1], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes2[V0, V1](),
+		o,
+	)
+	if err != nil {
+		return SetDef2[V0, V1]{}, err
+	}
+	return makeSetDef2[V0, V1](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeSetDef2[V0 TagValue, V1 TagValue](
+	name string,
+
+	keys [2]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) SetDef2[V0, V1] {
+	return SetDef2[V0, V1]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys:       keys,
+		sampleRate: sampleRate,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a SetDef that has all of the given tag values bound. It can be passed to
+// Metrics.Set() to produce a metric to log data to.
+func (d SetDef2[V0, V1]) Values(v0 V0, v1 V1) SetDef {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return SetDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags:       d.prefix.append(t),
+		sampleRate: d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundSetDef2 is a SetDef2 bound to a Metrics, see SetDef2.Bind.
+type BoundSetDef2[V0 TagValue, V1 TagValue] struct {
+	m     *Metrics
+	d     SetDef2[V0, V1]
+	cache *metricMap[tagValues2[V0, V1], *Set]
+}
+
+// Bind returns a BoundSetDef2 for looking up the sets of d in m. Get() is
+// the same as m.Set(d.Values(...)), but remembers the set for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d SetDef2[V0, V1]) Bind(m *Metrics) BoundSetDef2[V0, V1] {
+	return BoundSetDef2[V0, V1]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues2[V0, V1], *Set]{},
+	}
+}
+
+// Get returns the set with the given tag values.
+func (b BoundSetDef2[V0, V1]) Get(v0 V0, v1 V1) *Set {
+	k := tagValues2[V0, V1]{v0, v1}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Set(b.d.Values(v0, v1)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a SetDef1 that
+// can be used to set the rest.
+func (d SetDef2[V0, V1]) Prefix1(v0 V0) SetDef1[V1] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return SetDef1[V1]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[1]string)(d.keys[1:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format1,
+
+		ok: d.ok,
+	}
+}
+
+// SetDef3 is the definition of a set metric with 3 tag(s).
+type SetDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix     tags
+	keys       [3]string
+	sampleRate float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+
+	ok bool
+}
+
+// NewSetDef3 defines a set metric with 3 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewSetDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [3]string,
+	sampleRate float64,
+	opts ...DefOption,
+) SetDef3[V0, V1, V2] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes3[V0, V1, V2](),
+		o,
+	)
+	return makeSetDef3[V0, V1, V2](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterSetDef3 defines a set metric with 3 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewSetDef3, it can be called at any time.
+// See Registry.
+func RegisterSetDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [3]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (SetDef3[V0, V1, V2], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes3[V0, V1, V2](),
+		o,
+	)
+	if err != nil {
+		return SetDef3[V0, V1, V2]{}, err
+	}
+	return makeSetDef3[V0, V1, V2](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeSetDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+	name string,
+
+	keys [3]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) SetDef3[V0, V1, V2] {
+	return SetDef3[V0, V1, V2]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys:       keys,
+		sampleRate: sampleRate,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a SetDef that has all of the given tag values bound. It can be passed to
+// Metrics.Set() to produce a metric to log data to.
+func (d SetDef3[V0, V1, V2]) Values(v0 V0, v1 V1, v2 V2) SetDef {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return SetDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags:       d.prefix.append(t),
+		sampleRate: d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundSetDef3 is a SetDef3 bound to a Metrics, see SetDef3.Bind.
+type BoundSetDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
+	m     *Metrics
+	d     SetDef3[V0, V1, V2]
+	cache *metricMap[tagValues3[V0, V1, V2], *Set]
+}
+
+// Bind returns a BoundSetDef3 for looking up the sets of d in m. Get() is
+// the same as m.Set(d.Values(...)), but remembers the set for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d SetDef3[V0, V1, V2]) Bind(m *Metrics) BoundSetDef3[V0, V1, V2] {
+	return BoundSetDef3[V0, V1, V2]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues3[V0, V1, V2], *Set]{},
+	}
+}
+
+// Get returns the set with the given tag values.
+func (b BoundSetDef3[V0, V1, V2]) Get(v0 V0, v1 V1, v2 V2) *Set {
+	k := tagValues3[V0, V1, V2]{v0, v1, v2}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Set(b.d.Values(v0, v1, v2)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a SetDef2 that
+// can be used to set the rest.
+func (d SetDef3[V0, V1, V2]) Prefix1(v0 V0) SetDef2[V1, V2] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return SetDef2[V1, V2]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[2]string)(d.keys[1:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a SetDef1 that
+// can be used to set the rest.
+func (d SetDef3[V0, V1, V2]) Prefix2(v0 V0, v1 V1) SetDef1[V2] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return SetDef1[V2]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[1]string)(d.keys[2:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format2,
+
+		ok: d.ok,
+	}
+}
+
+// SetDef4 is the definition of a set metric with 4 tag(s).
+type SetDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix     tags
+	keys       [4]string
+	sampleRate float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+
+	ok bool
+}
+
+// NewSetDef4 defines a set metric with 4 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewSetDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	name string,
 	description string,
 	unit Unit,
-	keys [3]string,
+	keys [4]string,
 	sampleRate float64,
-) DistributionDef3[V0, V1, V2] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
+	opts ...DefOption,
+) SetDef4[V0, V1, V2, V3] {
+	o := makeDefOptions(opts)
+	ok := registerDef(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes4[V0, V1, V2, V3](),
+		o,
+	)
+	return makeSetDef4[V0, V1, V2, V3](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterSetDef4 defines a set metric with 4 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewSetDef4, it can be called at any time.
+// See Registry.
+func RegisterSetDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [4]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (SetDef4[V0, V1, V2, V3], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes4[V0, V1, V2, V3](),
+		o,
+	)
+	if err != nil {
+		return SetDef4[V0, V1, V2, V3]{}, err
+	}
+	return makeSetDef4[V0, V1, V2, V3](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeSetDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	name string,
+
+	keys [4]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) SetDef4[V0, V1, V2, V3] {
+	return SetDef4[V0, V1, V2, V3]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
+		keys:       keys,
+		sampleRate: sampleRate,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+
+		ok: ok,
+	}
+}
+
+// Values returns a SetDef that has all of the given tag values bound. It can be passed to
+// Metrics.Set() to produce a metric to log data to.
+func (d SetDef4[V0, V1, V2, V3]) Values(v0 V0, v1 V1, v2 V2, v3 V3) SetDef {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return SetDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags:       d.prefix.append(t),
+		sampleRate: d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundSetDef4 is a SetDef4 bound to a Metrics, see SetDef4.Bind.
+type BoundSetDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
+	m     *Metrics
+	d     SetDef4[V0, V1, V2, V3]
+	cache *metricMap[tagValues4[V0, V1, V2, V3], *Set]
+}
+
+// Bind returns a BoundSetDef4 for looking up the sets of d in m. Get() is
+// the same as m.Set(d.Values(...)), but remembers the set for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d SetDef4[V0, V1, V2, V3]) Bind(m *Metrics) BoundSetDef4[V0, V1, V2, V3] {
+	return BoundSetDef4[V0, V1, V2, V3]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues4[V0, V1, V2, V3], *Set]{},
+	}
+}
+
+// Get returns the set with the given tag values.
+func (b BoundSetDef4[V0, V1, V2, V3]) Get(v0 V0, v1 V1, v2 V2, v3 V3) *Set {
+	k := tagValues4[V0, V1, V2, V3]{v0, v1, v2, v3}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Set(b.d.Values(v0, v1, v2, v3)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a SetDef3 that
+// can be used to set the rest.
+func (d SetDef4[V0, V1, V2, V3]) Prefix1(v0 V0) SetDef3[V1, V2, V3] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return SetDef3[V1, V2, V3]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[3]string)(d.keys[1:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a SetDef2 that
+// can be used to set the rest.
+func (d SetDef4[V0, V1, V2, V3]) Prefix2(v0 V0, v1 V1) SetDef2[V2, V3] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return SetDef2[V2, V3]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[2]string)(d.keys[2:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format2,
+		format1: d.format3,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix3 sets the value of the first 3 tags, returning a SetDef1 that
+// can be used to set the rest.
+func (d SetDef4[V0, V1, V2, V3]) Prefix3(v0 V0, v1 V1, v2 V2) SetDef1[V3] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+
+	return SetDef1[V3]{
+		name:         d.name,
+		previousName: d.previousName,
 
+		prefix:     t,
+		keys:       *((*[1]string)(d.keys[3:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format3,
+
+		ok: d.ok,
+	}
+}
+
+// SetDef5 is the definition of a set metric with 5 tag(s).
+type SetDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix     tags
+	keys       [5]string
+	sampleRate float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+
+	ok bool
+}
+
+// NewSetDef5 defines a set metric with 5 tag(s).
+//
+// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
+// (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
+func NewSetDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	name string,
+	description string,
+	unit Unit,
+	keys [5]string,
+	sampleRate float64,
+	opts ...DefOption,
+) SetDef5[V0, V1, V2, V3, V4] {
+	o := makeDefOptions(opts)
 	ok := registerDef(
-		DistributionType,
+		SetType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-		},
+		valueTypes5[V0, V1, V2, V3, V4](),
+		o,
 	)
-	return DistributionDef3[V0, V1, V2]{
-		name:       name,
-		unit:       unit,
+	return makeSetDef5[V0, V1, V2, V3, V4](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterSetDef5 defines a set metric with 5 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewSetDef5, it can be called at any time.
+// See Registry.
+func RegisterSetDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [5]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (SetDef5[V0, V1, V2, V3, V4], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes5[V0, V1, V2, V3, V4](),
+		o,
+	)
+	if err != nil {
+		return SetDef5[V0, V1, V2, V3, V4]{}, err
+	}
+	return makeSetDef5[V0, V1, V2, V3, V4](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeSetDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	name string,
+
+	keys [5]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) SetDef5[V0, V1, V2, V3, V4] {
+	return SetDef5[V0, V1, V2, V3, V4]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
 		keys:       keys,
 		sampleRate: sampleRate,
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			true,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+
 		ok: ok,
 	}
 }
 
-// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
-// Metrics.Distribution() to produce a metric to log data to.
-func (d DistributionDef3[V0, V1, V2]) Values(v0 V0, v1 V1, v2 V2) DistributionDef {
-	t := tags{n: 3}
-	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+// Values returns a SetDef that has all of the given tag values bound. It can be passed to
+// Metrics.Set() to produce a metric to log data to.
+func (d SetDef5[V0, V1, V2, V3, V4]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) SetDef {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return SetDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags:       d.prefix.append(t),
+		sampleRate: d.sampleRate,
+
+		ok: d.ok,
+	}
+}
+
+// BoundSetDef5 is a SetDef5 bound to a Metrics, see SetDef5.Bind.
+type BoundSetDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
+	m     *Metrics
+	d     SetDef5[V0, V1, V2, V3, V4]
+	cache *metricMap[tagValues5[V0, V1, V2, V3, V4], *Set]
+}
+
+// Bind returns a BoundSetDef5 for looking up the sets of d in m. Get() is
+// the same as m.Set(d.Values(...)), but remembers the set for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d SetDef5[V0, V1, V2, V3, V4]) Bind(m *Metrics) BoundSetDef5[V0, V1, V2, V3, V4] {
+	return BoundSetDef5[V0, V1, V2, V3, V4]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues5[V0, V1, V2, V3, V4], *Set]{},
+	}
+}
+
+// Get returns the set with the given tag values.
+func (b BoundSetDef5[V0, V1, V2, V3, V4]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) *Set {
+	k := tagValues5[V0, V1, V2, V3, V4]{v0, v1, v2, v3, v4}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Set(b.d.Values(v0, v1, v2, v3, v4)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a SetDef4 that
+// can be used to set the rest.
+func (d SetDef5[V0, V1, V2, V3, V4]) Prefix1(v0 V0) SetDef4[V1, V2, V3, V4] {
+	t := tags{n: 1}
+	copy(t.keys[:], d.keys[:1])
+	t.values[0] = formatTag(d.format0, v0)
+
+	return SetDef4[V1, V2, V3, V4]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[4]string)(d.keys[1:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+
+		ok: d.ok,
+	}
+}
+
+// Prefix2 sets the value of the first 2 tags, returning a SetDef3 that
+// can be used to set the rest.
+func (d SetDef5[V0, V1, V2, V3, V4]) Prefix2(v0 V0, v1 V1) SetDef3[V2, V3, V4] {
+	t := tags{n: 2}
+	copy(t.keys[:], d.keys[:2])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+
+	return SetDef3[V2, V3, V4]{
+		name:         d.name,
+		previousName: d.previousName,
 
-	return DistributionDef{
-		name:          d.name,
-		unit:          d.unit,
-		tags:          d.prefix.append(t),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		prefix:     t,
+		keys:       *((*[3]string)(d.keys[2:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a DistributionDef2 that
+// Prefix3 sets the value of the first 3 tags, returning a SetDef2 that
 // can be used to set the rest.
-func (d DistributionDef3[V0, V1, V2]) Prefix1(v0 V0) DistributionDef2[V1, V2] {
-	t := tags{n: 1}
-	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+func (d SetDef5[V0, V1, V2, V3, V4]) Prefix3(v0 V0, v1 V1, v2 V2) SetDef2[V3, V4] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
 
-	return DistributionDef2[V1, V2]{
-		name:          d.name,
-		unit:          d.unit,
-		prefix:        t,
-		keys:          *((*[2]string)(d.keys[1:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return SetDef2[V3, V4]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[2]string)(d.keys[3:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format3,
+		format1: d.format4,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix2 sets the value of the first 2 tags, returning a DistributionDef1 that
+// Prefix4 sets the value of the first 4 tags, returning a SetDef1 that
 // can be used to set the rest.
-func (d DistributionDef3[V0, V1, V2]) Prefix2(v0 V0, v1 V1) DistributionDef1[V2] {
-	t := tags{n: 2}
-	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+func (d SetDef5[V0, V1, V2, V3, V4]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) SetDef1[V4] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
 
-	return DistributionDef1[V2]{
-		name:          d.name,
-		unit:          d.unit,
-		prefix:        t,
-		keys:          *((*[1]string)(d.keys[2:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return SetDef1[V4]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[1]string)(d.keys[4:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format4,
+
+		ok: d.ok,
 	}
 }
 
-// DistributionDef4 is the definition of a distribution metric with 4 tag(s).
-type DistributionDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
-	name          string
-	unit          Unit
-	prefix        tags
-	keys          [4]string
-	sampleRate    float64
-	allComparable bool
-	ok            bool
+// SetDef6 is the definition of a set metric with 6 tag(s).
+type SetDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix     tags
+	keys       [6]string
+	sampleRate float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+
+	ok bool
 }
 
-// NewDistributionDef4 defines a distribution metric with 4 tag(s).
+// NewSetDef6 defines a set metric with 6 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
-func NewDistributionDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+//
+// opts can be used for optional settings, see DefOption.
+func NewSetDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
 	name string,
 	description string,
 	unit Unit,
-	keys [4]string,
+	keys [6]string,
 	sampleRate float64,
-) DistributionDef4[V0, V1, V2, V3] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
-	var zero3 V3
-
+	opts ...DefOption,
+) SetDef6[V0, V1, V2, V3, V4, V5] {
+	o := makeDefOptions(opts)
 	ok := registerDef(
-		DistributionType,
+		SetType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-			reflect.TypeOf(zero3),
-		},
+		valueTypes6[V0, V1, V2, V3, V4, V5](),
+		o,
 	)
-	return DistributionDef4[V0, V1, V2, V3]{
-		name:       name,
-		unit:       unit,
+	return makeSetDef6[V0, V1, V2, V3, V4, V5](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterSetDef6 defines a set metric with 6 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewSetDef6, it can be called at any time.
+// See Registry.
+func RegisterSetDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [6]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (SetDef6[V0, V1, V2, V3, V4, V5], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes6[V0, V1, V2, V3, V4, V5](),
+		o,
+	)
+	if err != nil {
+		return SetDef6[V0, V1, V2, V3, V4, V5]{}, err
+	}
+	return makeSetDef6[V0, V1, V2, V3, V4, V5](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeSetDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	name string,
+
+	keys [6]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) SetDef6[V0, V1, V2, V3, V4, V5] {
+	return SetDef6[V0, V1, V2, V3, V4, V5]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
 		keys:       keys,
 		sampleRate: sampleRate,
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			reflect.TypeOf(zero3).Comparable() &&
-			true,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+
 		ok: ok,
 	}
 }
 
-// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
-// Metrics.Distribution() to produce a metric to log data to.
-func (d DistributionDef4[V0, V1, V2, V3]) Values(v0 V0, v1 V1, v2 V2, v3 V3) DistributionDef {
-	t := tags{n: 4}
+// Values returns a SetDef that has all of the given tag values bound. It can be passed to
+// Metrics.Set() to produce a metric to log data to.
+func (d SetDef6[V0, V1, V2, V3, V4, V5]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) SetDef {
+	t := tags{n: 6}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
 
-	return DistributionDef{
-		name:          d.name,
-		unit:          d.unit,
-		tags:          d.prefix.append(t),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return SetDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags:       d.prefix.append(t),
+		sampleRate: d.sampleRate,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a DistributionDef3 that
+// BoundSetDef6 is a SetDef6 bound to a Metrics, see SetDef6.Bind.
+type BoundSetDef6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue] struct {
+	m     *Metrics
+	d     SetDef6[V0, V1, V2, V3, V4, V5]
+	cache *metricMap[tagValues6[V0, V1, V2, V3, V4, V5], *Set]
+}
+
+// Bind returns a BoundSetDef6 for looking up the sets of d in m. Get() is
+// the same as m.Set(d.Values(...)), but remembers the set for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d SetDef6[V0, V1, V2, V3, V4, V5]) Bind(m *Metrics) BoundSetDef6[V0, V1, V2, V3, V4, V5] {
+	return BoundSetDef6[V0, V1, V2, V3, V4, V5]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues6[V0, V1, V2, V3, V4, V5], *Set]{},
+	}
+}
+
+// Get returns the set with the given tag values.
+func (b BoundSetDef6[V0, V1, V2, V3, V4, V5]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) *Set {
+	k := tagValues6[V0, V1, V2, V3, V4, V5]{v0, v1, v2, v3, v4, v5}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Set(b.d.Values(v0, v1, v2, v3, v4, v5)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a SetDef5 that
 // can be used to set the rest.
-func (d DistributionDef4[V0, V1, V2, V3]) Prefix1(v0 V0) DistributionDef3[V1, V2, V3] {
+func (d SetDef6[V0, V1, V2, V3, V4, V5]) Prefix1(v0 V0) SetDef5[V1, V2, V3, V4, V5] {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
-	return DistributionDef3[V1, V2, V3]{
-		name:          d.name,
-		unit:          d.unit,
-		prefix:        t,
-		keys:          *((*[3]string)(d.keys[1:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return SetDef5[V1, V2, V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[5]string)(d.keys[1:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix2 sets the value of the first 2 tags, returning a DistributionDef2 that
+// Prefix2 sets the value of the first 2 tags, returning a SetDef4 that
 // can be used to set the rest.
-func (d DistributionDef4[V0, V1, V2, V3]) Prefix2(v0 V0, v1 V1) DistributionDef2[V2, V3] {
+func (d SetDef6[V0, V1, V2, V3, V4, V5]) Prefix2(v0 V0, v1 V1) SetDef4[V2, V3, V4, V5] {
 	t := tags{n: 2}
 	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
 
-	return DistributionDef2[V2, V3]{
-		name:          d.name,
-		unit:          d.unit,
-		prefix:        t,
-		keys:          *((*[2]string)(d.keys[2:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return SetDef4[V2, V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[4]string)(d.keys[2:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix3 sets the value of the first 3 tags, returning a DistributionDef1 that
+// Prefix3 sets the value of the first 3 tags, returning a SetDef3 that
 // can be used to set the rest.
-func (d DistributionDef4[V0, V1, V2, V3]) Prefix3(v0 V0, v1 V1, v2 V2) DistributionDef1[V3] {
+func (d SetDef6[V0, V1, V2, V3, V4, V5]) Prefix3(v0 V0, v1 V1, v2 V2) SetDef3[V3, V4, V5] {
 	t := tags{n: 3}
 	copy(t.keys[:], d.keys[:3])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
 
-	return DistributionDef1[V3]{
-		name:          d.name,
-		unit:          d.unit,
-		prefix:        t,
-		keys:          *((*[1]string)(d.keys[3:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return SetDef3[V3, V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[3]string)(d.keys[3:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+
+		ok: d.ok,
 	}
 }
 
-// DistributionDef5 is the definition of a distribution metric with 5 tag(s).
-type DistributionDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
-	name          string
-	unit          Unit
-	prefix        tags
-	keys          [5]string
-	sampleRate    float64
-	allComparable bool
-	ok            bool
+// Prefix4 sets the value of the first 4 tags, returning a SetDef2 that
+// can be used to set the rest.
+func (d SetDef6[V0, V1, V2, V3, V4, V5]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) SetDef2[V4, V5] {
+	t := tags{n: 4}
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+
+	return SetDef2[V4, V5]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[2]string)(d.keys[4:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format4,
+		format1: d.format5,
+
+		ok: d.ok,
+	}
 }
 
-// NewDistributionDef5 defines a distribution metric with 5 tag(s).
+// Prefix5 sets the value of the first 5 tags, returning a SetDef1 that
+// can be used to set the rest.
+func (d SetDef6[V0, V1, V2, V3, V4, V5]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) SetDef1[V5] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return SetDef1[V5]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[1]string)(d.keys[5:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format5,
+
+		ok: d.ok,
+	}
+}
+
+// SetDef7 is the definition of a set metric with 7 tag(s).
+type SetDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix     tags
+	keys       [7]string
+	sampleRate float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+	format6 func(V6) string
+
+	ok bool
+}
+
+// NewSetDef7 defines a set metric with 7 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
-func NewDistributionDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+//
+// opts can be used for optional settings, see DefOption.
+func NewSetDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
 	name string,
 	description string,
 	unit Unit,
-	keys [5]string,
+	keys [7]string,
 	sampleRate float64,
-) DistributionDef5[V0, V1, V2, V3, V4] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
-	var zero3 V3
-	var zero4 V4
-
+	opts ...DefOption,
+) SetDef7[V0, V1, V2, V3, V4, V5, V6] {
+	o := makeDefOptions(opts)
 	ok := registerDef(
-		DistributionType,
+		SetType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-			reflect.TypeOf(zero3),
-			reflect.TypeOf(zero4),
-		},
+		valueTypes7[V0, V1, V2, V3, V4, V5, V6](),
+		o,
 	)
-	return DistributionDef5[V0, V1, V2, V3, V4]{
-		name:       name,
-		unit:       unit,
+	return makeSetDef7[V0, V1, V2, V3, V4, V5, V6](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
+}
+
+// RegisterSetDef7 defines a set metric with 7 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewSetDef7, it can be called at any time.
+// See Registry.
+func RegisterSetDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [7]string,
+	sampleRate float64,
+	opts ...DefOption,
+) (SetDef7[V0, V1, V2, V3, V4, V5, V6], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		SetType,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes7[V0, V1, V2, V3, V4, V5, V6](),
+		o,
+	)
+	if err != nil {
+		return SetDef7[V0, V1, V2, V3, V4, V5, V6]{}, err
+	}
+	return makeSetDef7[V0, V1, V2, V3, V4, V5, V6](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeSetDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	name string,
+
+	keys [7]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) SetDef7[V0, V1, V2, V3, V4, V5, V6] {
+	return SetDef7[V0, V1, V2, V3, V4, V5, V6]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
+
 		keys:       keys,
 		sampleRate: sampleRate,
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			reflect.TypeOf(zero3).Comparable() &&
-			reflect.TypeOf(zero4).Comparable() &&
-			true,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+		format6: tagFormatter[V6](),
+
 		ok: ok,
 	}
 }
 
-// Values returns a DistributionDef that has all of the given tag values bound. It can be passed to
-// Metrics.Distribution() to produce a metric to log data to.
-func (d DistributionDef5[V0, V1, V2, V3, V4]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) DistributionDef {
-	t := tags{n: 5}
+// Values returns a SetDef that has all of the given tag values bound. It can be passed to
+// Metrics.Set() to produce a metric to log data to.
+func (d SetDef7[V0, V1, V2, V3, V4, V5, V6]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) SetDef {
+	t := tags{n: 7}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
-	t.values[4] = v4
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
 
-	return DistributionDef{
-		name:          d.name,
-		unit:          d.unit,
-		tags:          d.prefix.append(t),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return SetDef{
+		name:         d.name,
+		previousName: d.previousName,
+
+		tags:       d.prefix.append(t),
+		sampleRate: d.sampleRate,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a DistributionDef4 that
+// BoundSetDef7 is a SetDef7 bound to a Metrics, see SetDef7.Bind.
+type BoundSetDef7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue] struct {
+	m     *Metrics
+	d     SetDef7[V0, V1, V2, V3, V4, V5, V6]
+	cache *metricMap[tagValues7[V0, V1, V2, V3, V4, V5, V6], *Set]
+}
+
+// Bind returns a BoundSetDef7 for looking up the sets of d in m. Get() is
+// the same as m.Set(d.Values(...)), but remembers the set for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d SetDef7[V0, V1, V2, V3, V4, V5, V6]) Bind(m *Metrics) BoundSetDef7[V0, V1, V2, V3, V4, V5, V6] {
+	return BoundSetDef7[V0, V1, V2, V3, V4, V5, V6]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues7[V0, V1, V2, V3, V4, V5, V6], *Set]{},
+	}
+}
+
+// Get returns the set with the given tag values.
+func (b BoundSetDef7[V0, V1, V2, V3, V4, V5, V6]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) *Set {
+	k := tagValues7[V0, V1, V2, V3, V4, V5, V6]{v0, v1, v2, v3, v4, v5, v6}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Set(b.d.Values(v0, v1, v2, v3, v4, v5, v6)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a SetDef6 that
 // can be used to set the rest.
-func (d DistributionDef5[V0, V1, V2, V3, V4]) Prefix1(v0 V0) DistributionDef4[V1, V2, V3, V4] {
+func (d SetDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix1(v0 V0) SetDef6[V1, V2, V3, V4, V5, V6] {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
-	return DistributionDef4[V1, V2, V3, V4]{
-		name:          d.name,
-		unit:          d.unit,
-		prefix:        t,
-		keys:          *((*[4]string)(d.keys[1:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return SetDef6[V1, V2, V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[6]string)(d.keys[1:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+		format5: d.format6,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix2 sets the value of the first 2 tags, returning a DistributionDef3 that
+// Prefix2 sets the value of the first 2 tags, returning a SetDef5 that
 // can be used to set the rest.
-func (d DistributionDef5[V0, V1, V2, V3, V4]) Prefix2(v0 V0, v1 V1) DistributionDef3[V2, V3, V4] {
+func (d SetDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix2(v0 V0, v1 V1) SetDef5[V2, V3, V4, V5, V6] {
 	t := tags{n: 2}
 	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
 
-	return DistributionDef3[V2, V3, V4]{
-		name:          d.name,
-		unit:          d.unit,
-		prefix:        t,
-		keys:          *((*[3]string)(d.keys[2:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return SetDef5[V2, V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[5]string)(d.keys[2:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+		format4: d.format6,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix3 sets the value of the first 3 tags, returning a DistributionDef2 that
+// Prefix3 sets the value of the first 3 tags, returning a SetDef4 that
 // can be used to set the rest.
-func (d DistributionDef5[V0, V1, V2, V3, V4]) Prefix3(v0 V0, v1 V1, v2 V2) DistributionDef2[V3, V4] {
+func (d SetDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix3(v0 V0, v1 V1, v2 V2) SetDef4[V3, V4, V5, V6] {
 	t := tags{n: 3}
 	copy(t.keys[:], d.keys[:3])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
 
-	return DistributionDef2[V3, V4]{
-		name:          d.name,
-		unit:          d.unit,
-		prefix:        t,
-		keys:          *((*[2]string)(d.keys[3:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+	return SetDef4[V3, V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[4]string)(d.keys[3:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+		format3: d.format6,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix4 sets the value of the first 4 tags, returning a DistributionDef1 that
+// Prefix4 sets the value of the first 4 tags, returning a SetDef3 that
 // can be used to set the rest.
-func (d DistributionDef5[V0, V1, V2, V3, V4]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) DistributionDef1[V4] {
+func (d SetDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) SetDef3[V4, V5, V6] {
 	t := tags{n: 4}
 	copy(t.keys[:], d.keys[:4])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
-
-	return DistributionDef1[V4]{
-		name:          d.name,
-		unit:          d.unit,
-		prefix:        t,
-		keys:          *((*[1]string)(d.keys[4:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
-	}
-}
-
-// SetDef1 is the definition of a set metric with 1 tag(s).
-type SetDef1[V0 TagValue] struct {
-	name string
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
 
-	prefix        tags
-	keys          [1]string
-	sampleRate    float64
-	allComparable bool
-	ok            bool
-}
+	return SetDef3[V4, V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
 
-// NewSetDef1 defines a set metric with 1 tag(s).
-//
-// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
-// (if main() has not yet started) or return an inert def that will not produce any data.
-func NewSetDef1[V0 TagValue](
-	name string,
-	description string,
-	unit Unit,
-	keys [1]string,
-	sampleRate float64,
-) SetDef1[V0] {
-	var zero0 V0
+		prefix:     t,
+		keys:       *((*[3]string)(d.keys[4:])),
+		sampleRate: d.sampleRate,
 
-	ok := registerDef(
-		SetType,
-		name,
-		description,
-		unit,
-		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-		},
-	)
-	return SetDef1[V0]{
-		name: name,
+		format0: d.format4,
+		format1: d.format5,
+		format2: d.format6,
 
-		keys:       keys,
-		sampleRate: sampleRate,
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			true,
-		ok: ok,
+		ok: d.ok,
 	}
 }
 
-// Values returns a SetDef that has all of the given tag values bound. It can be passed to
-// Metrics.Set() to produce a metric to log data to.
-func (d SetDef1[V0]) Values(v0 V0) SetDef {
-	t := tags{n: 1}
-	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
+// Prefix5 sets the value of the first 5 tags, returning a SetDef2 that
+// can be used to set the rest.
+func (d SetDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) SetDef2[V5, V6] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return SetDef2[V5, V6]{
+		name:         d.name,
+		previousName: d.previousName,
 
-	return SetDef{
-		name: d.name,
+		prefix:     t,
+		keys:       *((*[2]string)(d.keys[5:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format5,
+		format1: d.format6,
 
-		tags:          d.prefix.append(t),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		ok: d.ok,
 	}
 }
 
-// SetDef2 is the definition of a set metric with 2 tag(s).
-type SetDef2[V0 TagValue, V1 TagValue] struct {
-	name string
+// Prefix6 sets the value of the first 6 tags, returning a SetDef1 that
+// can be used to set the rest.
+func (d SetDef7[V0, V1, V2, V3, V4, V5, V6]) Prefix6(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) SetDef1[V6] {
+	t := tags{n: 6}
+	copy(t.keys[:], d.keys[:6])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+
+	return SetDef1[V6]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[1]string)(d.keys[6:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format6,
+
+		ok: d.ok,
+	}
+}
 
-	prefix        tags
-	keys          [2]string
-	sampleRate    float64
-	allComparable bool
-	ok            bool
+// SetDef8 is the definition of a set metric with 8 tag(s).
+type SetDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue] struct {
+	name         string
+	previousName string
+
+	prefix     tags
+	keys       [8]string
+	sampleRate float64
+
+	format0 func(V0) string
+	format1 func(V1) string
+	format2 func(V2) string
+	format3 func(V3) string
+	format4 func(V4) string
+	format5 func(V5) string
+	format6 func(V6) string
+	format7 func(V7) string
+
+	ok bool
 }
 
-// NewSetDef2 defines a set metric with 2 tag(s).
+// NewSetDef8 defines a set metric with 8 tag(s).
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
-func NewSetDef2[V0 TagValue, V1 TagValue](
+//
+// opts can be used for optional settings, see DefOption.
+func NewSetDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
 	name string,
 	description string,
 	unit Unit,
-	keys [2]string,
+	keys [8]string,
 	sampleRate float64,
-) SetDef2[V0, V1] {
-	var zero0 V0
-	var zero1 V1
-
+	opts ...DefOption,
+) SetDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	o := makeDefOptions(opts)
 	ok := registerDef(
 		SetType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-		},
+		valueTypes8[V0, V1, V2, V3, V4, V5, V6, V7](),
+		o,
 	)
-	return SetDef2[V0, V1]{
-		name: name,
-
-		keys:       keys,
-		sampleRate: sampleRate,
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			true,
-		ok: ok,
-	}
-}
-
-// Values returns a SetDef that has all of the given tag values bound. It can be passed to
-// Metrics.Set() to produce a metric to log data to.
-func (d SetDef2[V0, V1]) Values(v0 V0, v1 V1) SetDef {
-	t := tags{n: 2}
-	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-
-	return SetDef{
-		name: d.name,
-
-		tags:          d.prefix.append(t),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
-	}
-}
-
-// Prefix1 sets the value of the first 1 tags, returning a SetDef1 that
-// can be used to set the rest.
-func (d SetDef2[V0, V1]) Prefix1(v0 V0) SetDef1[V1] {
-	t := tags{n: 1}
-	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
-
-	return SetDef1[V1]{
-		name: d.name,
-
-		prefix:        t,
-		keys:          *((*[1]string)(d.keys[1:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
-	}
-}
-
-// SetDef3 is the definition of a set metric with 3 tag(s).
-type SetDef3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
-	name string
+	return makeSetDef8[V0, V1, V2, V3, V4, V5, V6, V7](
+		name,
 
-	prefix        tags
-	keys          [3]string
-	sampleRate    float64
-	allComparable bool
-	ok            bool
+		keys,
+		sampleRate,
+		o,
+		ok,
+	)
 }
 
-// NewSetDef3 defines a set metric with 3 tag(s).
-//
-// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
-// (if main() has not yet started) or return an inert def that will not produce any data.
-func NewSetDef3[V0 TagValue, V1 TagValue, V2 TagValue](
+// RegisterSetDef8 defines a set metric with 8 tag(s) in r, returning an
+// error if the definition is invalid. Unlike NewSetDef8, it can be called at any time.
+// See Registry.
+func RegisterSetDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	r *Registry,
 	name string,
 	description string,
 	unit Unit,
-	keys [3]string,
+	keys [8]string,
 	sampleRate float64,
-) SetDef3[V0, V1, V2] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
-
-	ok := registerDef(
+	opts ...DefOption,
+) (SetDef8[V0, V1, V2, V3, V4, V5, V6, V7], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
 		SetType,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-		},
+		valueTypes8[V0, V1, V2, V3, V4, V5, V6, V7](),
+		o,
 	)
-	return SetDef3[V0, V1, V2]{
-		name: name,
+	if err != nil {
+		return SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]{}, err
+	}
+	return makeSetDef8[V0, V1, V2, V3, V4, V5, V6, V7](
+		name,
+
+		keys,
+		sampleRate,
+		o,
+		true,
+	), nil
+}
+
+func makeSetDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	name string,
+
+	keys [8]string,
+	sampleRate float64,
+	o defOptions,
+	ok bool,
+) SetDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	return SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]{
+		name:         o.registry.publishedName(name),
+		previousName: o.previousName,
 
 		keys:       keys,
 		sampleRate: sampleRate,
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			true,
+
+		format0: tagFormatter[V0](),
+		format1: tagFormatter[V1](),
+		format2: tagFormatter[V2](),
+		format3: tagFormatter[V3](),
+		format4: tagFormatter[V4](),
+		format5: tagFormatter[V5](),
+		format6: tagFormatter[V6](),
+		format7: tagFormatter[V7](),
+
 		ok: ok,
 	}
 }
 
 // Values returns a SetDef that has all of the given tag values bound. It can be passed to
 // Metrics.Set() to produce a metric to log data to.
-func (d SetDef3[V0, V1, V2]) Values(v0 V0, v1 V1, v2 V2) SetDef {
-	t := tags{n: 3}
+func (d SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6, v7 V7) SetDef {
+	t := tags{n: 8}
 	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
+	t.values[7] = formatTag(d.format7, v7)
 
 	return SetDef{
-		name: d.name,
+		name:         d.name,
+		previousName: d.previousName,
 
-		tags:          d.prefix.append(t),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		tags:       d.prefix.append(t),
+		sampleRate: d.sampleRate,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a SetDef2 that
+// BoundSetDef8 is a SetDef8 bound to a Metrics, see SetDef8.Bind.
+type BoundSetDef8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue] struct {
+	m     *Metrics
+	d     SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]
+	cache *metricMap[tagValues8[V0, V1, V2, V3, V4, V5, V6, V7], *Set]
+}
+
+// Bind returns a BoundSetDef8 for looking up the sets of d in m. Get() is
+// the same as m.Set(d.Values(...)), but remembers the set for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Bind(m *Metrics) BoundSetDef8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	return BoundSetDef8[V0, V1, V2, V3, V4, V5, V6, V7]{
+		m:     m,
+		d:     d,
+		cache: &metricMap[tagValues8[V0, V1, V2, V3, V4, V5, V6, V7], *Set]{},
+	}
+}
+
+// Get returns the set with the given tag values.
+func (b BoundSetDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Get(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6, v7 V7) *Set {
+	k := tagValues8[V0, V1, V2, V3, V4, V5, V6, V7]{v0, v1, v2, v3, v4, v5, v6, v7}
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.Set(b.d.Values(v0, v1, v2, v3, v4, v5, v6, v7)))
+	}
+	return x
+}
+
+// Prefix1 sets the value of the first 1 tags, returning a SetDef7 that
 // can be used to set the rest.
-func (d SetDef3[V0, V1, V2]) Prefix1(v0 V0) SetDef2[V1, V2] {
+func (d SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix1(v0 V0) SetDef7[V1, V2, V3, V4, V5, V6, V7] {
 	t := tags{n: 1}
 	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+	t.values[0] = formatTag(d.format0, v0)
 
-	return SetDef2[V1, V2]{
-		name: d.name,
+	return SetDef7[V1, V2, V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[7]string)(d.keys[1:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format1,
+		format1: d.format2,
+		format2: d.format3,
+		format3: d.format4,
+		format4: d.format5,
+		format5: d.format6,
+		format6: d.format7,
 
-		prefix:        t,
-		keys:          *((*[2]string)(d.keys[1:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		ok: d.ok,
 	}
 }
 
-// Prefix2 sets the value of the first 2 tags, returning a SetDef1 that
+// Prefix2 sets the value of the first 2 tags, returning a SetDef6 that
 // can be used to set the rest.
-func (d SetDef3[V0, V1, V2]) Prefix2(v0 V0, v1 V1) SetDef1[V2] {
+func (d SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix2(v0 V0, v1 V1) SetDef6[V2, V3, V4, V5, V6, V7] {
 	t := tags{n: 2}
 	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
 
-	return SetDef1[V2]{
-		name: d.name,
+	return SetDef6[V2, V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[6]string)(d.keys[2:])),
+		sampleRate: d.sampleRate,
 
-		prefix:        t,
-		keys:          *((*[1]string)(d.keys[2:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		format0: d.format2,
+		format1: d.format3,
+		format2: d.format4,
+		format3: d.format5,
+		format4: d.format6,
+		format5: d.format7,
+
+		ok: d.ok,
 	}
 }
 
-// SetDef4 is the definition of a set metric with 4 tag(s).
-type SetDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
-	name string
+// Prefix3 sets the value of the first 3 tags, returning a SetDef5 that
+// can be used to set the rest.
+func (d SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix3(v0 V0, v1 V1, v2 V2) SetDef5[V3, V4, V5, V6, V7] {
+	t := tags{n: 3}
+	copy(t.keys[:], d.keys[:3])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
 
-	prefix        tags
-	keys          [4]string
-	sampleRate    float64
-	allComparable bool
-	ok            bool
-}
+	return SetDef5[V3, V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
 
-// NewSetDef4 defines a set metric with 4 tag(s).
-//
-// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
-// (if main() has not yet started) or return an inert def that will not produce any data.
-func NewSetDef4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
-	name string,
-	description string,
-	unit Unit,
-	keys [4]string,
-	sampleRate float64,
-) SetDef4[V0, V1, V2, V3] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
-	var zero3 V3
+		prefix:     t,
+		keys:       *((*[5]string)(d.keys[3:])),
+		sampleRate: d.sampleRate,
 
-	ok := registerDef(
-		SetType,
-		name,
-		description,
-		unit,
-		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-			reflect.TypeOf(zero3),
-		},
-	)
-	return SetDef4[V0, V1, V2, V3]{
-		name: name,
+		format0: d.format3,
+		format1: d.format4,
+		format2: d.format5,
+		format3: d.format6,
+		format4: d.format7,
 
-		keys:       keys,
-		sampleRate: sampleRate,
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			reflect.TypeOf(zero3).Comparable() &&
-			true,
-		ok: ok,
+		ok: d.ok,
 	}
 }
 
-// Values returns a SetDef that has all of the given tag values bound. It can be passed to
-// Metrics.Set() to produce a metric to log data to.
-func (d SetDef4[V0, V1, V2, V3]) Values(v0 V0, v1 V1, v2 V2, v3 V3) SetDef {
+// Prefix4 sets the value of the first 4 tags, returning a SetDef4 that
+// can be used to set the rest.
+func (d SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) SetDef4[V4, V5, V6, V7] {
 	t := tags{n: 4}
-	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
+	copy(t.keys[:], d.keys[:4])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
 
-	return SetDef{
-		name: d.name,
+	return SetDef4[V4, V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
 
-		tags:          d.prefix.append(t),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+		prefix:     t,
+		keys:       *((*[4]string)(d.keys[4:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format4,
+		format1: d.format5,
+		format2: d.format6,
+		format3: d.format7,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a SetDef3 that
+// Prefix5 sets the value of the first 5 tags, returning a SetDef3 that
 // can be used to set the rest.
-func (d SetDef4[V0, V1, V2, V3]) Prefix1(v0 V0) SetDef3[V1, V2, V3] {
-	t := tags{n: 1}
-	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
-
-	return SetDef3[V1, V2, V3]{
-		name: d.name,
+func (d SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix5(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) SetDef3[V5, V6, V7] {
+	t := tags{n: 5}
+	copy(t.keys[:], d.keys[:5])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+
+	return SetDef3[V5, V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[3]string)(d.keys[5:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format5,
+		format1: d.format6,
+		format2: d.format7,
+
+		ok: d.ok,
+	}
+}
 
-		prefix:        t,
-		keys:          *((*[3]string)(d.keys[1:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+// Prefix6 sets the value of the first 6 tags, returning a SetDef2 that
+// can be used to set the rest.
+func (d SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix6(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5) SetDef2[V6, V7] {
+	t := tags{n: 6}
+	copy(t.keys[:], d.keys[:6])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+
+	return SetDef2[V6, V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[2]string)(d.keys[6:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format6,
+		format1: d.format7,
+
+		ok: d.ok,
 	}
 }
 
-// Prefix2 sets the value of the first 2 tags, returning a SetDef2 that
+// Prefix7 sets the value of the first 7 tags, returning a SetDef1 that
 // can be used to set the rest.
-func (d SetDef4[V0, V1, V2, V3]) Prefix2(v0 V0, v1 V1) SetDef2[V2, V3] {
-	t := tags{n: 2}
-	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+func (d SetDef8[V0, V1, V2, V3, V4, V5, V6, V7]) Prefix7(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6) SetDef1[V7] {
+	t := tags{n: 7}
+	copy(t.keys[:], d.keys[:7])
+	t.values[0] = formatTag(d.format0, v0)
+	t.values[1] = formatTag(d.format1, v1)
+	t.values[2] = formatTag(d.format2, v2)
+	t.values[3] = formatTag(d.format3, v3)
+	t.values[4] = formatTag(d.format4, v4)
+	t.values[5] = formatTag(d.format5, v5)
+	t.values[6] = formatTag(d.format6, v6)
+
+	return SetDef1[V7]{
+		name:         d.name,
+		previousName: d.previousName,
+
+		prefix:     t,
+		keys:       *((*[1]string)(d.keys[7:])),
+		sampleRate: d.sampleRate,
+
+		format0: d.format7,
+
+		ok: d.ok,
+	}
+}
 
-	return SetDef2[V2, V3]{
-		name: d.name,
+// GaugeGroup1 is a set of gauges from the same GaugeDef1 that are emitted together. Each
+// EmitAndUnset emits the gauges that were Set since the last one and unsets the rest, so the group
+// reports exactly the tag values that are currently Set, for example the number of items in each
+// state. It's safe to use concurrently.
+type GaugeGroup1[V0 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef1[V0]
+	inner gaugeGroup
+}
 
-		prefix:        t,
-		keys:          *((*[2]string)(d.keys[2:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+// NewGaugeGroup1 returns a GaugeGroup1 that reports to d.
+func NewGaugeGroup1[V0 TagValue](
+	m *Metrics,
+	d GaugeDef1[V0],
+) *GaugeGroup1[V0] {
+	return &GaugeGroup1[V0]{
+		m:     m,
+		d:     d,
+		inner: newGaugeGroup(),
 	}
 }
 
-// Prefix3 sets the value of the first 3 tags, returning a SetDef1 that
-// can be used to set the rest.
-func (d SetDef4[V0, V1, V2, V3]) Prefix3(v0 V0, v1 V1, v2 V2) SetDef1[V3] {
-	t := tags{n: 3}
-	copy(t.keys[:], d.keys[:3])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+// Set sets the gauge with the given tag values to value, to be emitted by the next EmitAndUnset.
+func (g *GaugeGroup1[V0]) Set(v0 V0, value float64) {
+	g.inner.set(g.m.Gauge(g.d.Values(v0)), value)
+}
 
-	return SetDef1[V3]{
-		name: d.name,
+// EmitAndUnset emits the gauges added using Set since the last EmitAndUnset, and unsets any tagsets
+// that were not Set since the last EmitAndUnset.
+func (g *GaugeGroup1[V0]) EmitAndUnset() { g.inner.EmitAndUnset() }
 
-		prefix:        t,
-		keys:          *((*[1]string)(d.keys[3:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
-	}
+// EmitEveryFlush calls EmitAndUnset before every flush using Metrics.EveryFlush, returning a
+// function that stops it.
+func (g *GaugeGroup1[V0]) EmitEveryFlush() func() {
+	return g.m.EveryFlush(g.EmitAndUnset)
 }
 
-// SetDef5 is the definition of a set metric with 5 tag(s).
-type SetDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
-	name string
+// GaugeGroup2 is a set of gauges from the same GaugeDef2 that are emitted together. Each
+// EmitAndUnset emits the gauges that were Set since the last one and unsets the rest, so the group
+// reports exactly the tag values that are currently Set, for example the number of items in each
+// state. It's safe to use concurrently.
+type GaugeGroup2[V0 TagValue, V1 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef2[V0, V1]
+	inner gaugeGroup
+}
 
-	prefix        tags
-	keys          [5]string
-	sampleRate    float64
-	allComparable bool
-	ok            bool
+// NewGaugeGroup2 returns a GaugeGroup2 that reports to d.
+func NewGaugeGroup2[V0 TagValue, V1 TagValue](
+	m *Metrics,
+	d GaugeDef2[V0, V1],
+) *GaugeGroup2[V0, V1] {
+	return &GaugeGroup2[V0, V1]{
+		m:     m,
+		d:     d,
+		inner: newGaugeGroup(),
+	}
 }
 
-// NewSetDef5 defines a set metric with 5 tag(s).
-//
-// It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
-// (if main() has not yet started) or return an inert def that will not produce any data.
-func NewSetDef5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
-	name string,
-	description string,
-	unit Unit,
-	keys [5]string,
-	sampleRate float64,
-) SetDef5[V0, V1, V2, V3, V4] {
-	var zero0 V0
-	var zero1 V1
-	var zero2 V2
-	var zero3 V3
-	var zero4 V4
+// Set sets the gauge with the given tag values to value, to be emitted by the next EmitAndUnset.
+func (g *GaugeGroup2[V0, V1]) Set(v0 V0, v1 V1, value float64) {
+	g.inner.set(g.m.Gauge(g.d.Values(v0, v1)), value)
+}
 
-	ok := registerDef(
-		SetType,
-		name,
-		description,
-		unit,
-		keys[:],
-		[]reflect.Type{
-			reflect.TypeOf(zero0),
-			reflect.TypeOf(zero1),
-			reflect.TypeOf(zero2),
-			reflect.TypeOf(zero3),
-			reflect.TypeOf(zero4),
-		},
-	)
-	return SetDef5[V0, V1, V2, V3, V4]{
-		name: name,
+// EmitAndUnset emits the gauges added using Set since the last EmitAndUnset, and unsets any tagsets
+// that were not Set since the last EmitAndUnset.
+func (g *GaugeGroup2[V0, V1]) EmitAndUnset() { g.inner.EmitAndUnset() }
 
-		keys:       keys,
-		sampleRate: sampleRate,
-		allComparable: reflect.TypeOf(zero0).Comparable() &&
-			reflect.TypeOf(zero1).Comparable() &&
-			reflect.TypeOf(zero2).Comparable() &&
-			reflect.TypeOf(zero3).Comparable() &&
-			reflect.TypeOf(zero4).Comparable() &&
-			true,
-		ok: ok,
+// EmitEveryFlush calls EmitAndUnset before every flush using Metrics.EveryFlush, returning a
+// function that stops it.
+func (g *GaugeGroup2[V0, V1]) EmitEveryFlush() func() {
+	return g.m.EveryFlush(g.EmitAndUnset)
+}
+
+// GaugeGroup3 is a set of gauges from the same GaugeDef3 that are emitted together. Each
+// EmitAndUnset emits the gauges that were Set since the last one and unsets the rest, so the group
+// reports exactly the tag values that are currently Set, for example the number of items in each
+// state. It's safe to use concurrently.
+type GaugeGroup3[V0 TagValue, V1 TagValue, V2 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef3[V0, V1, V2]
+	inner gaugeGroup
+}
+
+// NewGaugeGroup3 returns a GaugeGroup3 that reports to d.
+func NewGaugeGroup3[V0 TagValue, V1 TagValue, V2 TagValue](
+	m *Metrics,
+	d GaugeDef3[V0, V1, V2],
+) *GaugeGroup3[V0, V1, V2] {
+	return &GaugeGroup3[V0, V1, V2]{
+		m:     m,
+		d:     d,
+		inner: newGaugeGroup(),
 	}
 }
 
-// Values returns a SetDef that has all of the given tag values bound. It can be passed to
-// Metrics.Set() to produce a metric to log data to.
-func (d SetDef5[V0, V1, V2, V3, V4]) Values(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4) SetDef {
-	t := tags{n: 5}
-	copy(t.keys[:], d.keys[:])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
-	t.values[4] = v4
+// Set sets the gauge with the given tag values to value, to be emitted by the next EmitAndUnset.
+func (g *GaugeGroup3[V0, V1, V2]) Set(v0 V0, v1 V1, v2 V2, value float64) {
+	g.inner.set(g.m.Gauge(g.d.Values(v0, v1, v2)), value)
+}
 
-	return SetDef{
-		name: d.name,
+// EmitAndUnset emits the gauges added using Set since the last EmitAndUnset, and unsets any tagsets
+// that were not Set since the last EmitAndUnset.
+func (g *GaugeGroup3[V0, V1, V2]) EmitAndUnset() { g.inner.EmitAndUnset() }
+
+// EmitEveryFlush calls EmitAndUnset before every flush using Metrics.EveryFlush, returning a
+// function that stops it.
+func (g *GaugeGroup3[V0, V1, V2]) EmitEveryFlush() func() {
+	return g.m.EveryFlush(g.EmitAndUnset)
+}
+
+// GaugeGroup4 is a set of gauges from the same GaugeDef4 that are emitted together. Each
+// EmitAndUnset emits the gauges that were Set since the last one and unsets the rest, so the group
+// reports exactly the tag values that are currently Set, for example the number of items in each
+// state. It's safe to use concurrently.
+type GaugeGroup4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef4[V0, V1, V2, V3]
+	inner gaugeGroup
+}
 
-		tags:          d.prefix.append(t),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+// NewGaugeGroup4 returns a GaugeGroup4 that reports to d.
+func NewGaugeGroup4[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue](
+	m *Metrics,
+	d GaugeDef4[V0, V1, V2, V3],
+) *GaugeGroup4[V0, V1, V2, V3] {
+	return &GaugeGroup4[V0, V1, V2, V3]{
+		m:     m,
+		d:     d,
+		inner: newGaugeGroup(),
 	}
 }
 
-// Prefix1 sets the value of the first 1 tags, returning a SetDef4 that
-// can be used to set the rest.
-func (d SetDef5[V0, V1, V2, V3, V4]) Prefix1(v0 V0) SetDef4[V1, V2, V3, V4] {
-	t := tags{n: 1}
-	copy(t.keys[:], d.keys[:1])
-	t.values[0] = v0
+// Set sets the gauge with the given tag values to value, to be emitted by the next EmitAndUnset.
+func (g *GaugeGroup4[V0, V1, V2, V3]) Set(v0 V0, v1 V1, v2 V2, v3 V3, value float64) {
+	g.inner.set(g.m.Gauge(g.d.Values(v0, v1, v2, v3)), value)
+}
 
-	return SetDef4[V1, V2, V3, V4]{
-		name: d.name,
+// EmitAndUnset emits the gauges added using Set since the last EmitAndUnset, and unsets any tagsets
+// that were not Set since the last EmitAndUnset.
+func (g *GaugeGroup4[V0, V1, V2, V3]) EmitAndUnset() { g.inner.EmitAndUnset() }
+
+// EmitEveryFlush calls EmitAndUnset before every flush using Metrics.EveryFlush, returning a
+// function that stops it.
+func (g *GaugeGroup4[V0, V1, V2, V3]) EmitEveryFlush() func() {
+	return g.m.EveryFlush(g.EmitAndUnset)
+}
 
-		prefix:        t,
-		keys:          *((*[4]string)(d.keys[1:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+// GaugeGroup5 is a set of gauges from the same GaugeDef5 that are emitted together. Each
+// EmitAndUnset emits the gauges that were Set since the last one and unsets the rest, so the group
+// reports exactly the tag values that are currently Set, for example the number of items in each
+// state. It's safe to use concurrently.
+type GaugeGroup5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef5[V0, V1, V2, V3, V4]
+	inner gaugeGroup
+}
+
+// NewGaugeGroup5 returns a GaugeGroup5 that reports to d.
+func NewGaugeGroup5[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue](
+	m *Metrics,
+	d GaugeDef5[V0, V1, V2, V3, V4],
+) *GaugeGroup5[V0, V1, V2, V3, V4] {
+	return &GaugeGroup5[V0, V1, V2, V3, V4]{
+		m:     m,
+		d:     d,
+		inner: newGaugeGroup(),
 	}
 }
 
-// Prefix2 sets the value of the first 2 tags, returning a SetDef3 that
-// can be used to set the rest.
-func (d SetDef5[V0, V1, V2, V3, V4]) Prefix2(v0 V0, v1 V1) SetDef3[V2, V3, V4] {
-	t := tags{n: 2}
-	copy(t.keys[:], d.keys[:2])
-	t.values[0] = v0
-	t.values[1] = v1
+// Set sets the gauge with the given tag values to value, to be emitted by the next EmitAndUnset.
+func (g *GaugeGroup5[V0, V1, V2, V3, V4]) Set(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, value float64) {
+	g.inner.set(g.m.Gauge(g.d.Values(v0, v1, v2, v3, v4)), value)
+}
 
-	return SetDef3[V2, V3, V4]{
-		name: d.name,
+// EmitAndUnset emits the gauges added using Set since the last EmitAndUnset, and unsets any tagsets
+// that were not Set since the last EmitAndUnset.
+func (g *GaugeGroup5[V0, V1, V2, V3, V4]) EmitAndUnset() { g.inner.EmitAndUnset() }
+
+// EmitEveryFlush calls EmitAndUnset before every flush using Metrics.EveryFlush, returning a
+// function that stops it.
+func (g *GaugeGroup5[V0, V1, V2, V3, V4]) EmitEveryFlush() func() {
+	return g.m.EveryFlush(g.EmitAndUnset)
+}
+
+// GaugeGroup6 is a set of gauges from the same GaugeDef6 that are emitted together. Each
+// EmitAndUnset emits the gauges that were Set since the last one and unsets the rest, so the group
+// reports exactly the tag values that are currently Set, for example the number of items in each
+// state. It's safe to use concurrently.
+type GaugeGroup6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef6[V0, V1, V2, V3, V4, V5]
+	inner gaugeGroup
+}
 
-		prefix:        t,
-		keys:          *((*[3]string)(d.keys[2:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+// NewGaugeGroup6 returns a GaugeGroup6 that reports to d.
+func NewGaugeGroup6[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue](
+	m *Metrics,
+	d GaugeDef6[V0, V1, V2, V3, V4, V5],
+) *GaugeGroup6[V0, V1, V2, V3, V4, V5] {
+	return &GaugeGroup6[V0, V1, V2, V3, V4, V5]{
+		m:     m,
+		d:     d,
+		inner: newGaugeGroup(),
 	}
 }
 
-// Prefix3 sets the value of the first 3 tags, returning a SetDef2 that
-// can be used to set the rest.
-func (d SetDef5[V0, V1, V2, V3, V4]) Prefix3(v0 V0, v1 V1, v2 V2) SetDef2[V3, V4] {
-	t := tags{n: 3}
-	copy(t.keys[:], d.keys[:3])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
+// Set sets the gauge with the given tag values to value, to be emitted by the next EmitAndUnset.
+func (g *GaugeGroup6[V0, V1, V2, V3, V4, V5]) Set(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, value float64) {
+	g.inner.set(g.m.Gauge(g.d.Values(v0, v1, v2, v3, v4, v5)), value)
+}
 
-	return SetDef2[V3, V4]{
-		name: d.name,
+// EmitAndUnset emits the gauges added using Set since the last EmitAndUnset, and unsets any tagsets
+// that were not Set since the last EmitAndUnset.
+func (g *GaugeGroup6[V0, V1, V2, V3, V4, V5]) EmitAndUnset() { g.inner.EmitAndUnset() }
+
+// EmitEveryFlush calls EmitAndUnset before every flush using Metrics.EveryFlush, returning a
+// function that stops it.
+func (g *GaugeGroup6[V0, V1, V2, V3, V4, V5]) EmitEveryFlush() func() {
+	return g.m.EveryFlush(g.EmitAndUnset)
+}
+
+// GaugeGroup7 is a set of gauges from the same GaugeDef7 that are emitted together. Each
+// EmitAndUnset emits the gauges that were Set since the last one and unsets the rest, so the group
+// reports exactly the tag values that are currently Set, for example the number of items in each
+// state. It's safe to use concurrently.
+type GaugeGroup7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef7[V0, V1, V2, V3, V4, V5, V6]
+	inner gaugeGroup
+}
 
-		prefix:        t,
-		keys:          *((*[2]string)(d.keys[3:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+// NewGaugeGroup7 returns a GaugeGroup7 that reports to d.
+func NewGaugeGroup7[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue](
+	m *Metrics,
+	d GaugeDef7[V0, V1, V2, V3, V4, V5, V6],
+) *GaugeGroup7[V0, V1, V2, V3, V4, V5, V6] {
+	return &GaugeGroup7[V0, V1, V2, V3, V4, V5, V6]{
+		m:     m,
+		d:     d,
+		inner: newGaugeGroup(),
 	}
 }
 
-// Prefix4 sets the value of the first 4 tags, returning a SetDef1 that
-// can be used to set the rest.
-func (d SetDef5[V0, V1, V2, V3, V4]) Prefix4(v0 V0, v1 V1, v2 V2, v3 V3) SetDef1[V4] {
-	t := tags{n: 4}
-	copy(t.keys[:], d.keys[:4])
-	t.values[0] = v0
-	t.values[1] = v1
-	t.values[2] = v2
-	t.values[3] = v3
+// Set sets the gauge with the given tag values to value, to be emitted by the next EmitAndUnset.
+func (g *GaugeGroup7[V0, V1, V2, V3, V4, V5, V6]) Set(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6, value float64) {
+	g.inner.set(g.m.Gauge(g.d.Values(v0, v1, v2, v3, v4, v5, v6)), value)
+}
 
-	return SetDef1[V4]{
-		name: d.name,
+// EmitAndUnset emits the gauges added using Set since the last EmitAndUnset, and unsets any tagsets
+// that were not Set since the last EmitAndUnset.
+func (g *GaugeGroup7[V0, V1, V2, V3, V4, V5, V6]) EmitAndUnset() { g.inner.EmitAndUnset() }
+
+// EmitEveryFlush calls EmitAndUnset before every flush using Metrics.EveryFlush, returning a
+// function that stops it.
+func (g *GaugeGroup7[V0, V1, V2, V3, V4, V5, V6]) EmitEveryFlush() func() {
+	return g.m.EveryFlush(g.EmitAndUnset)
+}
+
+// GaugeGroup8 is a set of gauges from the same GaugeDef8 that are emitted together. Each
+// EmitAndUnset emits the gauges that were Set since the last one and unsets the rest, so the group
+// reports exactly the tag values that are currently Set, for example the number of items in each
+// state. It's safe to use concurrently.
+type GaugeGroup8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue] struct {
+	m     *Metrics
+	d     GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7]
+	inner gaugeGroup
+}
 
-		prefix:        t,
-		keys:          *((*[1]string)(d.keys[4:])),
-		sampleRate:    d.sampleRate,
-		allComparable: d.allComparable,
-		ok:            d.ok,
+// NewGaugeGroup8 returns a GaugeGroup8 that reports to d.
+func NewGaugeGroup8[V0 TagValue, V1 TagValue, V2 TagValue, V3 TagValue, V4 TagValue, V5 TagValue, V6 TagValue, V7 TagValue](
+	m *Metrics,
+	d GaugeDef8[V0, V1, V2, V3, V4, V5, V6, V7],
+) *GaugeGroup8[V0, V1, V2, V3, V4, V5, V6, V7] {
+	return &GaugeGroup8[V0, V1, V2, V3, V4, V5, V6, V7]{
+		m:     m,
+		d:     d,
+		inner: newGaugeGroup(),
 	}
 }
+
+// Set sets the gauge with the given tag values to value, to be emitted by the next EmitAndUnset.
+func (g *GaugeGroup8[V0, V1, V2, V3, V4, V5, V6, V7]) Set(v0 V0, v1 V1, v2 V2, v3 V3, v4 V4, v5 V5, v6 V6, v7 V7, value float64) {
+	g.inner.set(g.m.Gauge(g.d.Values(v0, v1, v2, v3, v4, v5, v6, v7)), value)
+}
+
+// EmitAndUnset emits the gauges added using Set since the last EmitAndUnset, and unsets any tagsets
+// that were not Set since the last EmitAndUnset.
+func (g *GaugeGroup8[V0, V1, V2, V3, V4, V5, V6, V7]) EmitAndUnset() { g.inner.EmitAndUnset() }
+
+// EmitEveryFlush calls EmitAndUnset before every flush using Metrics.EveryFlush, returning a
+// function that stops it.
+func (g *GaugeGroup8[V0, V1, V2, V3, V4, V5, V6, V7]) EmitEveryFlush() func() {
+	return g.m.EveryFlush(g.EmitAndUnset)
+}
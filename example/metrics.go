@@ -9,6 +9,12 @@ var (
 		"runs",
 		"logged every time this process is started",
 		metrics.UnitRun,
+		// Optional metadata, which is included in metrics.DumpDefs() and synced by
+		// metrics-sync-metadata.
+		metrics.WithOwner("example-team"),
+		metrics.WithRunbook("https://example.com/runbooks/runs"),
+		metrics.WithSLO("example-availability"),
+		metrics.WithStability(metrics.StabilityStable),
 	)
 
 	runningGaugeDef = metrics.NewGaugeDef(
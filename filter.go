@@ -0,0 +1,311 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// How often the file given to WithFilterFile is checked for changes. A var for testing.
+var filterFilePollInterval = 5 * time.Second
+
+// FilterRule matches series by name and, optionally, by tag values. Exactly one of Name, Prefix,
+// and Glob must be set.
+//
+// A series matches if either its name or its previous name (see WithPreviousName) matches, and it
+// has every tag in Tags.
+type FilterRule struct {
+	// Matches exactly this metric name.
+	Name string `json:"name,omitempty"`
+	// Matches every metric name that starts with this prefix.
+	Prefix string `json:"prefix,omitempty"`
+	// Matches metric names using the syntax of path.Match, for example "rpc.*.latency".
+	Glob string `json:"glob,omitempty"`
+	// key:value tags that the series must all have to match, for example "method:get". Values are
+	// compared after sanitizing, so they should be written as they appear in DataDog.
+	Tags []string `json:"tags,omitempty"`
+}
+
+func (r FilterRule) validate() error {
+	n := 0
+	for _, s := range []string{r.Name, r.Prefix, r.Glob} {
+		if s != "" {
+			n++
+		}
+	}
+	if n != 1 {
+		return errors.New("exactly one of name, prefix, and glob must be set")
+	}
+	if r.Glob != "" {
+		_, err := path.Match(r.Glob, "")
+		if err != nil {
+			return fmt.Errorf("glob %q: %w", r.Glob, err)
+		}
+	}
+	for _, tag := range r.Tags {
+		if !strings.Contains(tag, ":") {
+			return fmt.Errorf("tag %q is not in key:value form", tag)
+		}
+	}
+	return nil
+}
+
+func (r FilterRule) matches(name string, previousName string, tags []string) bool {
+	if !r.matchesName(name) && (previousName == "" || !r.matchesName(previousName)) {
+		return false
+	}
+	for _, tag := range r.Tags {
+		found := false
+		for _, seriesTag := range tags {
+			if seriesTag == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (r FilterRule) matchesName(name string) bool {
+	switch {
+	case r.Name != "":
+		return name == r.Name
+	case r.Prefix != "":
+		return strings.HasPrefix(name, r.Prefix)
+	default:
+		ok, _ := path.Match(r.Glob, name)
+		return ok
+	}
+}
+
+// FilterConfig is the set of rules for a Filter. A series is not published if it matches any of
+// Deny and none of Allow, so Allow can make exceptions to broad Deny rules.
+//
+// It's also the format of the file given to WithFilterFile, as JSON, for example:
+//
+//	{
+//	  "deny": [
+//	    {"prefix": "rpc."},
+//	    {"name": "cache_hits", "tags": ["cache:sessions"]}
+//	  ],
+//	  "allow": [
+//	    {"glob": "rpc.*.errors"}
+//	  ]
+//	}
+type FilterConfig struct {
+	Deny  []FilterRule `json:"deny,omitempty"`
+	Allow []FilterRule `json:"allow,omitempty"`
+}
+
+// Filter disables publishing for the series matching its rules, for example to stop a single metric
+// from flooding the agent without shipping a code change. See FilterConfig, Metrics.SetFilter, and
+// WithFilterFile.
+//
+// The metrics reported by this package itself, such as metrics.filtered_series, are never filtered.
+type Filter struct {
+	config FilterConfig
+}
+
+// The names of the metrics reported by this package itself, which filters never apply to.
+var internalNames = map[string]bool{
+	badDefsDef.name:                true,
+	filterRulesDef.name:            true,
+	filteredSeriesDef.name:         true,
+	filterFileLoadFailuresDef.name: true,
+	buildInfoDef.name:              true,
+}
+
+// NewFilter returns a Filter with the given rules, or an error if any of them are invalid.
+func NewFilter(config FilterConfig) (*Filter, error) {
+	for i, rule := range config.Deny {
+		err := rule.validate()
+		if err != nil {
+			return nil, fmt.Errorf("deny rule %d: %w", i, err)
+		}
+	}
+	for i, rule := range config.Allow {
+		err := rule.validate()
+		if err != nil {
+			return nil, fmt.Errorf("allow rule %d: %w", i, err)
+		}
+	}
+	return &Filter{config: config}, nil
+}
+
+func (f *Filter) denies(name string, previousName string, tags []string) bool {
+	if internalNames[name] {
+		return false
+	}
+	denied := false
+	for _, rule := range f.config.Deny {
+		if rule.matches(name, previousName, tags) {
+			denied = true
+			break
+		}
+	}
+	if !denied {
+		return false
+	}
+	for _, rule := range f.config.Allow {
+		if rule.matches(name, previousName, tags) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetFilter replaces the filter used by m, taking effect for all series including ones that already
+// exist. A nil filter publishes everything.
+func (m *Metrics) SetFilter(f *Filter) {
+	m.filter.Store(f)
+}
+
+// WithFilter sets the initial filter for the Metrics, see Metrics.SetFilter.
+func WithFilter(f *Filter) Option {
+	return func(m *Metrics) {
+		m.filter.Store(f)
+	}
+}
+
+// WithFilterFile makes the Metrics load its filter from the FilterConfig in the JSON file at path,
+// and reload it whenever the file changes, see Metrics.SetFilter. If the file doesn't exist,
+// nothing is filtered. If the file can't be read or is invalid, the previous filter stays in effect
+// and metrics.filter_file_load_failures is incremented.
+func WithFilterFile(path string) Option {
+	return func(m *Metrics) {
+		m.filterFile = path
+	}
+}
+
+// readFilterFile returns the Filter in the file at path, or nil if there isn't one.
+func readFilterFile(path string) (*Filter, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var config FilterConfig
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&config)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	f, err := NewFilter(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// watchFilterFile loads m.filterFile now and again each time it changes.
+func (m *Metrics) watchFilterFile(loadFailures *Counter) {
+	type version struct {
+		exists  bool
+		modTime time.Time
+		size    int64
+	}
+	stat := func() version {
+		info, err := os.Stat(m.filterFile)
+		if err != nil {
+			return version{}
+		}
+		return version{exists: true, modTime: info.ModTime(), size: info.Size()}
+	}
+	load := func() {
+		f, err := readFilterFile(m.filterFile)
+		if err != nil {
+			loadFailures.Add(1)
+			return
+		}
+		m.SetFilter(f)
+	}
+
+	last := stat()
+	load()
+	m.bg.Periodic(filterFilePollInterval, 0 /*jitter*/, func(ctx context.Context) {
+		v := stat()
+		if v == last {
+			return
+		}
+		last = v
+		load()
+	})
+}
+
+// filterState caches whether a series is filtered, so that checking only needs to evaluate the
+// rules again after the filter changes.
+type filterState struct {
+	last atomic.Pointer[filterDecision]
+}
+
+type filterDecision struct {
+	f      *Filter
+	denied bool
+}
+
+func (s *filterState) denied(m *Metrics, name string, previousName string, tags []string) bool {
+	f := m.filter.Load()
+	if f == nil {
+		return false
+	}
+	last := s.last.Load()
+	if last != nil && last.f == f {
+		return last.denied
+	}
+	denied := f.denies(name, previousName, tags)
+	s.last.Store(&filterDecision{f: f, denied: denied})
+	return denied
+}
+
+// reportFilter updates the gauges that describe m's current filter.
+func (m *Metrics) reportFilter(denyRules *Gauge, allowRules *Gauge, filteredSeries *Gauge) {
+	f := m.filter.Load()
+	if f == nil {
+		denyRules.Set(0)
+		allowRules.Set(0)
+		filteredSeries.Set(0)
+		return
+	}
+	denyRules.Set(float64(len(f.config.Deny)))
+	allowRules.Set(float64(len(f.config.Allow)))
+
+	n := 0
+	m.gauges.Range(func(_ metricKey, g *Gauge) bool {
+		if g.filter.denied(m, g.name, g.previousName, g.tags) {
+			n++
+		}
+		return true
+	})
+	m.counters.Range(func(_ metricKey, c *Counter) bool {
+		if c.filter.denied(m, c.name, c.previousName, c.tags) {
+			n++
+		}
+		return true
+	})
+	m.distributions.Range(func(_ metricKey, d *Distribution) bool {
+		if d.filter.denied(m, d.name, d.previousName, d.tags) {
+			n++
+		}
+		return true
+	})
+	m.sets.Range(func(_ metricKey, s *Set) bool {
+		if s.filter.denied(m, s.name, s.previousName, s.tags) {
+			n++
+		}
+		return true
+	})
+	filteredSeries.Set(float64(n))
+}
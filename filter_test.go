@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFilter(t *testing.T) {
+	_, err := NewFilter(FilterConfig{Deny: []FilterRule{{Name: "a", Prefix: "b"}}})
+	if err == nil {
+		t.Fatal("expected error for rule with both name and prefix")
+	}
+	_, err = NewFilter(FilterConfig{Deny: []FilterRule{{Glob: "[a"}}})
+	if err == nil {
+		t.Fatal("expected error for bad glob")
+	}
+	_, err = NewFilter(FilterConfig{Deny: []FilterRule{{Name: "a", Tags: []string{"b"}}}})
+	if err == nil {
+		t.Fatal("expected error for tag without value")
+	}
+
+	f, err := NewFilter(FilterConfig{
+		Deny: []FilterRule{
+			{Prefix: "test_filter."},
+			{Glob: "test_glob_*", Tags: []string{"k:denied"}},
+			// Only this package's own metrics are exempt, not everything that looks like them.
+			{Prefix: "metrics."},
+		},
+		Allow: []FilterRule{
+			{Name: "test_filter.allowed"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := newCapturingPublisher()
+	m := New(p, WithFilter(f))
+	counterDef := func(name string, value string) CounterDef {
+		return CounterDef1[string]{name: name, keys: [...]string{"k"}, ok: true}.Values(value)
+	}
+	m.Counter(counterDef("test_filter.denied", "a")).Add(1)
+	m.Counter(counterDef("test_filter.allowed", "a")).Add(1)
+	m.Counter(counterDef("test_glob_x", "denied")).Add(1)
+	m.Counter(counterDef("test_glob_x", "allowed")).Add(1)
+	m.Distribution(DistributionDef{name: "test_filter.distribution", ok: true}).Observe(1)
+	m.Counter(counterDef("metrics.test_filter", "a")).Add(1)
+	m.Flush()
+
+	expectCount := func(name string, tag string, expected int64) {
+		t.Helper()
+		if n := p.countSeen(name, []string{tag}); n != expected {
+			t.Fatalf("expected %s %s to be %d, got %d", name, tag, expected, n)
+		}
+	}
+	expectCount("test_filter.denied", "k:a", 0)
+	expectCount("test_filter.allowed", "k:a", 1)
+	expectCount("test_glob_x", "k:denied", 0)
+	expectCount("test_glob_x", "k:allowed", 1)
+	expectCount("metrics.test_filter", "k:a", 0)
+	if vs := p.distributionSeen("test_filter.distribution", nil); len(vs) != 0 {
+		t.Fatalf("expected no distribution values, got %v", vs)
+	}
+	if v, _ := p.takeGauge("metrics.filtered_series", nil); v != 4 {
+		t.Fatalf("expected 4 filtered series, got %v", v)
+	}
+	if v, _ := p.takeGauge("metrics.filter_rules", []string{"action:deny"}); v != 3 {
+		t.Fatalf("expected 3 deny rules, got %v", v)
+	}
+
+	// Removing the filter applies to series that already exist.
+	m.SetFilter(nil)
+	m.Counter(counterDef("test_filter.denied", "a")).Add(1)
+	m.Flush()
+	expectCount("test_filter.denied", "k:a", 1)
+	if v, _ := p.takeGauge("metrics.filtered_series", nil); v != 0 {
+		t.Fatalf("expected 0 filtered series, got %v", v)
+	}
+}
+
+func TestFilterFile(t *testing.T) {
+	defer func(prev time.Duration) { filterFilePollInterval = prev }(filterFilePollInterval)
+	filterFilePollInterval = 10 * time.Millisecond
+
+	path := filepath.Join(t.TempDir(), "filter.json")
+	err := os.WriteFile(path, []byte(`{"deny": [{"name": "test_filter_file"}]}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := newCapturingPublisher()
+	m := New(p, WithFilterFile(path))
+	defer m.Close()
+	c := m.Counter(CounterDef{name: "test_filter_file", ok: true})
+	c.Add(1)
+	m.Flush()
+	if n := p.countSeen("test_filter_file", nil); n != 0 {
+		t.Fatalf("expected counter to be filtered, got %d", n)
+	}
+
+	// An invalid file leaves the previous filter in place.
+	err = os.WriteFile(path, []byte(`{"deny": [{}]}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for p.countSeen("metrics.filter_file_load_failures", nil) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the invalid filter file to be loaded")
+		}
+		time.Sleep(filterFilePollInterval)
+		m.Flush()
+	}
+	c.Add(1)
+	m.Flush()
+	if n := p.countSeen("test_filter_file", nil); n != 0 {
+		t.Fatalf("expected counter to still be filtered, got %d", n)
+	}
+
+	// Removing the file removes the filter.
+	err = os.Remove(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline = time.Now().Add(10 * time.Second)
+	for p.countSeen("test_filter_file", nil) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the filter file's removal to be noticed")
+		}
+		time.Sleep(filterFilePollInterval)
+		c.Add(1)
+		m.Flush()
+	}
+}
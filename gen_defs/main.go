@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
@@ -8,7 +9,11 @@ import (
 )
 
 func main() {
-	n := 6
+	// Each def type with more tags adds to the size of the package, and metric keys for defs with
+	// more tags than smallKeyTags in metrics.go allocate, so only go as high as is actually needed.
+	maxTags := flag.Int("max-tags", 8, "the maximum number of tags a metric can have")
+	flag.Parse()
+	n := *maxTags + 1
 
 	fmt.Println("package metrics")
 	fmt.Println()
@@ -19,12 +24,14 @@ func main() {
 	fmt.Printf("const maxTags = %d\n", n-1)
 
 	type vars struct {
-		N           int
-		Ns          []int
-		Metric      string
-		MetricLower string
-		SampleRate  bool
-		Unit        bool
+		N              int
+		Ns             []int
+		Metric         string
+		MetricLower    string
+		SampleRate     bool
+		Unit           bool
+		GaugeOptions   bool
+		CounterOptions bool
 	}
 
 	ns := make([]int, n)
@@ -33,66 +40,119 @@ func main() {
 	}
 
 	type metricOpts struct {
-		Name       string
-		Unit       bool
-		SampleRate bool
+		Name           string
+		Unit           bool
+		SampleRate     bool
+		GaugeOptions   bool
+		CounterOptions bool
+	}
+
+	for i := 1; i < n; i++ {
+		err := valueTypesTmpl.Execute(os.Stdout, vars{N: i, Ns: ns[:i]})
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	for _, metric := range []metricOpts{
-		{Name: "Counter", SampleRate: false, Unit: false},
-		{Name: "Gauge", SampleRate: false, Unit: false},
+		{Name: "Counter", SampleRate: false, Unit: false, CounterOptions: true},
+		{Name: "Gauge", SampleRate: false, Unit: false, GaugeOptions: true},
 		{Name: "Distribution", SampleRate: true, Unit: true},
 		{Name: "Set", SampleRate: true, Unit: false},
 	} {
 		for i := 1; i < n; i++ {
 			err := metricTmpl.Execute(os.Stdout, vars{
-				N:           i,
-				Ns:          ns[:i],
-				Metric:      metric.Name,
-				MetricLower: strings.ToLower(metric.Name),
-				SampleRate:  metric.SampleRate,
-				Unit:        metric.Unit,
+				N:              i,
+				Ns:             ns[:i],
+				Metric:         metric.Name,
+				MetricLower:    strings.ToLower(metric.Name),
+				SampleRate:     metric.SampleRate,
+				Unit:           metric.Unit,
+				GaugeOptions:   metric.GaugeOptions,
+				CounterOptions: metric.CounterOptions,
 			})
 			if err != nil {
 				panic(err)
 			}
 
 			for k := 1; k <= i-1; k++ {
+				type shifted struct {
+					New int
+					Old int
+				}
+				var rest []shifted
+				for j := k; j < i; j++ {
+					rest = append(rest, shifted{New: j - k, Old: j})
+				}
 				bindPrefixTmpl.Execute(os.Stdout, struct {
-					N          int
-					Ns         []int
-					K          int
-					Ks         []int
-					NMinusK    int
-					NMinusKs   []int
-					Metric     string
-					SampleRate bool
-					Unit       bool
+					N              int
+					Ns             []int
+					K              int
+					Ks             []int
+					NMinusK        int
+					NMinusKs       []int
+					Rest           []shifted
+					Metric         string
+					SampleRate     bool
+					Unit           bool
+					GaugeOptions   bool
+					CounterOptions bool
 				}{
-					N:          i,
-					Ns:         ns[:i],
-					K:          k,
-					Ks:         ns[:k],
-					NMinusK:    i - k,
-					NMinusKs:   ns[k:i],
-					Metric:     metric.Name,
-					SampleRate: metric.SampleRate,
-					Unit:       metric.Unit,
+					N:              i,
+					Ns:             ns[:i],
+					K:              k,
+					Ks:             ns[:k],
+					NMinusK:        i - k,
+					NMinusKs:       ns[k:i],
+					Rest:           rest,
+					Metric:         metric.Name,
+					SampleRate:     metric.SampleRate,
+					Unit:           metric.Unit,
+					GaugeOptions:   metric.GaugeOptions,
+					CounterOptions: metric.CounterOptions,
 				})
 			}
 		}
 	}
+	for i := 1; i < n; i++ {
+		err := gaugeGroupTmpl.Execute(os.Stdout, vars{N: i, Ns: ns[:i]})
+		if err != nil {
+			panic(err)
+		}
+	}
 }
 
+var valueTypesTmpl = template.Must(template.New("name").Parse(`
+// valueTypes{{.N}} returns the types of the tag values of a def with {{.N}} tag(s), for Metadata.
+func valueTypes{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}]() []reflect.Type {
+	{{range .Ns}}var zero{{.}} V{{.}}
+	{{ end }}
+	return []reflect.Type{
+		{{range .Ns}}reflect.TypeOf(zero{{.}}),
+		{{ end }}
+	}
+}
+// tagValues{{.N}} holds the tag values of a def with {{.N}} tag(s), for use as a map key.
+type tagValues{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}] struct {
+	{{range .Ns}}v{{.}} V{{.}}
+	{{end}}
+}
+`))
+
 var metricTmpl = template.Must(template.New("name").Parse(`
 // {{.Metric}}Def{{.N}} is the definition of a {{.MetricLower}} metric with {{.N}} tag(s).
 type {{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}] struct {
 	name       string
+	previousName string
 	{{if .Unit}} unit Unit {{end}}
 	prefix     tags
 	keys       [{{.N}}]string
 	{{if .SampleRate}} sampleRate float64 {{end}}
-	allComparable bool
+	{{if .GaugeOptions}} aggregation GaugeAggregation
+	onlyOnChange bool {{end}}
+	{{if .CounterOptions}} sharded bool {{end}}
+	{{range .Ns}}format{{.}} func(V{{.}}) string
+	{{end}}
 	ok            bool
 }
 
@@ -100,33 +160,91 @@ type {{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}] struct {
 //
 // It must be called from a top-level var block in a file called metrics.go, otherwise it will panic
 // (if main() has not yet started) or return an inert def that will not produce any data.
+//
+// opts can be used for optional settings, see DefOption.
 func New{{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}](
 	name string,
 	description string,
 	unit Unit,
 	keys [{{.N}}]string,
 	{{if .SampleRate}} sampleRate float64, {{end}}
+	opts ...DefOption,
 ) {{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}] {
-	{{range .Ns}}var zero{{.}} V{{.}}
-	{{ end }}
+	o := makeDefOptions(opts)
 	ok := registerDef(
 		{{.Metric}}Type,
 		name,
 		description,
 		unit,
 		keys[:],
-		[]reflect.Type{
-			{{range .Ns}}reflect.TypeOf(zero{{.}}),
-			{{ end }}
-		},
+		valueTypes{{.N}}[{{range .Ns}} V{{.}}, {{end}}](),
+		o,
+	)
+	return make{{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}](
+		name,
+		{{if .Unit}}unit,{{end}}
+		keys,
+		{{if .SampleRate}}sampleRate,{{end}}
+		o,
+		ok,
 	)
+}
+
+// Register{{.Metric}}Def{{.N}} defines a {{.MetricLower}} metric with {{.N}} tag(s) in r, returning an
+// error if the definition is invalid. Unlike New{{.Metric}}Def{{.N}}, it can be called at any time.
+// See Registry.
+func Register{{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}](
+	r *Registry,
+	name string,
+	description string,
+	unit Unit,
+	keys [{{.N}}]string,
+	{{if .SampleRate}} sampleRate float64, {{end}}
+	opts ...DefOption,
+) ({{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}], error) {
+	o := makeDefOptions(opts)
+	o.registry = r
+	err := r.register(
+		{{.Metric}}Type,
+		name,
+		description,
+		unit,
+		keys[:],
+		valueTypes{{.N}}[{{range .Ns}} V{{.}}, {{end}}](),
+		o,
+	)
+	if err != nil {
+		return {{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}]{}, err
+	}
+	return make{{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}](
+		name,
+		{{if .Unit}}unit,{{end}}
+		keys,
+		{{if .SampleRate}}sampleRate,{{end}}
+		o,
+		true,
+	), nil
+}
+
+func make{{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}](
+	name string,
+	{{if .Unit}}unit Unit,{{end}}
+	keys [{{.N}}]string,
+	{{if .SampleRate}}sampleRate float64,{{end}}
+	o defOptions,
+	ok bool,
+) {{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}] {
 	return {{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}]{
-		name:       name,
+		name:       o.registry.publishedName(name),
+		previousName: o.previousName,
 		{{if .Unit}}unit: unit,{{end}}
 		keys:       keys,
 		{{if .SampleRate}}sampleRate: sampleRate,{{end}}
-		allComparable: {{range .Ns}}reflect.TypeOf(zero{{.}}).Comparable() &&
-		{{end}} true,
+		{{if .GaugeOptions}}aggregation: o.gaugeAggregation,
+		onlyOnChange: o.onlyOnChange,{{end}}
+		{{if .CounterOptions}}sharded: o.sharded,{{end}}
+		{{range .Ns}}format{{.}}: tagFormatter[V{{.}}](),
+		{{end}}
 		ok:         ok,
 	}
 }
@@ -136,17 +254,48 @@ func New{{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}](
 func (d {{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}]) Values({{range .Ns}} v{{.}} V{{.}}, {{end}}) {{.Metric}}Def {
 	t := tags{n: {{.N}}}
 	copy(t.keys[:], d.keys[:])
-	{{range .Ns}}t.values[{{.}}] = v{{.}}
+	{{range .Ns}}t.values[{{.}}] = formatTag(d.format{{.}}, v{{.}})
 	{{end}}
 	return {{.Metric}}Def{
 		name: d.name,
+		previousName: d.previousName,
 		{{if .Unit}}unit: d.unit,{{end}}
 		tags: d.prefix.append(t),
 		{{if .SampleRate}}sampleRate: d.sampleRate,{{end}}
-		allComparable: d.allComparable,
+		{{if .GaugeOptions}}aggregation: d.aggregation,
+		onlyOnChange: d.onlyOnChange,{{end}}
+		{{if .CounterOptions}}sharded: d.sharded,{{end}}
 		ok: d.ok,
 	}
 }
+// Bound{{.Metric}}Def{{.N}} is a {{.Metric}}Def{{.N}} bound to a Metrics, see {{.Metric}}Def{{.N}}.Bind.
+type Bound{{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}] struct {
+	m *Metrics
+	d {{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}]
+	cache *metricMap[tagValues{{.N}}[{{range .Ns}} V{{.}}, {{end}}], *{{.Metric}}]
+}
+
+// Bind returns a Bound{{.Metric}}Def{{.N}} for looking up the {{.MetricLower}}s of d in m. Get() is
+// the same as m.{{.Metric}}(d.Values(...)), but remembers the {{.MetricLower}} for each set of tag
+// values so that getting it again doesn't allocate or take any locks. Bind itself allocates, so it
+// should be called once and the result kept.
+func (d {{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}]) Bind(m *Metrics) Bound{{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}] {
+	return Bound{{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}]{
+		m: m,
+		d: d,
+		cache: &metricMap[tagValues{{.N}}[{{range .Ns}} V{{.}}, {{end}}], *{{.Metric}}]{},
+	}
+}
+
+// Get returns the {{.MetricLower}} with the given tag values.
+func (b Bound{{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}]) Get({{range .Ns}} v{{.}} V{{.}}, {{end}}) *{{.Metric}} {
+	k := tagValues{{.N}}[{{range .Ns}} V{{.}}, {{end}}]{ {{range .Ns}} v{{.}}, {{end}} }
+	x, ok := b.cache.Load(k)
+	if !ok {
+		x, _ = b.cache.LoadOrStore(k, b.m.{{.Metric}}(b.d.Values({{range .Ns}} v{{.}}, {{end}})))
+	}
+	return x
+}
 `))
 
 var bindPrefixTmpl = template.Must(template.New("name").Parse(`
@@ -155,17 +304,61 @@ var bindPrefixTmpl = template.Must(template.New("name").Parse(`
 func (d {{.Metric}}Def{{.N}}[{{range .Ns}} V{{.}}, {{end}}]) Prefix{{.K}}({{range .Ks}} v{{.}} V{{.}}, {{end}}) {{.Metric}}Def{{.NMinusK}}[{{range .NMinusKs}} V{{.}}, {{end}}] {
 	t := tags{n: {{.K}}}
 	copy(t.keys[:], d.keys[:{{.K}}])
-	{{range .Ks}}t.values[{{.}}] = v{{.}}
+	{{range .Ks}}t.values[{{.}}] = formatTag(d.format{{.}}, v{{.}})
 	{{end}}
 
 	return {{.Metric}}Def{{.NMinusK}}[{{range .NMinusKs}} V{{.}}, {{end}}]{
 		name: d.name,
+		previousName: d.previousName,
 		{{if .Unit}}unit: d.unit,{{end}}
 		prefix: t,
 		keys: *((*[{{.NMinusK}}]string)(d.keys[{{.K}}:])),
 		{{if .SampleRate}}sampleRate: d.sampleRate,{{end}}
-		allComparable: d.allComparable,
+		{{if .GaugeOptions}}aggregation: d.aggregation,
+		onlyOnChange: d.onlyOnChange,{{end}}
+		{{if .CounterOptions}}sharded: d.sharded,{{end}}
+		{{range .Rest}}format{{.New}}: d.format{{.Old}},
+		{{end}}
 		ok:   d.ok,
 	}
 }
 `))
+
+var gaugeGroupTmpl = template.Must(template.New("name").Parse(`
+// GaugeGroup{{.N}} is a set of gauges from the same GaugeDef{{.N}} that are emitted together. Each
+// EmitAndUnset emits the gauges that were Set since the last one and unsets the rest, so the group
+// reports exactly the tag values that are currently Set, for example the number of items in each
+// state. It's safe to use concurrently.
+type GaugeGroup{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}] struct {
+	m     *Metrics
+	d     GaugeDef{{.N}}[{{range .Ns}} V{{.}}, {{end}}]
+	inner gaugeGroup
+}
+
+// NewGaugeGroup{{.N}} returns a GaugeGroup{{.N}} that reports to d.
+func NewGaugeGroup{{.N}}[{{range .Ns}} V{{.}} TagValue, {{end}}](
+	m *Metrics,
+	d GaugeDef{{.N}}[{{range .Ns}} V{{.}}, {{end}}],
+) *GaugeGroup{{.N}}[{{range .Ns}} V{{.}}, {{end}}] {
+	return &GaugeGroup{{.N}}[{{range .Ns}} V{{.}}, {{end}}]{
+		m:     m,
+		d:     d,
+		inner: newGaugeGroup(),
+	}
+}
+
+// Set sets the gauge with the given tag values to value, to be emitted by the next EmitAndUnset.
+func (g *GaugeGroup{{.N}}[{{range .Ns}} V{{.}}, {{end}}]) Set({{range .Ns}} v{{.}} V{{.}}, {{end}} value float64) {
+	g.inner.set(g.m.Gauge(g.d.Values({{range .Ns}} v{{.}}, {{end}})), value)
+}
+
+// EmitAndUnset emits the gauges added using Set since the last EmitAndUnset, and unsets any tagsets
+// that were not Set since the last EmitAndUnset.
+func (g *GaugeGroup{{.N}}[{{range .Ns}} V{{.}}, {{end}}]) EmitAndUnset() { g.inner.EmitAndUnset() }
+
+// EmitEveryFlush calls EmitAndUnset before every flush using Metrics.EveryFlush, returning a
+// function that stops it.
+func (g *GaugeGroup{{.N}}[{{range .Ns}} V{{.}}, {{end}}]) EmitEveryFlush() func() {
+	return g.m.EveryFlush(g.EmitAndUnset)
+}
+`))
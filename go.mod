@@ -1,8 +1,6 @@
 module github.com/bradenaw/metrics
 
-go 1.21
-
-toolchain go1.21.1
+go 1.22
 
 require (
 	github.com/bradenaw/juniper v0.13.0
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"sync/atomic"
+)
+
+// InFlight measures concurrency, for example the number of requests currently being handled by an
+// endpoint.
+//
+// It reports two gauges: the number in flight at the time of each flush, and the peak number in
+// flight at any point since the previous flush. The latter catches short bursts that happen
+// entirely between flushes.
+//
+//	// ---- metrics.go -----------------------------------------------------------------------------
+//	rpcInFlightDef = metrics.NewGaugeDef1[string](
+//		"rpc_in_flight",
+//		"The number of RPCs currently being handled by method.",
+//		metrics.UnitRequest,
+//		[...]string{"method"},
+//	)
+//	rpcInFlightPeakDef = metrics.NewGaugeDef1[string](
+//		"rpc_in_flight_peak",
+//		"The maximum number of RPCs being handled concurrently by method since the last flush.",
+//		metrics.UnitRequest,
+//		[...]string{"method"},
+//	)
+//
+//	// ---- at creation of RPC server --------------------------------------------------------------
+//	s.getInFlight = metrics.NewInFlight(
+//		m,
+//		rpcInFlightDef.Values("get"),
+//		rpcInFlightPeakDef.Values("get"),
+//	)
+//
+//	// ---- inside the Get() RPC handler -----------------------------------------------------------
+//	defer s.getInFlight.Start()()
+type InFlight struct {
+	current      atomic.Int64
+	peak         atomic.Int64
+	gauge        *Gauge
+	peakGauge    *Gauge
+	stopEmitting func()
+}
+
+// NewInFlight returns an InFlight that reports the current concurrency to d and the peak
+// concurrency since the last flush to peakD.
+//
+// The InFlight reports on every flush until Close is called.
+func NewInFlight(m *Metrics, d GaugeDef, peakD GaugeDef) *InFlight {
+	f := &InFlight{
+		gauge:     m.Gauge(d),
+		peakGauge: m.Gauge(peakD),
+	}
+	f.stopEmitting = m.EveryFlush(f.emit)
+	return f
+}
+
+// Start marks the beginning of one unit of concurrent work, and returns a function that marks its
+// end. It's meant to be used with defer so that panics and early returns are still accounted for:
+//
+//	defer inFlight.Start()()
+//
+// Calling the returned function more than once has no additional effect.
+func (f *InFlight) Start() func() {
+	curr := f.current.Add(1)
+	for {
+		peak := f.peak.Load()
+		if curr <= peak || f.peak.CompareAndSwap(peak, curr) {
+			break
+		}
+	}
+
+	var done atomic.Bool
+	return func() {
+		if !done.CompareAndSwap(false, true) {
+			return
+		}
+		for {
+			curr := f.current.Load()
+			if curr <= 0 || f.current.CompareAndSwap(curr, curr-1) {
+				break
+			}
+		}
+	}
+}
+
+// Current returns the number of units of work that have been started but not yet finished.
+func (f *InFlight) Current() int64 {
+	return f.current.Load()
+}
+
+// Close stops reporting f and unsets its gauges.
+func (f *InFlight) Close() {
+	f.stopEmitting()
+	f.gauge.Unset()
+	f.peakGauge.Unset()
+}
+
+func (f *InFlight) emit() {
+	curr := f.current.Load()
+	// The peak for the next interval starts at whatever is in flight right now.
+	peak := f.peak.Swap(curr)
+	if curr > peak {
+		peak = curr
+	}
+	f.gauge.Set(float64(curr))
+	f.peakGauge.Set(float64(peak))
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+type buildInfo struct {
+	revision      string
+	goVersion     string
+	moduleVersion string
+	dirty         bool
+}
+
+var readBuildInfo = sync.OnceValue(func() buildInfo {
+	result := buildInfo{
+		revision:      "unknown",
+		goVersion:     "unknown",
+		moduleVersion: "unknown",
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return result
+	}
+	if bi.GoVersion != "" {
+		result.goVersion = bi.GoVersion
+	}
+	if bi.Main.Version != "" {
+		result.moduleVersion = bi.Main.Version
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			result.revision = setting.Value
+		case "vcs.modified":
+			result.dirty = setting.Value == "true"
+		}
+	}
+	return result
+})
+
+// FeatureFlagGroup reports the state of a set of feature flags as gauges, one per flag, set to 1 and
+// tagged with the flag's name and current value, for example:
+//
+//	feature_flags   flag:new_checkout   value:true
+//	feature_flags   flag:cache_mode     value:write_through
+//
+// Flags that are no longer present, or whose value has changed, are unset so that only the current
+// state is reported.
+//
+// FeatureFlagGroups are usually emitted to using Metrics.EveryFlush, or whenever the flags are
+// reloaded.
+type FeatureFlagGroup[V TagValue] struct {
+	m     *Metrics
+	d     GaugeDef2[string, V]
+	inner gaugeGroup
+}
+
+// NewFeatureFlagGroup returns a FeatureFlagGroup that reports to d.
+//
+// By convention, the keys for d are "flag" and "value".
+func NewFeatureFlagGroup[V TagValue](m *Metrics, d GaugeDef2[string, V]) *FeatureFlagGroup[V] {
+	return &FeatureFlagGroup[V]{
+		m:     m,
+		d:     d,
+		inner: newGaugeGroup(),
+	}
+}
+
+// Emit reports flags as the current state of all feature flags, unsetting any flag name and value
+// pairs that were present in the last call to Emit but are not present now.
+func (g *FeatureFlagGroup[V]) Emit(flags map[string]V) {
+	for name, value := range flags {
+		g.inner.set(g.m.Gauge(g.d.Values(name, value)), 1)
+	}
+	g.inner.EmitAndUnset()
+}
@@ -12,26 +12,26 @@ import (
 // slightly faster.
 //
 // It works the same way.
-type metricMap[V any] struct {
+type metricMap[K comparable, V any] struct {
 	// read is the immutable part of the map. Lookups can check here with just an atomic load, and
 	// on hit, can safely use the value they see.
-	read atomic.Pointer[metricMapRO[V]]
+	read atomic.Pointer[metricMapRO[K, V]]
 
 	mu sync.Mutex
 	// The number of loads since the last `promoteLocked()` with `amended` set that had to take `mu`
 	// to examine `dirty`. When this grows too large, promote `dirty` into `read`.
 	misses int
 	// `dirty` is either nil (when `!read.amended`) or a superset of the map inside of `read`.
-	dirty map[metricKey]V
+	dirty map[K]V
 }
 
-type metricMapRO[V any] struct {
-	m map[metricKey]V
+type metricMapRO[K comparable, V any] struct {
+	m map[K]V
 	// True if `m` is incomplete, that is, that there are keys in `dirty` that are not in `m`.
 	amended bool
 }
 
-func (m *metricMap[V]) Load(k metricKey) (V, bool) {
+func (m *metricMap[K, V]) Load(k K) (V, bool) {
 	ro := m.loadReadOnly()
 	existing, ok := ro.m[k]
 	if ok {
@@ -61,7 +61,7 @@ func (m *metricMap[V]) Load(k metricKey) (V, bool) {
 	return existing, ok
 }
 
-func (m *metricMap[V]) LoadOrStore(k metricKey, v V) (V, bool) {
+func (m *metricMap[K, V]) LoadOrStore(k K, v V) (V, bool) {
 	ro := m.loadReadOnly()
 	existing, ok := ro.m[k]
 	if ok {
@@ -78,12 +78,12 @@ func (m *metricMap[V]) LoadOrStore(k metricKey, v V) (V, bool) {
 		// m.dirty==nil means that ro.m was complete up to this point, so we're the first write
 		// since the last promotion.
 		if ro.m == nil {
-			m.dirty = make(map[metricKey]V)
+			m.dirty = make(map[K]V)
 		} else {
 			m.dirty = maps.Clone(ro.m)
 		}
 		// Also mark ro as incomplete so that reads know they might need to check m.dirty.
-		m.read.Store(&metricMapRO[V]{
+		m.read.Store(&metricMapRO[K, V]{
 			m:       ro.m,
 			amended: true,
 		})
@@ -96,7 +96,7 @@ func (m *metricMap[V]) LoadOrStore(k metricKey, v V) (V, bool) {
 	return v, false
 }
 
-func (m *metricMap[V]) Range(f func(metricKey, V) bool) {
+func (m *metricMap[K, V]) Range(f func(K, V) bool) {
 	ro := m.loadReadOnly()
 	read := ro.m
 	if ro.amended {
@@ -122,8 +122,8 @@ func (m *metricMap[V]) Range(f func(metricKey, V) bool) {
 }
 
 // promotes m.dirty into m.read, making m.read complete again.
-func (m *metricMap[V]) promoteLocked() {
-	m.read.Store(&metricMapRO[V]{
+func (m *metricMap[K, V]) promoteLocked() {
+	m.read.Store(&metricMapRO[K, V]{
 		m:       m.dirty,
 		amended: false,
 	})
@@ -132,11 +132,11 @@ func (m *metricMap[V]) promoteLocked() {
 }
 
 // Loads ro, acccounting for a zero-valued m.
-func (m *metricMap[V]) loadReadOnly() metricMapRO[V] {
+func (m *metricMap[K, V]) loadReadOnly() metricMapRO[K, V] {
 	ro := m.read.Load()
 	if ro == nil {
 		// nil map shows as empty, and if it's never been set then amended is false anyway.
-		return metricMapRO[V]{}
+		return metricMapRO[K, V]{}
 	}
 	return *ro
 }
@@ -3,6 +3,7 @@ package metrics
 import (
 	"maps"
 	"math/rand/v2"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -17,7 +18,7 @@ func TestMetricMapConcurrent(t *testing.T) {
 
 	start := time.Now()
 
-	var m metricMap[*bool]
+	var m metricMap[metricKey, *bool]
 
 	var (
 		totalLoads         atomic.Int64
@@ -39,7 +40,7 @@ func TestMetricMapConcurrent(t *testing.T) {
 			checkKey := func(k metricKey, existing *bool) {
 				expected, mine := myKeys[k]
 				if mine && existing != expected {
-					t.Fatal("previously stored a value but it got overwritten")
+					t.Error("previously stored a value but it got overwritten")
 				}
 			}
 
@@ -51,12 +52,13 @@ func TestMetricMapConcurrent(t *testing.T) {
 				k := newMetricKey(
 					"", // name
 					1,  // n
-					[maxTags]any{
+					[maxTags]string{
 						// Quantize the keys a little to increase the chance of collision between
 						// goroutines.
-						int(time.Since(start).Round(time.Millisecond)) + rand.N(concurrency),
+						strconv.Itoa(
+							int(time.Since(start).Round(time.Millisecond)) + rand.N(concurrency),
+						),
 					},
-					true, // allComparable
 				)
 
 				loads++
@@ -69,7 +71,7 @@ func TestMetricMapConcurrent(t *testing.T) {
 					v2, loaded := m.LoadOrStore(k, v)
 					if !loaded {
 						if v != v2 {
-							t.Fatal("stored a value but got back something different")
+							t.Error("stored a value but got back something different")
 						}
 						myKeys[k] = v
 					}
@@ -84,13 +86,13 @@ func TestMetricMapConcurrent(t *testing.T) {
 							return true
 						}
 						if expected != v {
-							t.Fatal("Range saw a different value than the last LoadOrStore")
+							t.Error("Range saw a different value than the last LoadOrStore")
 						}
 						seen++
 						return true
 					})
 					if seen != len(myKeys) {
-						t.Fatal("Range didn't see a key it should have")
+						t.Error("Range didn't see a key it should have")
 					}
 				}
 			}
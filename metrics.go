@@ -13,8 +13,22 @@
 // good idea to put an alert on this stat so that if it starts logging during a deploy, you know
 // your other metrics may not be trustworthy.
 //
+// Programs that need to define metrics after init, for example plugin systems or metrics generated
+// from configuration, can use a Registry and the RegisterMDefY functions instead, which return an
+// error for invalid definitions.
+//
+// These conventions can also be checked at build time with the analyzer in cmd/metrics-vet, for
+// example with go vet -vettool=$(which metrics-vet) ./...
+//
 // See the example folder for an example of usage.
 //
+// Series can be turned off at runtime without a code change, for example when one is flooding the
+// agent, using Metrics.SetFilter or WithFilterFile. The filter's state is reported as
+// metrics.filter_rules, metrics.filtered_series, and metrics.filter_file_load_failures.
+//
+// Every Metrics also reports a gauge called metrics.build_info, which is always 1 and tagged with the
+// VCS revision, Go version, and module version of the running binary.
+//
 // Generally you will have:
 //  1. metrics.NewMDefY calls in a top-level var block of metrics.go in packages that log metrics,
 //     named ending in "Def".
@@ -46,7 +60,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/bradenaw/juniper/xslices"
 	"github.com/bradenaw/juniper/xsync"
 	"golang.org/x/exp/maps"
 )
@@ -58,6 +71,8 @@ const (
 	//
 	// https://docs.datadoghq.com/developers/dogstatsd/?tab=hostagent
 	flushInterval = 2 * time.Second
+
+	defaultGaugeHeartbeat = time.Minute
 )
 
 // Publisher is the subset of github.com/DataDog/datadog-go/v5/statsd.ClientInterface used by this
@@ -73,11 +88,23 @@ type Publisher interface {
 	Set(name string, value string, tags []string, rate float64) error
 }
 
-// TagValue is the value of a key:value pair in a metric tag. They are formatted the same as
-// fmt.Sprint unless the type implements TagValuer, in which case MetricTagValue() is used instead.
+// TagValue is the set of types that can be the value of a key:value pair in a metric tag: strings,
+// bools, and integers, including named types whose underlying type is one of those. Values are
+// formatted when they're bound with Values() or PrefixK(), using MetricTagValue() if the type
+// implements TagValuer, String() if it implements fmt.Stringer, and otherwise the same as
+// fmt.Sprint. How to format each type is decided once when the def is made, so formatting doesn't
+// need reflection.
+//
+// Values of any other type, for example structs that implement TagValuer, can be used by opting in
+// to AnyTagValue, which is slower.
 //
 // TagValues that produce the same string are considered the same.
-type TagValue any
+type TagValue interface {
+	~string | ~bool |
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		AnyTagValue
+}
 
 // See the comment on type TagValue.
 type TagValuer interface {
@@ -89,15 +116,53 @@ type Metrics struct {
 	bg       *xsync.Group
 	flushNow func()
 
-	gauges        metricMap[*Gauge]
-	counters      metricMap[*Counter]
-	distributions metricMap[*Distribution]
-	sets          metricMap[*Set]
+	gauges        metricMap[metricKey, *Gauge]
+	counters      metricMap[metricKey, *Counter]
+	distributions metricMap[metricKey, *Distribution]
+	sets          metricMap[metricKey, *Set]
 
 	m       sync.Mutex
 	flushed chan struct{}
 	nextID  int
 	polls   map[int]func()
+
+	// Series matching the filter aren't published. See SetFilter.
+	filter     atomic.Pointer[Filter]
+	filterFile string
+
+	allGaugesOnlyOnChange bool
+	// The number of flushes between publishes of unchanged gauges that only publish on change.
+	gaugeHeartbeatFlushes int
+	// The number of flushes so far. Only accessed from the flush goroutine.
+	flushes int
+}
+
+// Option is an optional setting for New.
+type Option func(*Metrics)
+
+// WithAllGaugesOnlyOnChange makes every gauge publish only when its value changes, as if they were
+// all defined with WithPublishOnlyOnChange.
+func WithAllGaugesOnlyOnChange() Option {
+	return func(m *Metrics) {
+		m.allGaugesOnlyOnChange = true
+	}
+}
+
+// WithGaugeHeartbeat sets how often gauges that only publish on change are re-published when their
+// value has not changed. Defaults to one minute. It is rounded up to a multiple of the flush
+// interval.
+func WithGaugeHeartbeat(heartbeat time.Duration) Option {
+	return func(m *Metrics) {
+		m.gaugeHeartbeatFlushes = heartbeatFlushes(heartbeat)
+	}
+}
+
+func heartbeatFlushes(heartbeat time.Duration) int {
+	n := int((heartbeat + flushInterval - 1) / flushInterval)
+	if n < 1 {
+		return 1
+	}
+	return n
 }
 
 type noOpPublisher struct{}
@@ -135,20 +200,65 @@ var (
 		UnitItem,
 		[...]string{"reason"},
 	)
+
+	filterRulesDef = NewGaugeDef1[string](
+		"metrics.filter_rules",
+		"The number of rules in the filter set with SetFilter or WithFilterFile, by whether they "+
+			"allow or deny.",
+		UnitItem,
+		[...]string{"action"},
+	)
+
+	filteredSeriesDef = NewGaugeDef(
+		"metrics.filtered_series",
+		"The number of series that are not being published because they match the filter set with "+
+			"SetFilter or WithFilterFile.",
+		UnitItem,
+	)
+
+	filterFileLoadFailuresDef = NewCounterDef(
+		"metrics.filter_file_load_failures",
+		"The number of times the file given to WithFilterFile could not be read or was invalid. "+
+			"The previous filter stays in effect.",
+		UnitError,
+	)
+
+	buildInfoDef = NewGaugeDef4[string, string, string, bool](
+		"metrics.build_info",
+		"Always 1, tagged with information about the running binary from runtime/debug.ReadBuildInfo.",
+		NoUnits,
+		[...]string{"revision", "go_version", "module_version", "dirty"},
+		WithPublishOnlyOnChange(),
+	)
 )
 
-func New(p Publisher) *Metrics {
+func New(p Publisher, opts ...Option) *Metrics {
 	m := &Metrics{
 		p:       p,
 		bg:      xsync.NewGroup(context.Background()),
 		flushed: make(chan struct{}),
 		polls:   make(map[int]func()),
+
+		gaugeHeartbeatFlushes: heartbeatFlushes(defaultGaugeHeartbeat),
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
 
 	badDefsCallersFramesGauge := m.Gauge(badDefsDef.Values("runtime_caller_failed"))
 	badDefsNotAtInitGauge := m.Gauge(badDefsDef.Values("not_at_init_time"))
 	badDefsObserveDurationBadUnitsGauge := m.Gauge(badDefsDef.Values("observe_duration_bad_units"))
 
+	denyRulesGauge := m.Gauge(filterRulesDef.Values("deny"))
+	allowRulesGauge := m.Gauge(filterRulesDef.Values("allow"))
+	filteredSeriesGauge := m.Gauge(filteredSeriesDef)
+	if m.filterFile != "" {
+		m.watchFilterFile(m.Counter(filterFileLoadFailuresDef))
+	}
+
+	bi := readBuildInfo()
+	m.Gauge(buildInfoDef.Values(bi.revision, bi.goVersion, bi.moduleVersion, bi.dirty)).Set(1)
+
 	m.flushNow = m.bg.PeriodicOrTrigger(flushInterval, 0 /*jitter*/, func(ctx context.Context) {
 		m.m.Lock()
 		polls := maps.Values(m.polls)
@@ -160,7 +270,9 @@ func New(p Publisher) *Metrics {
 		badDefsCallersFramesGauge.Set(float64(badDefsCallersFrames.Load()))
 		badDefsNotAtInitGauge.Set(float64(badDefsNotAtInit.Load()))
 		badDefsObserveDurationBadUnitsGauge.Set(float64(badObserveDurations.Load()))
+		m.reportFilter(denyRulesGauge, allowRulesGauge, filteredSeriesGauge)
 
+		m.flushes++
 		m.gauges.Range(func(_ metricKey, g *Gauge) bool {
 			g.publish()
 			return true
@@ -212,18 +324,31 @@ func New(p Publisher) *Metrics {
 //	} else {
 //		s.getErrorCounter.Add(1)
 //	}
+//
+// When the tag values aren't known ahead of time, Bind keeps the same kind of cache for every set of
+// tag values:
+//
+//	// ---- at creation of RPC server --------------------------------------------------------------
+//	s.responseCounters = rpcResponseDef.Bind(m)
+//
+//	// ---- inside the RPC handler -----------------------------------------------------------------
+//	s.responseCounters.Get(methodName, status).Add(1)
 func (m *Metrics) Counter(d CounterDef) *Counter {
 	if !d.ok {
 		return noOpCounter
 	}
 
-	k := newMetricKey(d.name, d.tags.n, d.tags.values, d.allComparable)
+	k := newMetricKey(d.name, d.tags.n, d.tags.values)
 	c, ok := m.counters.Load(k)
 	if !ok {
 		c = &Counter{
-			m:    m,
-			name: d.name,
-			tags: makeTags(d.tags.keys[:d.tags.n], d.tags.values[:d.tags.n]),
+			m:            m,
+			name:         d.name,
+			previousName: d.previousName,
+			tags:         makeTags(d.tags.keys[:d.tags.n], d.tags.values[:d.tags.n]),
+		}
+		if d.sharded {
+			c.shards = newCounterShards()
 		}
 		c, _ = m.counters.LoadOrStore(k, c)
 	}
@@ -237,15 +362,20 @@ func (m *Metrics) Gauge(d GaugeDef) *Gauge {
 		return noOpGauge
 	}
 
-	k := newMetricKey(d.name, d.tags.n, d.tags.values, d.allComparable)
+	k := newMetricKey(d.name, d.tags.n, d.tags.values)
 	g, ok := m.gauges.Load(k)
 	if !ok {
 		g = &Gauge{
-			m:    m,
-			name: d.name,
-			tags: makeTags(d.tags.keys[:d.tags.n], d.tags.values[:d.tags.n]),
+			m:            m,
+			name:         d.name,
+			previousName: d.previousName,
+			tags:         makeTags(d.tags.keys[:d.tags.n], d.tags.values[:d.tags.n]),
+			aggregation:  d.aggregation,
+			onlyOnChange: d.onlyOnChange || m.allGaugesOnlyOnChange,
 		}
 		g.v.Store(math.Float64bits(math.NaN()))
+		g.agg.Store(math.Float64bits(math.NaN()))
+		g.sum.Store(new(gaugeSum))
 		g, _ = m.gauges.LoadOrStore(k, g)
 	}
 	return g
@@ -259,15 +389,16 @@ func (m *Metrics) Distribution(d DistributionDef) *Distribution {
 		return noOpDistribution
 	}
 
-	k := newMetricKey(d.name, d.tags.n, d.tags.values, d.allComparable)
+	k := newMetricKey(d.name, d.tags.n, d.tags.values)
 	c, ok := m.distributions.Load(k)
 	if !ok {
 		c = &Distribution{
-			m:          m,
-			name:       d.name,
-			unit:       d.unit,
-			tags:       makeTags(d.tags.keys[:d.tags.n], d.tags.values[:d.tags.n]),
-			sampleRate: d.sampleRate,
+			m:            m,
+			name:         d.name,
+			previousName: d.previousName,
+			unit:         d.unit,
+			tags:         makeTags(d.tags.keys[:d.tags.n], d.tags.values[:d.tags.n]),
+			sampleRate:   d.sampleRate,
 		}
 		c, _ = m.distributions.LoadOrStore(k, c)
 	}
@@ -281,14 +412,15 @@ func (m *Metrics) Set(d SetDef) *Set {
 		return noOpSet
 	}
 
-	k := newMetricKey(d.name, d.tags.n, d.tags.values, d.allComparable)
+	k := newMetricKey(d.name, d.tags.n, d.tags.values)
 	c, ok := m.sets.Load(k)
 	if !ok {
 		c = &Set{
-			m:          m,
-			name:       d.name,
-			tags:       makeTags(d.tags.keys[:d.tags.n], d.tags.values[:d.tags.n]),
-			sampleRate: d.sampleRate,
+			m:            m,
+			name:         d.name,
+			previousName: d.previousName,
+			tags:         makeTags(d.tags.keys[:d.tags.n], d.tags.values[:d.tags.n]),
+			sampleRate:   d.sampleRate,
 		}
 		c, _ = m.sets.LoadOrStore(k, c)
 	}
@@ -355,37 +487,110 @@ func (m *Metrics) Close() {
 //
 // Gauges are good for measuring states, for example the number of open connections or the size of a
 // buffer.
+//
+// By default a Gauge reports the last value it was set to before each flush. Defs created with
+// WithGaugeAggregation can instead report the max, min, mean, or sum of the values it was set to
+// during each flush interval, see GaugeAggregation.
 type Gauge struct {
 	m    *Metrics
 	name string
-	tags []string
-	v    atomic.Uint64
+	// If non-empty, also published under this name. See WithPreviousName.
+	previousName string
+	tags         []string
+	aggregation  GaugeAggregation
+	filter       filterState
+	// The current value of the gauge, NaN if unset.
+	v atomic.Uint64
+	// For GaugeAggregationMax and GaugeAggregationMin, the aggregate of the values seen since the
+	// last publish, NaN if none.
+	agg atomic.Uint64
+	// For GaugeAggregationMean and GaugeAggregationSum, the sum and number of values seen since the
+	// last publish. publish swaps in a new one for each interval.
+	sum atomic.Pointer[gaugeSum]
+
+	onlyOnChange bool
+	// The below are only accessed from the flush goroutine, and only used if onlyOnChange.
+	published          bool
+	lastPublished      float64
+	lastPublishedFlush int
 }
 
 // Set sets the value of the gauge. The gauge will continue to have this value until the next Set or
 // Unset, or the end of the process.
 func (g *Gauge) Set(v float64) {
 	g.v.Store(math.Float64bits(v))
+	g.observe(v)
 }
 
 // Add adds v to the current value of g. If g is unset, sets g to v.
 func (g *Gauge) Add(v float64) {
-	g.update(func(prev float64) float64 {
+	next := g.update(func(prev float64) float64 {
 		if math.IsNaN(prev) {
 			return v
 		}
 		return prev + v
 	})
+	g.observe(next)
+}
+
+// Returns the new value.
+func (g *Gauge) update(f func(prev float64) float64) float64 {
+	return casFloat64(&g.v, f)
+}
+
+// Folds v into the aggregate for the current interval.
+func (g *Gauge) observe(v float64) {
+	switch g.aggregation {
+	case GaugeAggregationMax:
+		casFloat64(&g.agg, func(prev float64) float64 {
+			if math.IsNaN(prev) || v > prev {
+				return v
+			}
+			return prev
+		})
+	case GaugeAggregationMin:
+		casFloat64(&g.agg, func(prev float64) float64 {
+			if math.IsNaN(prev) || v < prev {
+				return v
+			}
+			return prev
+		})
+	case GaugeAggregationMean, GaugeAggregationSum:
+		for {
+			s := g.sum.Load()
+			s.writers.Add(1)
+			// If publish swapped s out before it could see this writer, count v in the next interval
+			// instead.
+			if g.sum.Load() != s {
+				s.writers.Add(-1)
+				continue
+			}
+			casFloat64(&s.sum, func(prev float64) float64 { return prev + v })
+			s.n.Add(1)
+			s.writers.Add(-1)
+			return
+		}
+	}
+}
+
+// gaugeSum is the sum and number of the values a Gauge was set to during one flush interval.
+type gaugeSum struct {
+	sum atomic.Uint64
+	n   atomic.Int64
+	// The number of observes that are adding to sum and n.
+	writers atomic.Int32
 }
 
-func (g *Gauge) update(f func(prev float64) float64) {
+// casFloat64 atomically replaces the float64 stored in a with f of its previous value, returning
+// the new value.
+func casFloat64(a *atomic.Uint64, f func(prev float64) float64) float64 {
 	for {
-		v := g.v.Load()
+		v := a.Load()
 		vf := math.Float64frombits(v)
 		nextf := f(vf)
 		next := math.Float64bits(nextf)
-		if g.v.CompareAndSwap(v, next) {
-			break
+		if a.CompareAndSwap(v, next) {
+			return nextf
 		}
 	}
 }
@@ -402,10 +607,48 @@ func (g *Gauge) value() float64 {
 
 func (g *Gauge) publish() {
 	v := g.value()
-	if math.IsNaN(v) {
+	switch g.aggregation {
+	case GaugeAggregationMax, GaugeAggregationMin:
+		// The next interval starts at the gauge's current value.
+		agg := math.Float64frombits(g.agg.Swap(math.Float64bits(v)))
+		if !math.IsNaN(v) && !math.IsNaN(agg) {
+			v = agg
+		}
+	case GaugeAggregationMean, GaugeAggregationSum:
+		s := g.sum.Swap(new(gaugeSum))
+		// Wait out any observes that started before the swap so that each value is counted in both
+		// the sum and the count of the same interval.
+		for s.writers.Load() != 0 {
+			runtime.Gosched()
+		}
+		sum, n := math.Float64frombits(s.sum.Load()), s.n.Load()
+		if math.IsNaN(v) {
+			break
+		}
+		if g.aggregation == GaugeAggregationSum {
+			v = sum
+		} else if n > 0 {
+			v = sum / float64(n)
+		}
+	}
+	if math.IsNaN(v) || g.filter.denied(g.m, g.name, g.previousName, g.tags) {
+		g.published = false
 		return
 	}
+	if g.onlyOnChange {
+		if g.published &&
+			v == g.lastPublished &&
+			g.m.flushes-g.lastPublishedFlush < g.m.gaugeHeartbeatFlushes {
+			return
+		}
+		g.published = true
+		g.lastPublished = v
+		g.lastPublishedFlush = g.m.flushes
+	}
 	g.m.p.Gauge(g.name, v, g.tags, 1 /*samplingRate*/)
+	if g.previousName != "" {
+		g.m.p.Gauge(g.previousName, v, g.tags, 1 /*samplingRate*/)
+	}
 }
 
 // Counter is a metric that keeps track of the number of events that happen per time interval.
@@ -415,33 +658,59 @@ func (g *Gauge) publish() {
 type Counter struct {
 	m    *Metrics
 	name string
-	tags []string
-	v    atomic.Int64
+	// If non-empty, also published under this name. See WithPreviousName.
+	previousName string
+	tags         []string
+	filter       filterState
+	v            atomic.Int64
+	// Non-nil for counters defined with WithShardedCounter, in which case v is unused.
+	shards *counterShards
 }
 
 func (c *Counter) Add(n int64) {
+	if c.shards != nil {
+		c.shards.add(n)
+		return
+	}
 	c.v.Add(n)
 }
 
 func (c *Counter) publish() {
-	v := c.v.Swap(0)
-	if v > 0 {
+	var v int64
+	if c.shards != nil {
+		v = c.shards.swap()
+	} else {
+		v = c.v.Swap(0)
+	}
+	if v > 0 && !c.filter.denied(c.m, c.name, c.previousName, c.tags) {
 		c.m.p.Count(c.name, v, c.tags, 1)
+		if c.previousName != "" {
+			c.m.p.Count(c.previousName, v, c.tags, 1)
+		}
 	}
 }
 
 // Distribution produces quantile metrics, e.g. 50th, 90th, 99th percentiles of the values passed to
 // Observe for each time bucket.
 type Distribution struct {
-	m          *Metrics
-	name       string
-	unit       Unit
-	tags       []string
-	sampleRate float64
+	m    *Metrics
+	name string
+	// If non-empty, also published under this name. See WithPreviousName.
+	previousName string
+	unit         Unit
+	tags         []string
+	filter       filterState
+	sampleRate   float64
 }
 
 func (d *Distribution) Observe(value float64) {
+	if d.filter.denied(d.m, d.name, d.previousName, d.tags) {
+		return
+	}
 	d.m.p.Distribution(d.name, value, d.tags, d.sampleRate)
+	if d.previousName != "" {
+		d.m.p.Distribution(d.previousName, value, d.tags, d.sampleRate)
+	}
 }
 
 var (
@@ -462,17 +731,17 @@ var (
 func (d *Distribution) ObserveDuration(value time.Duration) {
 	switch d.unit {
 	case UnitNanosecond:
-		d.m.p.Distribution(d.name, float64(value.Nanoseconds()), d.tags, d.sampleRate)
+		d.Observe(float64(value.Nanoseconds()))
 	case UnitMicrosecond:
-		d.m.p.Distribution(d.name, value.Seconds()*1_000_000, d.tags, d.sampleRate)
+		d.Observe(value.Seconds() * 1_000_000)
 	case UnitMillisecond:
-		d.m.p.Distribution(d.name, value.Seconds()*1_000, d.tags, d.sampleRate)
+		d.Observe(value.Seconds() * 1_000)
 	case UnitSecond:
-		d.m.p.Distribution(d.name, value.Seconds(), d.tags, d.sampleRate)
+		d.Observe(value.Seconds())
 	case UnitMinute:
-		d.m.p.Distribution(d.name, value.Seconds()/60, d.tags, d.sampleRate)
+		d.Observe(value.Seconds() / 60)
 	case UnitHour:
-		d.m.p.Distribution(d.name, value.Seconds()/3600, d.tags, d.sampleRate)
+		d.Observe(value.Seconds() / 3600)
 	default:
 		_, loaded := badObserveDurationsSet.LoadOrStore(d.name, struct{}{})
 		if !loaded {
@@ -481,49 +750,108 @@ func (d *Distribution) ObserveDuration(value time.Duration) {
 	}
 }
 
+// Time starts a timer and returns a function that, when called, records the time elapsed since the
+// call to Time using ObserveDuration. It's meant to be used with defer:
+//
+//	defer m.Distribution(requestLatencyDef).Time()()
+//
+// The same unit restrictions as ObserveDuration apply.
+func (d *Distribution) Time() func() {
+	start := time.Now()
+	return func() {
+		d.ObserveDuration(time.Since(start))
+	}
+}
+
+// TimeFunc calls f and records how long it took into d using ObserveDuration.
+func TimeFunc(d *Distribution, f func()) {
+	defer d.Time()()
+	f()
+}
+
+// TimeOutcome starts a timer and returns a function that, when called, records the time elapsed
+// since the call to TimeOutcome into ok if err is nil, or into failed otherwise. ok and failed are
+// usually the same def bound with a different tag value, for example:
+//
+//	// ---- at creation of RPC server --------------------------------------------------------------
+//	s.getLatencyOK = m.Distribution(rpcLatencyDef.Values("get", "ok"))
+//	s.getLatencyError = m.Distribution(rpcLatencyDef.Values("get", "error"))
+//
+//	// ---- inside the Get() RPC handler -----------------------------------------------------------
+//	stop := metrics.TimeOutcome(s.getLatencyOK, s.getLatencyError)
+//	defer func() { stop(err) }()
+//
+// The same unit restrictions as ObserveDuration apply.
+func TimeOutcome(ok *Distribution, failed *Distribution) func(err error) {
+	start := time.Now()
+	return func(err error) {
+		if err == nil {
+			ok.ObserveDuration(time.Since(start))
+		} else {
+			failed.ObserveDuration(time.Since(start))
+		}
+	}
+}
+
+// TimeFuncOutcome calls f and records how long it took into ok if it returns nil, or into failed
+// otherwise. Returns the error from f.
+func TimeFuncOutcome(ok *Distribution, failed *Distribution, f func() error) error {
+	stop := TimeOutcome(ok, failed)
+	err := f()
+	stop(err)
+	return err
+}
+
 // Set measures the cardinality of values passed to Observe for each time bucket, that is, it
 // estimates how many _unique_ values have been passed to it.
 type Set struct {
-	m          *Metrics
-	name       string
-	tags       []string
-	sampleRate float64
+	m    *Metrics
+	name string
+	// If non-empty, also published under this name. See WithPreviousName.
+	previousName string
+	tags         []string
+	filter       filterState
+	sampleRate   float64
 }
 
 func (s *Set) Observe(value string) {
+	if s.filter.denied(s.m, s.name, s.previousName, s.tags) {
+		return
+	}
 	s.m.p.Set(s.name, value, s.tags, s.sampleRate)
+	if s.previousName != "" {
+		s.m.p.Set(s.previousName, value, s.tags, s.sampleRate)
+	}
 }
 
+// The number of tag values that metricKey holds directly. Keys for defs with more tags than this
+// need to join the rest, which allocates, so this is a trade-off between the size of every key and
+// the cost of looking up metrics with many tags.
+const smallKeyTags = min(5, maxTags)
+
 // metricKey is used to dedupe metrics so that multiple calls on a def result in the same metric. It
-// contains the name and tag values.
+// contains the name and formatted tag values.
 type metricKey struct {
 	name   string
-	values [maxTags]any
+	values [smallKeyTags]string
+	// The tag values past smallKeyTags, if any, joined.
+	rest string
 }
 
-func newMetricKey(name string, n int, values [maxTags]any, allComparable bool) metricKey {
-	if allComparable {
-		// Fast path - avoid reflection when all of the tag values are comparable.
-		return metricKey{
-			name:   name,
-			values: values,
-		}
+func newMetricKey(name string, n int, values [maxTags]string) metricKey {
+	k := metricKey{
+		name:   name,
+		values: [smallKeyTags]string(values[:smallKeyTags]),
 	}
-
-	k := metricKey{name: name}
-
-	for i := range values {
-		if reflect.ValueOf(values[i]).Comparable() {
-			k.values[i] = values[i]
-		} else {
-			k.values[i] = tagValueString(values[i])
-		}
+	if n > smallKeyTags {
+		// Sanitized tag values never contain a comma.
+		k.rest = strings.Join(values[smallKeyTags:n], ",")
 	}
-
 	return k
 }
 
-func makeTags(keys []string, values []any) []string {
+// makeTags returns key:value tags from keys and already formatted values.
+func makeTags(keys []string, values []string) []string {
 	tags := make([]string, len(keys))
 	for i := range tags {
 		tags[i] = makeTag(keys[i], values[i])
@@ -531,11 +859,11 @@ func makeTags(keys []string, values []any) []string {
 	return tags
 }
 
-func makeTag(key string, value any) string {
+func makeTag(key string, value string) string {
 	if len(key) == 0 {
-		return tagValueString(value)
+		return value
 	}
-	return key + ":" + tagValueString(value)
+	return key + ":" + value
 }
 
 var validTagCharacters = func() [256]bool {
@@ -660,9 +988,34 @@ type Metadata struct {
 	ValueTypes  []reflect.Type `json:"-"`
 	File        string         `json:"file"`
 	Line        int            `json:"line"`
+	// The names of ValueTypes as given by reflect.Type.String(), since ValueTypes can't be
+	// serialized.
+	ValueTypeNames []string `json:"valueTypes"`
+	// Only set for gauges defined with WithGaugeAggregation.
+	GaugeAggregation GaugeAggregation `json:"gaugeAggregation,omitempty"`
+	// Only set for gauges defined with WithPublishOnlyOnChange.
+	PublishOnlyOnChange bool `json:"publishOnlyOnChange,omitempty"`
+	// Set with WithPerUnit.
+	PerUnit Unit `json:"perUnit,omitempty"`
+	// Set with WithShortName.
+	ShortName string `json:"shortName,omitempty"`
+	// Set with WithOwner.
+	Owner string `json:"owner,omitempty"`
+	// Set with WithRunbook.
+	RunbookURL string `json:"runbookURL,omitempty"`
+	// Set with WithSLO.
+	SLOs []string `json:"slos,omitempty"`
+	// Set with WithStability.
+	Stability Stability `json:"stability,omitempty"`
+	// Set with WithDeprecated.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	// Set with WithPreviousName. The metric is also published under this name.
+	PreviousName string `json:"previousName,omitempty"`
+	// The namespace of the registry that the metric was defined in, see NewNamespacedRegistry. Name
+	// already includes it.
+	Namespace string `json:"namespace,omitempty"`
 }
 
-var defs xsync.Map[string, *Metadata]
 var badDefsCallersFrames atomic.Int64
 var badDefsNotAtInit atomic.Int64
 
@@ -695,6 +1048,7 @@ func registerDef(
 	unit Unit,
 	keys []string,
 	valueTypes []reflect.Type,
+	opts defOptions,
 ) bool {
 	pc, file, line, ok := runtime.Caller(2)
 	if !ok {
@@ -709,18 +1063,6 @@ func registerDef(
 
 	// Now we know it's init-time, which means it's safe to panic.
 
-	if !nameRegexp.MatchString(name) {
-		panic(fmt.Sprintf(
-			"metric definition's name %q doesn't match required %s (see "+
-				"https://docs.datadoghq.com/metrics/custom_metrics/#naming-custom-metrics)\n\n"+
-				"metric %s defined at %s:%d",
-			name,
-			nameRegexp,
-			name,
-			file,
-			line,
-		))
-	}
 	if !(strings.HasSuffix(file, "/metrics.go") || strings.HasSuffix(file, "_example_test.go")) {
 		panic(fmt.Sprintf(
 			"metric definitions must be defined in init() or a top-level var block of a "+
@@ -729,87 +1071,44 @@ func registerDef(
 			name, file, line,
 		))
 	}
-	if len(description) > 400 {
+	if opts.registry != defaultRegistry && opts.registry.namespace == "" {
 		panic(fmt.Sprintf(
-			"metric descriptions cannot be more than 400 characters, this one is %d\n\n"+
+			"WithRegistry must be given a registry made with NewNamespacedRegistry, use "+
+				"RegisterMDefY to define metrics in other registries\n\n"+
 				"metric %s defined at %s:%d",
-			len(description), name, file, line,
-		))
-	}
-
-	d, loaded := defs.LoadOrStore(name, &Metadata{
-		MetricType:  metricType,
-		Name:        name,
-		Description: description,
-		Unit:        unit,
-		Keys:        xslices.Clone(keys),
-		ValueTypes:  valueTypes,
-		File:        file,
-		Line:        line,
-	})
-	if loaded {
-		panic(fmt.Sprintf(
-			"multiple definitions for metric %s:\n"+
-				"\t%s:%d\n"+
-				"\t%s:%d",
-			name,
-			d.File, d.Line,
-			file, line,
+			name, file, line,
 		))
 	}
-
-	seenKeys := make(map[string]bool, len(keys))
-	for _, key := range keys {
-		_, ok := reservedTagKeys[key]
-		if ok {
-			panic(fmt.Sprintf(
-				"metric used reserved tag key %q (see "+
-					"https://docs.datadoghq.com/getting_started/tagging/#overview)\n\n"+
-					"metric %s defined at %s:%d",
-				key,
-				name,
-				file,
-				line,
-			))
-		}
-		if !tagKeyRegexp.MatchString(key) {
-			panic(fmt.Sprintf(
-				"metric tag key %q doesn't match %s (see "+
-					"https://docs.datadoghq.com/getting_started/tagging/#define-tags)\n\n"+
-					"metric %s defined at %s:%d",
-				key,
-				tagKeyRegexp,
-				name,
-				file,
-				line,
-			))
-		}
-		if key != "" && seenKeys[key] {
-			panic(fmt.Sprintf(
-				"duplicate tag key %q\n\n"+
-					"metric %s defined at %s:%d",
-				key,
-				name,
-				file,
-				line,
-			))
-		}
-		seenKeys[key] = true
+	err := opts.registry.define(
+		metricType,
+		name,
+		description,
+		unit,
+		keys,
+		valueTypes,
+		opts,
+		file,
+		line,
+	)
+	if err != nil {
+		panic(fmt.Sprintf("%s\n\nmetric %s defined at %s:%d", err, name, file, line))
 	}
-
 	return true
 }
 
-// Defs returns metadata about all of the metric definitions in this process.
+// Defs returns metadata about all of the metric definitions in this process, keyed by the name they
+// are published under. This includes the definitions in the default registry and in every registry
+// made with NewNamespacedRegistry, but not those made with NewRegistry.
 //
 // Since metrics are registered during init-time, this should be called only after main() has
 // already begun.
 func Defs() map[string]Metadata {
-	result := make(map[string]Metadata)
-	defs.Range(func(name string, m *Metadata) bool {
-		result[name] = *m
-		return true
-	})
+	result := defaultRegistry.Defs()
+	for _, r := range namespacedRegistries() {
+		for name, md := range r.Defs() {
+			result[name] = md
+		}
+	}
 	return result
 }
 
@@ -827,6 +1126,19 @@ func DumpDefs() error {
 	return nil
 }
 
+func typeNames(types []reflect.Type) []string {
+	if types == nil {
+		return nil
+	}
+	names := make([]string, len(types))
+	for i, t := range types {
+		if t != nil {
+			names[i] = t.String()
+		}
+	}
+	return names
+}
+
 func join[E any](a []E, b []E) []E {
 	if len(a) == 0 {
 		return b
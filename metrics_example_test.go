@@ -29,3 +29,40 @@ func ExampleLinearBuckets() {
 	// LinearBuckets(100, 50, 3) -> [100 150 200]
 	// LinearBuckets(100, 75, 4) -> [100 175 250 325]
 }
+
+var serverResponsesDef = metrics.NewCounterDef(
+	"rpc.server.responses",
+	"The number of responses sent by the server.",
+	metrics.UnitResponse,
+	// This metric used to be called rpc_responses, and dashboards still use that name.
+	metrics.WithPreviousName("rpc_responses"),
+)
+
+func ExampleWithPreviousName() {
+	// Everything logged to m.Counter(serverResponsesDef) is published as both rpc.server.responses
+	// and rpc_responses, and metrics.DumpDefs() reports the relationship.
+	fmt.Println(metrics.Defs()["rpc.server.responses"].PreviousName)
+
+	// Output:
+	// rpc_responses
+}
+
+var (
+	rpclibRegistry = metrics.NewNamespacedRegistry("rpclib")
+
+	rpclibRequestsDef = metrics.NewCounterDef(
+		"requests",
+		"The number of requests sent by the RPC library.",
+		metrics.UnitRequest,
+		metrics.WithRegistry(rpclibRegistry),
+	)
+)
+
+func ExampleNewNamespacedRegistry() {
+	// m.Counter(rpclibRequestsDef) is published as rpclib.requests.
+	md := metrics.Defs()["rpclib.requests"]
+	fmt.Println(md.Name, md.Namespace)
+
+	// Output:
+	// rpclib.requests rpclib
+}
@@ -1,17 +1,21 @@
 package metrics
 
 import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestBucketedGaugeGroup(t *testing.T) {
 	d := GaugeDef1[string]{
-		name:          "test_bucketed_gauge_group",
-		keys:          [...]string{"bucket"},
-		allComparable: true,
-		ok:            true,
+		name: "test_bucketed_gauge_group",
+		keys: [...]string{"bucket"},
+		ok:   true,
 	}
 
 	gg := NewBucketedGaugeGroup(NoOpMetrics, d, []float64{1, 10, 100, 1000})
@@ -63,8 +67,7 @@ func TestBucketedGaugeGroup(t *testing.T) {
 			newMetricKey(
 				"test_bucketed_gauge_group",
 				1, // nTags
-				[maxTags]any{e.bucket},
-				true,
+				[maxTags]string{e.bucket},
 			),
 		)
 		if !ok {
@@ -76,12 +79,128 @@ func TestBucketedGaugeGroup(t *testing.T) {
 	}
 }
 
+func TestBucketedGaugeGroupConcurrent(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+	d := GaugeDef1[string]{
+		name: "test_bucketed_gauge_group_concurrent",
+		keys: [...]string{"bucket"},
+		ok:   true,
+	}
+	tags := []string{"bucket:lt_10"}
+
+	gg := NewBucketedGaugeGroup(m, d, []float64{10})
+	stop := gg.EmitEveryFlush()
+
+	gg.Observe(1)
+	gg.Observe(2)
+	m.Flush()
+	if v, _ := p.takeGauge(d.name, tags); v != 2 {
+		t.Fatalf("expected 2, got %f", v)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				gg.Observe(float64(j % 20))
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		m.Flush()
+	}
+	wg.Wait()
+
+	stop()
+	m.Flush()
+	before, _ := p.takeGauge(d.name, tags)
+	gg.Observe(1)
+	m.Flush()
+	if after, _ := p.takeGauge(d.name, tags); after != before {
+		t.Fatalf("expected %f after stopping, got %f", before, after)
+	}
+}
+
+func TestGaugeGroupConcurrent(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+	d := GaugeDef1[int]{
+		name: "test_gauge_group_concurrent",
+		keys: [...]string{"k"},
+		ok:   true,
+	}
+
+	gg := NewGaugeGroup1(m, d)
+	stop := gg.EmitEveryFlush()
+	defer stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				gg.Set(j%5, float64(j))
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		m.Flush()
+	}
+	wg.Wait()
+
+	gg.Set(7, 3)
+	m.Flush()
+	if v, ok := p.takeGauge(d.name, []string{"k:7"}); !ok || v != 3 {
+		t.Fatalf("expected 3, got %f (published: %t)", v, ok)
+	}
+	// Not Set since the last emit, so unset.
+	m.Flush()
+	if _, ok := p.takeGauge(d.name, []string{"k:7"}); ok {
+		t.Fatal("expected k:7 to be unset")
+	}
+}
+
+func TestGaugeGroup(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+	d := GaugeDef3[string, int, bool]{
+		name: "test_gauge_group",
+		keys: [...]string{"s", "i", "b"},
+		ok:   true,
+	}
+	gg := NewGaugeGroup3(m, d)
+
+	gg.Set("a", 1, true, 5)
+	gg.Set("b", 2, false, 6)
+	gg.EmitAndUnset()
+	m.Flush()
+	if v, ok := p.takeGauge(d.name, []string{"s:a", "i:1", "b:true"}); !ok || v != 5 {
+		t.Fatalf("expected 5, got %f (published: %t)", v, ok)
+	}
+	if v, ok := p.takeGauge(d.name, []string{"s:b", "i:2", "b:false"}); !ok || v != 6 {
+		t.Fatalf("expected 6, got %f (published: %t)", v, ok)
+	}
+
+	gg.Set("a", 1, true, 7)
+	gg.EmitAndUnset()
+	m.Flush()
+	if v, ok := p.takeGauge(d.name, []string{"s:a", "i:1", "b:true"}); !ok || v != 7 {
+		t.Fatalf("expected 7, got %f (published: %t)", v, ok)
+	}
+	if _, ok := p.takeGauge(d.name, []string{"s:b", "i:2", "b:false"}); ok {
+		t.Fatal("expected s:b i:2 b:false to be unset")
+	}
+}
+
 func TestBucketedCounter(t *testing.T) {
 	d := CounterDef1[string]{
-		name:          "test_bucketed_counter",
-		keys:          [...]string{"bucket"},
-		allComparable: true,
-		ok:            true,
+		name: "test_bucketed_counter",
+		keys: [...]string{"bucket"},
+		ok:   true,
 	}
 
 	bc := NewBucketedCounter(NoOpMetrics, d, []float64{1, 10, 100, 1000})
@@ -131,8 +250,7 @@ func TestBucketedCounter(t *testing.T) {
 			newMetricKey(
 				"test_bucketed_counter",
 				1, // nTags
-				[maxTags]any{e.bucket},
-				true, // allComparable
+				[maxTags]string{e.bucket},
 			),
 		)
 		if !ok {
@@ -145,14 +263,31 @@ func TestBucketedCounter(t *testing.T) {
 }
 
 type capturingPublisher struct {
-	mu       sync.Mutex
-	counters map[string]int64
+	mu            sync.Mutex
+	counters      map[string]int64
+	gauges        map[string]float64
+	distributions map[string][]float64
+}
+
+func newCapturingPublisher() *capturingPublisher {
+	return &capturingPublisher{
+		counters:      make(map[string]int64),
+		gauges:        make(map[string]float64),
+		distributions: make(map[string][]float64),
+	}
 }
 
 func (p *capturingPublisher) Gauge(name string, value float64, tags []string, rate float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.gauges[p.makeKey(name, tags)] = value
 	return nil
 }
 func (p *capturingPublisher) Distribution(name string, value float64, tags []string, rate float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := p.makeKey(name, tags)
+	p.distributions[k] = append(p.distributions[k], value)
 	return nil
 }
 func (p *capturingPublisher) Set(name string, value string, tags []string, rate float64) error {
@@ -173,9 +308,25 @@ func (p *capturingPublisher) countSeen(name string, tags []string) int64 {
 	return p.counters[p.makeKey(name, tags)]
 }
 
+// Returns the last value published for the given gauge and removes it, so that the next call can
+// tell whether the gauge was published again.
+func (p *capturingPublisher) takeGauge(name string, tags []string) (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := p.makeKey(name, tags)
+	v, ok := p.gauges[k]
+	delete(p.gauges, k)
+	return v, ok
+}
+func (p *capturingPublisher) distributionSeen(name string, tags []string) []float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.distributions[p.makeKey(name, tags)]
+}
+
 func TestMetrics(t *testing.T) {
-	p := capturingPublisher{counters: make(map[string]int64)}
-	m := New(&p)
+	p := newCapturingPublisher()
+	m := New(p)
 
 	def := CounterDef3[string, int, bool]{
 		name: "test_metrics_counter",
@@ -205,6 +356,313 @@ func TestMetrics(t *testing.T) {
 	}
 }
 
+func TestTime(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+
+	def := DistributionDef1[string]{
+		name: "test_time",
+		unit: UnitMillisecond,
+		keys: [...]string{"outcome"},
+		ok:   true,
+	}
+	okD := m.Distribution(def.Values("ok"))
+	errorD := m.Distribution(def.Values("error"))
+
+	func() {
+		defer okD.Time()()
+		time.Sleep(time.Millisecond)
+	}()
+	TimeFunc(okD, func() {})
+	_ = TimeFuncOutcome(okD, errorD, func() error { return nil })
+	err := TimeFuncOutcome(okD, errorD, func() error { return errors.New("oops") })
+	if err == nil {
+		t.Fatal("expected TimeFuncOutcome to pass through the error from f")
+	}
+
+	okSeen := p.distributionSeen(def.name, []string{"outcome:ok"})
+	if len(okSeen) != 3 {
+		t.Fatalf("expected 3 observations for outcome:ok, got %d", len(okSeen))
+	}
+	if okSeen[0] < 1 {
+		t.Fatalf("expected first observation to be at least 1ms, got %f", okSeen[0])
+	}
+	errorSeen := p.distributionSeen(def.name, []string{"outcome:error"})
+	if len(errorSeen) != 1 {
+		t.Fatalf("expected 1 observation for outcome:error, got %d", len(errorSeen))
+	}
+}
+
+func TestGaugeAggregationMeanConcurrent(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+	g := m.Gauge(GaugeDef{
+		name:        "test_gauge_mean_concurrent",
+		aggregation: GaugeAggregationMean,
+		ok:          true,
+	})
+	const lo, hi = 10, 20
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; ; j++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				g.Set(float64(lo + j%(hi-lo+1)))
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		m.Flush()
+		v, ok := p.takeGauge(g.name, nil)
+		if ok && (v < lo || v > hi) {
+			close(stop)
+			wg.Wait()
+			t.Fatalf("mean %f is outside of the range of observed values [%d, %d]", v, lo, hi)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestGaugeAggregation(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+
+	check := func(g *Gauge, expected float64) {
+		t.Helper()
+		v, ok := p.takeGauge(g.name, nil)
+		if math.IsNaN(expected) {
+			if ok {
+				t.Fatalf("%s: expected no value, got %f", g.name, v)
+			}
+			return
+		}
+		if !ok {
+			t.Fatalf("%s: expected %f, got no value", g.name, expected)
+		}
+		if v != expected {
+			t.Fatalf("%s: expected %f, got %f", g.name, expected, v)
+		}
+	}
+
+	last := m.Gauge(GaugeDef{name: "test_gauge_last", ok: true})
+	max := m.Gauge(GaugeDef{
+		name:        "test_gauge_max",
+		aggregation: GaugeAggregationMax,
+		ok:          true,
+	})
+	min := m.Gauge(GaugeDef{
+		name:        "test_gauge_min",
+		aggregation: GaugeAggregationMin,
+		ok:          true,
+	})
+	mean := m.Gauge(GaugeDef{
+		name:        "test_gauge_mean",
+		aggregation: GaugeAggregationMean,
+		ok:          true,
+	})
+	sum := m.Gauge(GaugeDef{
+		name:        "test_gauge_sum",
+		aggregation: GaugeAggregationSum,
+		ok:          true,
+	})
+	all := []*Gauge{last, max, min, mean, sum}
+
+	m.Flush()
+	for _, g := range all {
+		check(g, math.NaN())
+	}
+
+	// Add counts as a Set to the resulting value, 2.
+	for _, g := range all {
+		g.Set(4)
+		g.Set(10)
+		g.Add(-8)
+	}
+	m.Flush()
+	check(last, 2)
+	check(max, 10)
+	check(min, 2)
+	check(mean, 16.0/3)
+	check(sum, 16)
+
+	// Nothing was set during this interval, so max, min, and mean carry over the current value.
+	m.Flush()
+	check(last, 2)
+	check(max, 2)
+	check(min, 2)
+	check(mean, 2)
+	check(sum, 0)
+
+	for _, g := range all {
+		g.Add(3)
+		g.Add(1)
+	}
+	m.Flush()
+	check(last, 6)
+	check(max, 6)
+	check(min, 2)
+	check(mean, 5.5)
+	check(sum, 11)
+
+	for _, g := range all {
+		g.Set(5)
+		g.Unset()
+	}
+	m.Flush()
+	for _, g := range all {
+		check(g, math.NaN())
+	}
+}
+
+func TestGaugeOnlyOnChange(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p, WithGaugeHeartbeat(3*flushInterval))
+
+	g := m.Gauge(GaugeDef{name: "test_gauge_only_on_change", onlyOnChange: true, ok: true})
+	always := m.Gauge(GaugeDef{name: "test_gauge_always", ok: true})
+
+	check := func(expectPublished bool) {
+		t.Helper()
+		m.Flush()
+		_, ok := p.takeGauge(g.name, nil)
+		if ok != expectPublished {
+			t.Fatalf("expected published=%t, got %t", expectPublished, ok)
+		}
+		_, ok = p.takeGauge(always.name, nil)
+		if !ok {
+			t.Fatal("gauge without onlyOnChange should publish on every flush")
+		}
+	}
+
+	g.Set(1)
+	always.Set(1)
+	check(true)
+	check(false)
+	check(false)
+	// Heartbeat.
+	check(true)
+	check(false)
+
+	g.Set(2)
+	check(true)
+	g.Set(2)
+	check(false)
+
+	// Unsetting and setting again to the same value publishes immediately.
+	g.Unset()
+	m.Flush()
+	g.Set(2)
+	check(true)
+}
+
+func TestBuildInfo(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+	m.Flush()
+
+	bi := readBuildInfo()
+	v, ok := p.takeGauge("metrics.build_info", makeTags(
+		buildInfoDef.keys[:],
+		[]string{
+			tagValueSanitize(bi.revision),
+			tagValueSanitize(bi.goVersion),
+			tagValueSanitize(bi.moduleVersion),
+			strconv.FormatBool(bi.dirty),
+		},
+	))
+	if !ok {
+		t.Fatal("metrics.build_info not published")
+	}
+	if v != 1 {
+		t.Fatalf("expected metrics.build_info to be 1, got %f", v)
+	}
+}
+
+func TestFeatureFlagGroup(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+
+	d := GaugeDef2[string, string]{
+		name: "test_feature_flags",
+		keys: [...]string{"flag", "value"},
+		ok:   true,
+	}
+	g := NewFeatureFlagGroup(m, d)
+
+	check := func(name string, value string, expectPublished bool) {
+		t.Helper()
+		_, ok := p.takeGauge(d.name, []string{"flag:" + name, "value:" + value})
+		if ok != expectPublished {
+			t.Fatalf("flag:%s value:%s expected published=%t, got %t", name, value, expectPublished, ok)
+		}
+	}
+
+	g.Emit(map[string]string{"a": "true", "b": "fast"})
+	m.Flush()
+	check("a", "true", true)
+	check("b", "fast", true)
+
+	g.Emit(map[string]string{"b": "slow"})
+	m.Flush()
+	check("a", "true", false)
+	check("b", "fast", false)
+	check("b", "slow", true)
+}
+
+func TestInFlight(t *testing.T) {
+	m := New(noOpPublisher{})
+
+	f := NewInFlight(
+		m,
+		GaugeDef{name: "test_in_flight", ok: true},
+		GaugeDef{name: "test_in_flight_peak", ok: true},
+	)
+	defer f.Close()
+
+	check := func(expectedCurr float64, expectedPeak float64) {
+		t.Helper()
+		m.Flush()
+		if f.gauge.value() != expectedCurr {
+			t.Fatalf("expected in flight %f, got %f", expectedCurr, f.gauge.value())
+		}
+		if f.peakGauge.value() != expectedPeak {
+			t.Fatalf("expected peak %f, got %f", expectedPeak, f.peakGauge.value())
+		}
+	}
+
+	check(0, 0)
+
+	done1 := f.Start()
+	done2 := f.Start()
+	done3 := f.Start()
+	done3()
+	check(2, 3)
+	// Peak resets to the current value after each flush.
+	check(2, 2)
+
+	done2()
+	done2()
+	done1()
+	check(0, 2)
+	check(0, 0)
+
+	// A stray extra call to a done func must not push the count negative.
+	done1()
+	if f.Current() != 0 {
+		t.Fatalf("expected 0 in flight, got %d", f.Current())
+	}
+}
+
 func TestTagValueSanitize(t *testing.T) {
 	check := func(
 		s string,
@@ -288,12 +746,12 @@ func BenchmarkReport(b *testing.B) {
 func BenchmarkMetricLookup(b *testing.B) {
 	m := New(noOpPublisher{})
 	b.ReportAllocs()
-	d := CounterDef3[string, int, bool]{
-		name:          "benchmark_metric_lookup",
-		keys:          [...]string{"", "", ""},
-		allComparable: true,
-		ok:            true,
-	}
+	d := makeCounterDef3[string, int, bool](
+		"benchmark_metric_lookup",
+		[...]string{"", "", ""},
+		makeDefOptions(nil),
+		true,
+	)
 
 	foo := "foo"
 
@@ -302,3 +760,250 @@ func BenchmarkMetricLookup(b *testing.B) {
 		m.Counter(withValues)
 	}
 }
+
+func BenchmarkBoundMetricLookup(b *testing.B) {
+	m := New(noOpPublisher{})
+	d := makeCounterDef3[string, int, bool](
+		"benchmark_bound_metric_lookup",
+		[...]string{"", "", ""},
+		makeDefOptions(nil),
+		true,
+	)
+	bound := d.Bind(m)
+
+	foo := "foo"
+
+	b.Run("Serial", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bound.Get(foo, 1, false)
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				bound.Get(foo, 1, false)
+			}
+		})
+	})
+}
+
+func TestBind(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+	d := makeCounterDef2[string, int](
+		"test_bind",
+		[...]string{"s", "i"},
+		makeDefOptions(nil),
+		true,
+	)
+	bound := d.Bind(m)
+
+	c := bound.Get("a", 1)
+	if c != m.Counter(d.Values("a", 1)) {
+		t.Fatal("expected the same counter as Metrics.Counter")
+	}
+	if bound.Get("a", 2) == c {
+		t.Fatal("expected a different counter for different tag values")
+	}
+	// Different values that format the same are still the same metric.
+	if bound.Get("A", 1) != c {
+		t.Fatal("expected the same counter for tag values that format the same")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		bound.Get("a", 1).Add(1)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected no allocations, got %f", allocs)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				bound.Get("b", j).Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	m.Flush()
+	if n := p.countSeen("test_bind", []string{"s:b", "i:7"}); n != 10 {
+		t.Fatalf("expected 10, got %d", n)
+	}
+}
+
+// Zero defs have no formatters, so their tag values go through formatTag's fallback. See also
+// TestTagValuesWithoutFormatters.
+func TestZeroDef(t *testing.T) {
+	m := New(newCapturingPublisher())
+
+	var d CounterDef2[string, int]
+	if m.Counter(d.Values("a", 1)) != noOpCounter {
+		t.Fatal("expected a no-op counter from a zero def")
+	}
+	if m.Counter(d.Prefix1("a").Values(1)) != noOpCounter {
+		t.Fatal("expected a no-op counter from a prefix of a zero def")
+	}
+	if d.Bind(m).Get("a", 1) != noOpCounter {
+		t.Fatal("expected a no-op counter from a bound zero def")
+	}
+
+	failed, err := RegisterCounterDef1[string](
+		NewRegistry(),
+		"Not A Name",
+		"",
+		NoUnits,
+		[...]string{"k"},
+	)
+	if err == nil {
+		t.Fatal("expected an error for an invalid name")
+	}
+	if m.Counter(failed.Values("a")) != noOpCounter {
+		t.Fatal("expected a no-op counter from a failed registration")
+	}
+}
+
+func TestDefOptionsValidate(t *testing.T) {
+	for _, tc := range []struct {
+		opts    []DefOption
+		invalid bool
+	}{
+		{opts: []DefOption{
+			WithOwner("team-storage"),
+			WithRunbook("https://example.com/runbooks/queue"),
+			WithSLO("queue-availability"),
+			WithSLO("queue-latency"),
+			WithStability(StabilityStable),
+			WithDeprecated("queue_depth_v2", "2030-01-31"),
+		}},
+		{opts: []DefOption{WithDeprecated("", "")}},
+		{opts: []DefOption{WithShardedCounter()}},
+		{opts: []DefOption{WithRunbook("runbooks/queue")}, invalid: true},
+		{opts: []DefOption{WithStability("beta")}, invalid: true},
+		{opts: []DefOption{WithDeprecated("Not A Name", "")}, invalid: true},
+		{opts: []DefOption{WithDeprecated("", "Jan 31 2030")}, invalid: true},
+		{opts: []DefOption{WithGaugeAggregation(GaugeAggregationMax)}, invalid: true},
+	} {
+		o := makeDefOptions(tc.opts)
+		reason := o.validate(CounterType)
+		if tc.invalid && reason == "" {
+			t.Errorf("expected %#v to be invalid", o)
+		} else if !tc.invalid && reason != "" {
+			t.Errorf("expected %#v to be valid, got %s", o, reason)
+		}
+	}
+
+	o := makeDefOptions([]DefOption{WithSLO("a"), WithSLO("b")})
+	if !slices.Equal(o.slos, []string{"a", "b"}) {
+		t.Fatalf("expected both SLOs, got %v", o.slos)
+	}
+}
+
+func TestPreviousName(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+
+	counterDef := CounterDef1[string]{
+		name:         "test_renamed_counter",
+		previousName: "test_old_counter",
+		keys:         [...]string{"k"},
+		ok:           true,
+	}
+	m.Counter(counterDef.Values("v")).Add(3)
+	distributionDef := DistributionDef{
+		name:         "test_renamed_distribution",
+		previousName: "test_old_distribution",
+		unit:         UnitSecond,
+		ok:           true,
+	}
+	m.Distribution(distributionDef).ObserveDuration(2 * time.Second)
+	gaugeDef := GaugeDef{
+		name:         "test_renamed_gauge",
+		previousName: "test_old_gauge",
+		ok:           true,
+	}
+	m.Gauge(gaugeDef).Set(5)
+	m.Flush()
+
+	for _, name := range []string{"test_renamed_counter", "test_old_counter"} {
+		if n := p.countSeen(name, []string{"k:v"}); n != 3 {
+			t.Fatalf("expected %s to be 3, got %d", name, n)
+		}
+	}
+	for _, name := range []string{"test_renamed_distribution", "test_old_distribution"} {
+		if vs := p.distributionSeen(name, nil); !slices.Equal(vs, []float64{2}) {
+			t.Fatalf("expected %s to be [2], got %v", name, vs)
+		}
+	}
+	for _, name := range []string{"test_renamed_gauge", "test_old_gauge"} {
+		if v, ok := p.takeGauge(name, nil); !ok || v != 5 {
+			t.Fatalf("expected %s to be 5, got %v (%t)", name, v, ok)
+		}
+	}
+}
+
+func BenchmarkMetricLookupManyTags(b *testing.B) {
+	m := New(noOpPublisher{})
+	b.ReportAllocs()
+	d := makeCounterDef8[string, int, bool, string, int, bool, string, int](
+		"benchmark_metric_lookup_many_tags",
+		[...]string{"a", "b", "c", "d", "e", "f", "g", "h"},
+		makeDefOptions(nil),
+		true,
+	)
+
+	foo := "foo"
+
+	for i := 0; i < b.N; i++ {
+		withValues := d.Values(foo, 1, false, foo, 2, true, foo, 3)
+		m.Counter(withValues)
+	}
+}
+
+func TestManyTags(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+	d := makeCounterDef8[string, int, bool, string, int, bool, string, int](
+		"test_many_tags",
+		[...]string{"a", "b", "c", "d", "e", "f", "g", "h"},
+		makeDefOptions(nil),
+		true,
+	)
+
+	// Tags past those that fit directly in the key still distinguish metrics.
+	c1 := m.Counter(d.Values("x", 1, false, "y", 2, true, "z", 3))
+	c2 := m.Counter(d.Values("x", 1, false, "y", 2, true, "z", 4))
+	if c1 == c2 {
+		t.Fatal("expected metrics differing only in the last tag to be different")
+	}
+	if c1 != m.Counter(d.Prefix3("x", 1, false).Values("y", 2, true, "z", 3)) {
+		t.Fatal("expected the same metric for the same tag values")
+	}
+
+	c1.Add(1)
+	c2.Add(2)
+	m.Flush()
+	tags := []string{"a:x", "b:1", "c:false", "d:y", "e:2", "f:true", "g:z", "h:4"}
+	if n := p.countSeen("test_many_tags", tags); n != 2 {
+		t.Fatalf("expected 2, got %d", n)
+	}
+
+	// Metrics with few tags still don't allocate to look up.
+	small := makeCounterDef2[int, bool](
+		"test_few_tags",
+		[...]string{"a", "b"},
+		makeDefOptions(nil),
+		true,
+	)
+	m.Counter(small.Values(1, true))
+	allocs := testing.AllocsPerRun(100, func() {
+		m.Counter(small.Values(1, true))
+	})
+	if allocs != 0 {
+		t.Fatalf("expected no allocations looking up a metric with few tags, got %v", allocs)
+	}
+}
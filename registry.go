@@ -0,0 +1,262 @@
+package metrics
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/bradenaw/juniper/xslices"
+	"golang.org/x/exp/maps"
+)
+
+// Registry is a set of metric definitions.
+//
+// Definitions made with the NewMDefY functions go in the default registry, which requires them to
+// be made at init time in a file named metrics.go so that every metric in a program is easy to
+// find, and panics if they're invalid. Some programs need to define metrics after main() has
+// started, for example plugin systems or metrics generated from per-tenant configuration. They can
+// instead make a Registry with NewRegistry and define metrics in it with the RegisterMDefY
+// functions, which can be called at any time from any file and return an error if the definition is
+// invalid.
+//
+// Libraries can use NewNamespacedRegistry to keep their metrics separate from those of the programs
+// that use them, see WithRegistry.
+//
+// Names must be unique across all registries, since they're all published to the same place. A
+// registry keeps its names until it's closed, see Close.
+type Registry struct {
+	// If non-empty, prefixed to the names of every metric in the registry when published.
+	namespace string
+
+	m    sync.Mutex
+	defs map[string]*Metadata
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		defs: make(map[string]*Metadata),
+	}
+}
+
+// NewNamespacedRegistry returns an empty Registry whose metrics are all published with the given
+// namespace as a prefix, separated by a dot. For example, a library might define its metrics as:
+//
+//	// ---- metrics.go -----------------------------------------------------------------------------
+//	var (
+//		registry = metrics.NewNamespacedRegistry("rpclib")
+//
+//		// Published as rpclib.requests.
+//		requestsDef = metrics.NewCounterDef(
+//			"requests",
+//			"The number of requests sent.",
+//			metrics.UnitRequest,
+//			metrics.WithRegistry(registry),
+//		)
+//	)
+//
+// Unlike other registries, the definitions in namespaced registries are included in Defs() and
+// DumpDefs(), marked with their namespace.
+//
+// NewNamespacedRegistry panics if the namespace isn't a valid metric name or is already in use,
+// since namespaced registries are made at init time alongside the definitions that use them.
+func NewNamespacedRegistry(namespace string) *Registry {
+	if !nameRegexp.MatchString(namespace) || strings.HasSuffix(namespace, ".") {
+		panic(fmt.Sprintf("namespace %q doesn't match required %s", namespace, nameRegexp))
+	}
+	r := NewRegistry()
+	r.namespace = namespace
+
+	namespacesMu.Lock()
+	defer namespacesMu.Unlock()
+	if _, ok := namespaces[namespace]; ok {
+		panic(fmt.Sprintf("multiple registries for namespace %s", namespace))
+	}
+	namespaces[namespace] = r
+	return r
+}
+
+var (
+	// The registry for the NewMDefY functions.
+	defaultRegistry = NewRegistry()
+
+	namespacesMu sync.Mutex
+	// Every registry made with NewNamespacedRegistry, by namespace.
+	namespaces = make(map[string]*Registry)
+
+	// Held while defining a metric so that checking for name collisions across registries is
+	// atomic.
+	defineMu sync.Mutex
+	// Every name that a def in any registry publishes to, including previous names, so that no two
+	// defs publish to the same name. Guarded by defineMu.
+	publishedNames = make(map[string]*Metadata)
+)
+
+func namespacedRegistries() []*Registry {
+	namespacesMu.Lock()
+	defer namespacesMu.Unlock()
+	return maps.Values(namespaces)
+}
+
+// publishedName returns the name that a metric called name in r is published as.
+func (r *Registry) publishedName(name string) string {
+	if r.namespace == "" {
+		return name
+	}
+	return r.namespace + "." + name
+}
+
+// Defs returns metadata about all of the metric definitions in r.
+func (r *Registry) Defs() map[string]Metadata {
+	r.m.Lock()
+	defer r.m.Unlock()
+	result := make(map[string]Metadata, len(r.defs))
+	for name, md := range r.defs {
+		result[name] = *md
+	}
+	return result
+}
+
+// Close removes all of the definitions from r and releases their names so that they can be defined
+// again, for example by a new Registry that replaces r after reloading configuration. The defs from
+// r and the metrics made from them should no longer be used, since they publish to the same names.
+func (r *Registry) Close() {
+	defineMu.Lock()
+	defer defineMu.Unlock()
+	r.m.Lock()
+	defer r.m.Unlock()
+	for name, md := range r.defs {
+		delete(publishedNames, name)
+		if md.PreviousName != "" {
+			delete(publishedNames, md.PreviousName)
+		}
+	}
+	r.defs = make(map[string]*Metadata)
+}
+
+// define adds a definition to r, or returns an error explaining why it's invalid.
+func (r *Registry) define(
+	metricType MetricType,
+	name string,
+	description string,
+	unit Unit,
+	keys []string,
+	valueTypes []reflect.Type,
+	opts defOptions,
+	file string,
+	line int,
+) error {
+	name = r.publishedName(name)
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf(
+			"metric definition's name %q doesn't match required %s (see "+
+				"https://docs.datadoghq.com/metrics/custom_metrics/#naming-custom-metrics)",
+			name,
+			nameRegexp,
+		)
+	}
+	if len(description) > 400 {
+		return fmt.Errorf(
+			"metric descriptions cannot be more than 400 characters, this one is %d",
+			len(description),
+		)
+	}
+	if reason := opts.validate(metricType); reason != "" {
+		return fmt.Errorf("invalid metric definition options: %s", reason)
+	}
+	previousName := opts.previousName
+	if previousName == name {
+		return fmt.Errorf("metric's previous name is the same as its name")
+	}
+
+	seenKeys := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		_, ok := reservedTagKeys[key]
+		if ok {
+			return fmt.Errorf(
+				"metric used reserved tag key %q (see "+
+					"https://docs.datadoghq.com/getting_started/tagging/#overview)",
+				key,
+			)
+		}
+		if !tagKeyRegexp.MatchString(key) {
+			return fmt.Errorf(
+				"metric tag key %q doesn't match %s (see "+
+					"https://docs.datadoghq.com/getting_started/tagging/#define-tags)",
+				key,
+				tagKeyRegexp,
+			)
+		}
+		if key != "" && seenKeys[key] {
+			return fmt.Errorf("duplicate tag key %q", key)
+		}
+		seenKeys[key] = true
+	}
+
+	md := &Metadata{
+		MetricType:  metricType,
+		Name:        name,
+		Description: description,
+		Unit:        unit,
+		Keys:        xslices.Clone(keys),
+		ValueTypes:  valueTypes,
+		File:        file,
+		Line:        line,
+
+		ValueTypeNames:      typeNames(valueTypes),
+		GaugeAggregation:    opts.gaugeAggregation,
+		PublishOnlyOnChange: opts.onlyOnChange,
+		PerUnit:             opts.perUnit,
+		ShortName:           opts.shortName,
+		Owner:               opts.owner,
+		RunbookURL:          opts.runbookURL,
+		SLOs:                opts.slos,
+		Stability:           opts.stability,
+		Deprecation:         opts.deprecation,
+		PreviousName:        previousName,
+		Namespace:           r.namespace,
+	}
+	names := []string{name}
+	if previousName != "" {
+		names = append(names, previousName)
+	}
+
+	defineMu.Lock()
+	defer defineMu.Unlock()
+	for _, n := range names {
+		existing, ok := publishedNames[n]
+		if ok {
+			return fmt.Errorf(
+				"multiple definitions for metric %s:\n"+
+					"\t%s:%d\n"+
+					"\t%s:%d",
+				n,
+				existing.File, existing.Line,
+				file, line,
+			)
+		}
+	}
+	for _, n := range names {
+		publishedNames[n] = md
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.defs[name] = md
+	return nil
+}
+
+// register adds a definition to r from a RegisterMDefY function, using the location of its caller.
+func (r *Registry) register(
+	metricType MetricType,
+	name string,
+	description string,
+	unit Unit,
+	keys []string,
+	valueTypes []reflect.Type,
+	opts defOptions,
+) error {
+	_, file, line, _ := runtime.Caller(2)
+	return r.define(metricType, name, description, unit, keys, valueTypes, opts, file, line)
+}
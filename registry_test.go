@@ -0,0 +1,213 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	// Unlike the default registry, definitions can be made after init and outside of metrics.go.
+	counterDef, err := RegisterCounterDef1[string](
+		r,
+		"test_registry_counter",
+		"A counter defined at runtime.",
+		UnitRequest,
+		[...]string{"tenant"},
+		WithPreviousName("test_registry_counter_old"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gaugeDef, err := RegisterGaugeDef(r, "test_registry_gauge", "A gauge defined at runtime.", UnitItem)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := newCapturingPublisher()
+	m := New(p)
+	m.Counter(counterDef.Values("a")).Add(2)
+	m.Gauge(gaugeDef).Set(3)
+	m.Flush()
+	if n := p.countSeen("test_registry_counter", []string{"tenant:a"}); n != 2 {
+		t.Fatalf("expected counter to be 2, got %d", n)
+	}
+	if v, ok := p.takeGauge("test_registry_gauge", nil); !ok || v != 3 {
+		t.Fatalf("expected gauge to be 3, got %v (%t)", v, ok)
+	}
+
+	md, ok := r.Defs()["test_registry_counter"]
+	if !ok {
+		t.Fatal("expected counter in r.Defs()")
+	}
+	if !strings.HasSuffix(md.File, "registry_test.go") {
+		t.Fatalf("expected def to be located in registry_test.go, got %s", md.File)
+	}
+	if _, ok := Defs()["test_registry_counter"]; ok {
+		t.Fatal("expected counter not to be in the default registry")
+	}
+
+	for _, tc := range []struct {
+		name        string
+		register    func() error
+		errContains string
+	}{
+		{
+			name: "BadName",
+			register: func() error {
+				_, err := RegisterCounterDef(r, "Bad Name", "", NoUnits)
+				return err
+			},
+			errContains: "doesn't match required",
+		},
+		{
+			name: "Duplicate",
+			register: func() error {
+				_, err := RegisterCounterDef(r, "test_registry_gauge", "", NoUnits)
+				return err
+			},
+			errContains: "multiple definitions for metric test_registry_gauge",
+		},
+		{
+			name: "DuplicatePreviousName",
+			register: func() error {
+				_, err := RegisterCounterDef(r, "test_registry_counter_old", "", NoUnits)
+				return err
+			},
+			errContains: "multiple definitions for metric test_registry_counter_old",
+		},
+		{
+			name: "DuplicateDefaultRegistry",
+			register: func() error {
+				_, err := RegisterGaugeDef(r, "metrics.build_info", "", NoUnits)
+				return err
+			},
+			errContains: "multiple definitions for metric metrics.build_info",
+		},
+		{
+			name: "DuplicateOtherRegistry",
+			register: func() error {
+				_, err := RegisterGaugeDef(NewRegistry(), "test_registry_gauge", "", NoUnits)
+				return err
+			},
+			errContains: "multiple definitions for metric test_registry_gauge",
+		},
+		{
+			name: "ReservedTagKey",
+			register: func() error {
+				_, err := RegisterGaugeDef1[string](r, "test_registry_host", "", NoUnits, [...]string{"host"})
+				return err
+			},
+			errContains: "reserved tag key",
+		},
+		{
+			name: "BadOption",
+			register: func() error {
+				_, err := RegisterCounterDef(
+					r,
+					"test_registry_bad_option",
+					"",
+					NoUnits,
+					WithPublishOnlyOnChange(),
+				)
+				return err
+			},
+			errContains: "invalid metric definition options",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.register()
+			if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+				t.Fatalf("expected error containing %q, got %v", tc.errContains, err)
+			}
+		})
+	}
+
+	// Failed definitions don't reserve their names.
+	_, err = RegisterCounterDef(r, "test_registry_bad_option", "", NoUnits)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistryClose(t *testing.T) {
+	register := func(r *Registry) error {
+		_, err := RegisterCounterDef(
+			r,
+			"test_registry_close",
+			"",
+			NoUnits,
+			WithPreviousName("test_registry_close_old"),
+		)
+		return err
+	}
+
+	r := NewRegistry()
+	err := register(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := NewRegistry()
+	err = register(reloaded)
+	if err == nil || !strings.Contains(err.Error(), "multiple definitions") {
+		t.Fatalf("expected collision with open registry, got %v", err)
+	}
+
+	r.Close()
+	if defs := r.Defs(); len(defs) != 0 {
+		t.Fatalf("expected no defs after Close, got %v", defs)
+	}
+	err = register(reloaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNamespacedRegistry(t *testing.T) {
+	r := NewNamespacedRegistry("test_namespace")
+	counterDef, err := RegisterCounterDef(
+		r,
+		"requests",
+		"A counter in a namespace.",
+		UnitRequest,
+		// Moved from outside of the namespace.
+		WithPreviousName("test_namespace_requests"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := newCapturingPublisher()
+	m := New(p)
+	m.Counter(counterDef).Add(1)
+	m.Flush()
+	for _, name := range []string{"test_namespace.requests", "test_namespace_requests"} {
+		if n := p.countSeen(name, nil); n != 1 {
+			t.Fatalf("expected %s to be 1, got %d", name, n)
+		}
+	}
+
+	md, ok := Defs()["test_namespace.requests"]
+	if !ok {
+		t.Fatal("expected namespaced def in Defs()")
+	}
+	if md.Namespace != "test_namespace" || md.PreviousName != "test_namespace_requests" {
+		t.Fatalf("unexpected metadata %#v", md)
+	}
+
+	// Names can't collide with those in other registries.
+	_, err = RegisterCounterDef(NewRegistry(), "test_namespace.requests", "", NoUnits)
+	if err == nil || !strings.Contains(err.Error(), "multiple definitions") {
+		t.Fatalf("expected collision with namespaced registry, got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic for duplicate namespace")
+			}
+		}()
+		NewNamespacedRegistry("test_namespace")
+	}()
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+)
+
+// The size of a cache line on most CPUs that this runs on. Shards are padded to this size so that
+// adding to one doesn't contend with adding to its neighbors.
+const cacheLineSize = 64
+
+type counterShard struct {
+	v atomic.Int64
+	_ [cacheLineSize - 8]byte
+}
+
+// counterShards is the value of a counter defined with WithShardedCounter, striped across shards.
+type counterShards struct {
+	shards []counterShard
+	// len(shards)-1, where len(shards) is a power of two.
+	mask uint32
+}
+
+func newCounterShards() *counterShards {
+	n := 1 << bits.Len(uint(runtime.GOMAXPROCS(0)-1))
+	return &counterShards{
+		shards: make([]counterShard, n),
+		mask:   uint32(n - 1),
+	}
+}
+
+func (s *counterShards) add(n int64) {
+	// There's no way to ask which P the goroutine is running on, but rand's state is per-thread and
+	// so is cheap to use without contention, and picking a shard at random spreads concurrent adds
+	// across shards about as well.
+	s.shards[rand.Uint32()&s.mask].v.Add(n)
+}
+
+// swap returns the total of all of the shards and resets them to zero.
+func (s *counterShards) swap() int64 {
+	var total int64
+	for i := range s.shards {
+		total += s.shards[i].v.Swap(0)
+	}
+	return total
+}
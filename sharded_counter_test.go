@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestShardedCounter(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+	d := CounterDef2[string, string]{
+		name:    "test_sharded_counter",
+		keys:    [...]string{"k", "l"},
+		sharded: true,
+		ok:      true,
+	}
+	c := m.Counter(d.Values("v", "w"))
+	if c.shards == nil {
+		t.Fatal("expected a sharded counter")
+	}
+	if m.Counter(d.Prefix1("v").Values("x")).shards == nil {
+		t.Fatal("expected counters from a prefix to also be sharded")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Add(2)
+			}
+		}()
+	}
+	wg.Wait()
+	m.Flush()
+	if n := p.countSeen("test_sharded_counter", []string{"k:v", "l:w"}); n != 20000 {
+		t.Fatalf("expected 20000, got %d", n)
+	}
+
+	// Publishing resets every shard.
+	c.Add(1)
+	m.Flush()
+	if n := p.countSeen("test_sharded_counter", []string{"k:v", "l:w"}); n != 20001 {
+		t.Fatalf("expected 20001, got %d", n)
+	}
+}
+
+func BenchmarkCounterAdd(b *testing.B) {
+	m := New(noOpPublisher{})
+	for _, tc := range []struct {
+		name    string
+		sharded bool
+	}{
+		{"Unsharded", false},
+		{"Sharded", true},
+	} {
+		d := CounterDef1[string]{
+			name:    "benchmark_counter_add_" + tc.name,
+			keys:    [...]string{"k"},
+			sharded: tc.sharded,
+			ok:      true,
+		}
+		c := m.Counter(d.Values("v"))
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.Add(1)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"unsafe"
+)
+
+// AnyTagValue opts in to using a value of any type as a TagValue, for defs whose type parameter is
+// AnyTagValue, for example:
+//
+//	// ---- metrics.go -----------------------------------------------------------------------------
+//	regionRequestsDef = metrics.NewCounterDef1[metrics.AnyTagValue](
+//		"region_requests",
+//		"Counts requests by region.",
+//		metrics.UnitRequest,
+//		[...]string{"region"},
+//	)
+//
+//	// ---- at the point of logging the metric -----------------------------------------------------
+//	m.Counter(regionRequestsDef.Values(metrics.Any(region))).Add(1)
+//
+// The value is formatted as described on TagValue when it's passed to Any(), with a type switch and
+// fmt.Sprint, so it's slower than the other TagValue types.
+type AnyTagValue struct {
+	s string
+}
+
+// Any returns v as an AnyTagValue.
+func Any(v any) AnyTagValue {
+	return AnyTagValue{s: tagValueString(v)}
+}
+
+// tagFormatter returns a function that formats values of type V as tag values. It's called once per
+// def so that the function can be chosen using reflection without paying for it on every call.
+func tagFormatter[V TagValue]() func(V) string {
+	var zero V
+	switch any(zero).(type) {
+	case AnyTagValue:
+		return func(v V) string { return any(v).(AnyTagValue).s }
+	case TagValuer:
+		return func(v V) string { return tagValueSanitize(any(v).(TagValuer).MetricTagValue()) }
+	case fmt.Stringer:
+		return func(v V) string { return tagValueSanitize(any(v).(fmt.Stringer).String()) }
+	}
+
+	// Every other type in TagValue has one of these underlying types, so its value can be read as
+	// that type directly.
+	switch reflect.TypeOf(zero).Kind() {
+	case reflect.String:
+		return func(v V) string { return tagValueSanitize(as[string](v)) }
+	case reflect.Bool:
+		return func(v V) string { return strconv.FormatBool(as[bool](v)) }
+	case reflect.Int:
+		return func(v V) string { return strconv.FormatInt(int64(as[int](v)), 10) }
+	case reflect.Int8:
+		return func(v V) string { return strconv.FormatInt(int64(as[int8](v)), 10) }
+	case reflect.Int16:
+		return func(v V) string { return strconv.FormatInt(int64(as[int16](v)), 10) }
+	case reflect.Int32:
+		return func(v V) string { return strconv.FormatInt(int64(as[int32](v)), 10) }
+	case reflect.Int64:
+		return func(v V) string { return strconv.FormatInt(as[int64](v), 10) }
+	case reflect.Uint:
+		return func(v V) string { return strconv.FormatUint(uint64(as[uint](v)), 10) }
+	case reflect.Uint8:
+		return func(v V) string { return strconv.FormatUint(uint64(as[uint8](v)), 10) }
+	case reflect.Uint16:
+		return func(v V) string { return strconv.FormatUint(uint64(as[uint16](v)), 10) }
+	case reflect.Uint32:
+		return func(v V) string { return strconv.FormatUint(uint64(as[uint32](v)), 10) }
+	case reflect.Uint64:
+		return func(v V) string { return strconv.FormatUint(as[uint64](v), 10) }
+	}
+	panic(fmt.Sprintf("unreachable: %T is not a TagValue", zero))
+}
+
+// formatTag formats v with format, which is nil for the zero value of a def, for example one
+// returned by a failed RegisterMDefY. Those defs only produce no-op metrics, but still need to
+// accept tag values.
+func formatTag[V TagValue](format func(V) string, v V) string {
+	if format == nil {
+		format = tagFormatter[V]()
+	}
+	return format(v)
+}
+
+// as reinterprets v as a U, which must be the underlying type of V.
+func as[U any, V any](v V) U {
+	return *(*U)(unsafe.Pointer(&v))
+}
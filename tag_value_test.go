@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"testing"
+)
+
+type testRegion string
+
+type testLevel int
+
+func (l testLevel) String() string { return [...]string{"Low", "High"}[l] }
+
+type testShard uint16
+
+func (s testShard) MetricTagValue() string { return "shard-" + string(rune('a'+s)) }
+
+type testPoint struct{ x, y int }
+
+func (p testPoint) MetricTagValue() string { return "point" }
+
+func TestTagValues(t *testing.T) {
+	p := newCapturingPublisher()
+	m := New(p)
+	type (
+		r = testRegion
+		l = testLevel
+		s = testShard
+	)
+	d := makeCounterDef8[string, bool, int8, uint64, r, l, s, AnyTagValue](
+		"test_tag_values",
+		[...]string{"a", "b", "c", "d", "e", "f", "g", "h"},
+		makeDefOptions(nil),
+		true,
+	)
+
+	c := m.Counter(d.Values("Foo Bar", true, -3, 18446744073709551615, "us-East", 1, 2, Any(1.5)))
+	c.Add(1)
+	// Any uses TagValuer too.
+	m.Counter(d.Values("x", false, 0, 0, "", 0, 0, Any(testPoint{1, 2}))).Add(1)
+	m.Flush()
+
+	tags := []string{
+		"a:foo_bar",
+		"b:true",
+		"c:-3",
+		"d:18446744073709551615",
+		"e:us-east",
+		"f:high",
+		"g:shard-c",
+		"h:1.5",
+	}
+	if n := p.countSeen("test_tag_values", tags); n != 1 {
+		t.Fatalf("expected 1, got %d", n)
+	}
+	tags = []string{"a:x", "b:false", "c:0", "d:0", "e:", "f:low", "g:shard-a", "h:point"}
+	if n := p.countSeen("test_tag_values", tags); n != 1 {
+		t.Fatalf("expected 1, got %d", n)
+	}
+
+	// Binding a prefix formats the same way.
+	if c != m.Counter(
+		d.Prefix5("Foo Bar", true, -3, 18446744073709551615, "us-East").
+			Values(1, 2, Any(1.5)),
+	) {
+		t.Fatal("expected the same metric for the same tag values")
+	}
+
+	// Values that format the same are the same metric.
+	same := d.Values("foo_bar", true, -3, 18446744073709551615, "us-east", 1, 2, Any("1.5"))
+	if c != m.Counter(same) {
+		t.Fatal("expected the same metric for values that format the same")
+	}
+}
+
+// Defs that aren't made by a constructor, like the struct literals in most tests, have no
+// formatters and so format their tag values with formatTag's fallback. It has to format the same
+// way as the formatters the constructors use.
+func TestTagValuesWithoutFormatters(t *testing.T) {
+	m := New(newCapturingPublisher())
+	keys := [...]string{"region", "level", "shard"}
+	literal := CounterDef3[testRegion, testLevel, testShard]{
+		name: "test_tag_values_without_formatters",
+		keys: keys,
+		ok:   true,
+	}
+	made := makeCounterDef3[testRegion, testLevel, testShard](
+		"test_tag_values_without_formatters",
+		keys,
+		makeDefOptions(nil),
+		true,
+	)
+
+	c := m.Counter(made.Values("us-East", 1, 2))
+	if c != m.Counter(literal.Values("us-East", 1, 2)) {
+		t.Fatal("expected the same metric from a def without formatters")
+	}
+	if c != m.Counter(literal.Prefix1("us-East").Values(1, 2)) {
+		t.Fatal("expected the same metric from a prefix of a def without formatters")
+	}
+}
+
+func BenchmarkTagValues(b *testing.B) {
+	m := New(noOpPublisher{})
+	b.ReportAllocs()
+	d := makeCounterDef3[testRegion, testLevel, uint32](
+		"benchmark_tag_values",
+		[...]string{"region", "level", "code"},
+		makeDefOptions(nil),
+		true,
+	)
+
+	for i := 0; i < b.N; i++ {
+		m.Counter(d.Values("us-east", 1, 404))
+	}
+}
@@ -3,7 +3,7 @@ package metrics
 type tags struct {
 	n      int
 	keys   [maxTags]string
-	values [maxTags]any
+	values [maxTags]string
 }
 
 func (t tags) append(other tags) tags {